@@ -0,0 +1,260 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zchee/git-worktree-runner/internal/adapters"
+	"github.com/zchee/git-worktree-runner/internal/gitx"
+	"github.com/zchee/git-worktree-runner/wr"
+)
+
+// completer returns the completion candidates for toComplete. A candidate may carry a description after a tab.
+type completer func(r Runner, ctx context.Context, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// configKeys are the configuration keys offered when completing `git wr config`.
+var configKeys = []string{
+	"wr.ai.default\tdefault AI tool",
+	"wr.copy.conflict\tpolicy for existing target files",
+	"wr.copy.exclude\tglob excluded from copies",
+	"wr.copy.excludeDirs\tdirectory excluded from copies",
+	"wr.copy.ignoredOnly\tcopy only git-ignored files",
+	"wr.copy.include\tglob copied into new worktrees",
+	"wr.copy.includeDirs\tdirectory copied into new worktrees",
+	"wr.copy.mode\tcopy, reflink, hardlink or symlink",
+	"wr.copy.modeOverride\tper-pattern copy mode",
+	"wr.copy.preserve\tfile attributes to preserve",
+	"wr.defaultBranch\tdefault branch name",
+	"wr.editor.default\tdefault editor",
+	"wr.hook.postCreate\tcommand run after creating a worktree",
+	"wr.hook.postRemove\tcommand run after removing a worktree",
+	"wr.hook.preCreate\tcommand run before creating a worktree",
+	"wr.hook.preRemove\tcommand run before removing a worktree",
+	"wr.hook.timeout\thook timeout",
+	"wr.ports.base\tfirst port to allocate",
+	"wr.ports.count\tports per worktree",
+	"wr.ports.envFile\twrite .env.wr into worktrees",
+	"wr.worktrees.dir\tbase directory for worktrees",
+	"wr.worktrees.naming\tsanitize, hash or escape",
+	"wr.worktrees.pathTemplate\tworktree directory layout",
+	"wr.worktrees.prefix\tprefix for worktree directories",
+}
+
+var (
+	shells = completeWords("bash", "zsh", "fish")
+
+	commandArgs = []completer{completeFiles}
+)
+
+// validArgsFunction returns the cobra completion function for spec.
+//
+// Every subcommand parses its own arguments (DisableFlagParsing), so cobra cannot derive this from flag sets.
+func (r Runner) validArgsFunction(spec commandSpec) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return spec.complete(r, cmd.Context(), args, toComplete)
+	}
+}
+
+// complete returns the candidates for toComplete given the preceding arguments.
+func (s commandSpec) complete(r Runner, ctx context.Context, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	positional := s.args
+	n := 0
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return completeFiles(r, ctx, toComplete)
+		case s.stopAtArg && n > 0:
+			n++
+		case strings.HasPrefix(arg, "-"):
+			name, _, hasValue := strings.Cut(arg, "=")
+			if alt, ok := s.argsWith[name]; ok {
+				positional = alt
+			}
+			if f, ok := s.lookup(name); ok && f.takesValue() && !hasValue {
+				i++
+			}
+		default:
+			n++
+		}
+	}
+	if s.stopAtArg && n > 0 {
+		return positional[min(n, len(positional)-1)](r, ctx, toComplete)
+	}
+
+	if len(args) > 0 {
+		if f, ok := s.lookup(args[len(args)-1]); ok && f.takesValue() {
+			return f.completeValue(r, ctx, toComplete)
+		}
+	}
+
+	if strings.HasPrefix(toComplete, "-") {
+		if name, value, ok := strings.Cut(toComplete, "="); ok {
+			f, ok := s.lookup(name)
+			if !ok || !f.takesValue() {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			out, directive := f.completeValue(r, ctx, value)
+			for i := range out {
+				out[i] = name + "=" + out[i]
+			}
+			return out, directive
+		}
+		var out []string
+		for _, f := range s.flags {
+			out = append(out, f.names...)
+		}
+		slices.Sort(out)
+		return filterPrefix(out, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if len(positional) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return positional[min(n, len(positional)-1)](r, ctx, toComplete)
+}
+
+// completeValue completes the value of f, offering nothing when f declares no completion.
+func (f flagSpec) completeValue(r Runner, ctx context.Context, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if f.complete == nil {
+		return completeNone(r, ctx, toComplete)
+	}
+	return f.complete(r, ctx, toComplete)
+}
+
+// filterPrefix returns the candidates starting with prefix, ignoring their descriptions.
+func filterPrefix(candidates []string, prefix string) []string {
+	var out []string
+	for _, c := range candidates {
+		value, _, _ := strings.Cut(c, "\t")
+		if strings.HasPrefix(value, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func completeNone(Runner, context.Context, string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeFiles(Runner, context.Context, string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+func completeWords(words ...string) completer {
+	return func(_ Runner, _ context.Context, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix(words, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func completeAdapters(kind adapters.Kind) completer {
+	return func(_ Runner, _ context.Context, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix(adapters.ListBuiltins(kind), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func completeConfigKeys(_ Runner, _ context.Context, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return filterPrefix(configKeys, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeBranches(r Runner, ctx context.Context, toComplete string) ([]string, cobra.ShellCompDirective) {
+	m, err := r.newManager(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	branches, err := m.Branches(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterPrefix(branches, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorktrees offers branch names, numeric IDs when toComplete is a number, and tag: identifiers.
+func completeWorktrees(r Runner, ctx context.Context, toComplete string) ([]string, cobra.ShellCompDirective) {
+	m, err := r.newManager(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	entries, err := m.List(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var out []string
+	switch {
+	case strings.HasPrefix(toComplete, wr.TagPrefix):
+		for _, tag := range entryTags(entries) {
+			out = append(out, wr.TagPrefix+tag)
+		}
+	case strings.HasPrefix(toComplete, "#"):
+		for _, e := range entries {
+			if e.ID != 0 {
+				out = append(out, "#"+strconv.Itoa(e.ID)+"\t"+e.Target.Branch)
+			}
+		}
+	case toComplete != "" && strings.Trim(toComplete, "0123456789") == "":
+		for _, e := range entries {
+			if e.ID != 0 {
+				out = append(out, strconv.Itoa(e.ID)+"\t"+e.Target.Branch)
+			}
+		}
+	default:
+		for _, e := range entries {
+			if e.Target.Branch == gitx.DetachedBranch {
+				continue
+			}
+			desc := e.Target.Path
+			if e.Target.IsMain {
+				desc = "main repo"
+			}
+			if e.ID != 0 {
+				desc = "#" + strconv.Itoa(e.ID) + " " + desc
+			}
+			out = append(out, e.Target.Branch+"\t"+desc)
+		}
+	}
+	return filterPrefix(out, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeTags(r Runner, ctx context.Context, toComplete string) ([]string, cobra.ShellCompDirective) {
+	m, err := r.newManager(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	entries, err := m.List(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterPrefix(entryTags(entries), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// entryTags returns the sorted, de-duplicated tags of entries.
+func entryTags(entries []wr.ListEntry) []string {
+	var tags []string
+	for _, e := range entries {
+		tags = append(tags, e.Meta.Tags...)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+	"github.com/zchee/git-worktree-runner/wr"
+)
+
+func TestCommandCompletion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		command    string
+		args       []string
+		toComplete string
+		want       []string
+	}{
+		"success: flags": {
+			command:    "list",
+			toComplete: "--f",
+			want:       []string{"--format", ":4"},
+		},
+		"success: flag value": {
+			command:    "list",
+			args:       []string{"--format"},
+			toComplete: "n",
+			want:       []string{"ndjson", ":4"},
+		},
+		"success: flag value after equals": {
+			command:    "copy",
+			toComplete: "--conflict=s",
+			want:       []string{"--conflict=skip-existing", ":4"},
+		},
+		"success: adapter names": {
+			command:    "ai",
+			args:       []string{"--ai"},
+			toComplete: "co",
+			want:       []string{"codex", "continue", ":4"},
+		},
+		"success: config keys": {
+			command:    "config",
+			args:       []string{"set"},
+			toComplete: "wr.ports.",
+			want: []string{
+				"wr.ports.base\tfirst port to allocate",
+				"wr.ports.count\tports per worktree",
+				"wr.ports.envFile\twrite .env.wr into worktrees",
+				":4",
+			},
+		},
+		"success: positional arguments advance past flag values": {
+			command:    "config",
+			args:       []string{"--global", "get"},
+			toComplete: "wr.hook.t",
+			want:       []string{"wr.hook.timeout\thook timeout", ":4"},
+		},
+		"success: command after run --all falls back to files": {
+			command: "run",
+			args:    []string{"--all"},
+			want:    []string{":0"},
+		},
+		"success: patterns after -- fall back to files": {
+			command: "copy",
+			args:    []string{"a", "--"},
+			want:    []string{":0"},
+		},
+		"success: no more arguments": {
+			command: "init",
+			args:    []string{"bash"},
+			want:    []string{":4"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			r := Runner{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr}
+			args := append(append([]string{"__complete", tc.command}, tc.args...), tc.toComplete)
+			if got := r.Run(t.Context(), args); got != exitSuccess {
+				t.Fatalf("Run(%q) = %d, stderr=%q", args, got, stderr.String())
+			}
+			got := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("completion mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompleteWorktrees(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+	m, err := wr.NewManager(t.Context(), wr.ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	if _, err := m.CreateWorktree(t.Context(), "feature-auth", wr.CreateWorktreeOptions{FromCurrent: true, NoCopy: true}); err != nil {
+		t.Fatalf("CreateWorktree(feature-auth) error: %v", err)
+	}
+	// Worktrees added outside git-wr have no ID.
+	if _, err := g.Run(t.Context(), repoDir, "worktree", "add", "-b", "feature-billing", filepath.Join(repoDir+"-worktrees", "feature-billing")); err != nil {
+		t.Fatalf("git worktree add feature-billing: %v", err)
+	}
+	t.Chdir(repoDir)
+
+	tests := map[string]struct {
+		args []string
+		want []string
+	}{
+		"success: worktree branches": {
+			args: []string{"go", "feature-a"},
+			want: []string{"feature-auth\t#2 " + filepath.Join(repoDir+"-worktrees", "feature-auth"), ":4"},
+		},
+		"success: worktree branches without ID": {
+			args: []string{"go", "feature-b"},
+			want: []string{"feature-billing\t" + filepath.Join(repoDir+"-worktrees", "feature-billing"), ":4"},
+		},
+		"success: worktree IDs": {
+			args: []string{"go", "2"},
+			want: []string{"2\tfeature-auth", ":4"},
+		},
+		"success: branches for new": {
+			args: []string{"new", "--from", "feature-b"},
+			want: []string{"feature-billing", ":4"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := Runner{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr}
+			args := append([]string{"__complete"}, tc.args...)
+			if got := r.Run(t.Context(), args); got != exitSuccess {
+				t.Fatalf("Run(%q) = %d, stderr=%q", args, got, stderr.String())
+			}
+			got := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("completion mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// fakeCompleteGit answers `git wr __complete` with two candidates and a directive, and records the arguments.
+const fakeCompleteGit = `#!/bin/sh
+shift 2
+printf '%s\n' "$@" > "$WR_ARGS"
+printf 'alpha\tfirst\nbeta\n:4\n'
+`
+
+func TestBashCompletionScript(t *testing.T) {
+	t.Parallel()
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	var script bytes.Buffer
+	if code := (Runner{Stdout: &script, Stderr: &script}).runCompletion(t.Context(), []string{"bash"}); code != exitSuccess {
+		t.Fatalf("runCompletion(bash) = %d: %s", code, script.String())
+	}
+
+	tests := map[string]struct {
+		invoke   string
+		wantArgs []string
+	}{
+		"success: wr function": {
+			invoke:   "COMP_WORDS=(wr go b); COMP_CWORD=2; _wr",
+			wantArgs: []string{"go", "b"},
+		},
+		"success: git wr": {
+			invoke:   "words=(git wr copy --from ''); cword=4; cur=''; _git_wr",
+			wantArgs: []string{"copy", "--from", ""},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "git"), []byte(fakeCompleteGit), 0o755); err != nil {
+				t.Fatalf("WriteFile(git): %v", err)
+			}
+			argsFile := filepath.Join(dir, "args")
+
+			cmd := exec.CommandContext(t.Context(), bash, "-c", script.String()+tc.invoke+`; printf '%s\n' "${COMPREPLY[@]}"`)
+			cmd.Env = append(os.Environ(),
+				"PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"),
+				"WR_ARGS="+argsFile,
+			)
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("bash: %v", err)
+			}
+			if diff := cmp.Diff("alpha\nbeta\n", string(out)); diff != "" {
+				t.Fatalf("COMPREPLY mismatch (-want +got):\n%s", diff)
+			}
+
+			gotArgs, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatalf("ReadFile(args): %v", err)
+			}
+			if diff := cmp.Diff(tc.wantArgs, strings.Split(strings.TrimSuffix(string(gotArgs), "\n"), "\n")); diff != "" {
+				t.Fatalf("__complete arguments mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
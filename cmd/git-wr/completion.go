@@ -0,0 +1,180 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// completionScripts holds the scripts emitted by `git wr completion <shell>`.
+//
+// Each script asks the hidden `git wr __complete <args...> <word>` endpoint for candidates. The endpoint
+// prints one candidate per line, optionally followed by a tab and a description, and ends with a
+// ":<directive>" line; bit 4 of the directive disables the fallback to file name completion.
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+const bashCompletion = `# bash completion for git wr. Add this to ~/.bashrc:
+#
+#   eval "$(git wr completion bash)"
+#
+# It completes "git wr", "git-wr" and the "wr" function from "git wr init".
+__wr_complete() {
+  local line directive=0
+  COMPREPLY=()
+  while IFS= read -r line; do
+    case "$line" in
+      :[0-9]*) directive="${line#:}" ;;
+      *) COMPREPLY+=("${line%%$'\t'*}") ;;
+    esac
+  done < <(command git wr __complete "$@" 2>/dev/null)
+  if (( ${#COMPREPLY[@]} == 0 && (directive & 4) == 0 )); then
+    compopt -o default 2>/dev/null
+  fi
+}
+
+_wr() {
+  local cur words cword
+  if declare -F _get_comp_words_by_ref >/dev/null; then
+    _get_comp_words_by_ref -n =: cur words cword
+  else
+    cur="${COMP_WORDS[COMP_CWORD]}" words=("${COMP_WORDS[@]}") cword=$COMP_CWORD
+  fi
+  __wr_complete "${words[@]:1:cword}"
+  if declare -F __ltrim_colon_completions >/dev/null; then
+    __ltrim_colon_completions "$cur"
+  fi
+}
+
+# git's bash completion calls _git_wr for "git wr" with words, cword and cur set.
+_git_wr() {
+  local i
+  for ((i = 1; i < cword; i++)); do
+    [[ ${words[i]} == wr ]] && break
+  done
+  __wr_complete "${words[@]:i+1:cword-i}"
+  if declare -F __ltrim_colon_completions >/dev/null; then
+    __ltrim_colon_completions "$cur"
+  fi
+}
+
+complete -F _wr wr git-wr
+`
+
+const zshCompletion = `# zsh completion for git wr. Add this to ~/.zshrc after compinit:
+#
+#   eval "$(git wr completion zsh)"
+#
+# It completes "git wr", "git-wr" and the "wr" function from "git wr init".
+__wr_complete() {
+  local line directive=0
+  local -a candidates
+  for line in "${(@f)$(command git wr __complete "$@" 2>/dev/null)}"; do
+    case "$line" in
+      :<->) directive=${line#:} ;;
+      *$'\t'*) candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}") ;;
+      ?*) candidates+=("${line//:/\\:}") ;;
+    esac
+  done
+  if (( ${#candidates} )); then
+    _describe -t values 'git wr' candidates
+  elif (( ! (directive & 4) )); then
+    _files
+  fi
+}
+
+# words[1] is "wr" or "git-wr" here, and "wr" when git's completion calls _git-wr for "git wr".
+_wr() {
+  __wr_complete "${(@)words[2,CURRENT]}"
+}
+
+_git-wr() {
+  __wr_complete "${(@)words[2,CURRENT]}"
+}
+
+compdef _wr wr git-wr
+`
+
+const fishCompletion = `# fish completion for git wr. Add this to ~/.config/fish/config.fish:
+#
+#   git wr completion fish | source
+#
+# It completes "git wr", "git-wr" and the "wr" function from "git wr init".
+function __wr_complete
+    set -l args
+    set -l found 0
+    for token in (commandline -opc)
+        if test $found = 1
+            set -a args $token
+        else if contains -- $token wr git-wr
+            set found 1
+        end
+    end
+
+    set -l directive 0
+    set -l results
+    for line in (command git wr __complete $args (commandline -ct) 2>/dev/null)
+        if string match -qr '^:[0-9]+$' -- $line
+            set directive (string sub -s 2 -- $line)
+        else
+            set -a results $line
+        end
+    end
+    if test (count $results) -eq 0; and test (math "bitand($directive, 4)") -eq 0
+        __fish_complete_path (commandline -ct)
+        return
+    end
+    printf '%s\n' $results
+end
+
+complete -c wr -f -a '(__wr_complete)'
+complete -c git-wr -f -a '(__wr_complete)'
+complete -c git -n '__fish_seen_subcommand_from wr' -f -a '(__wr_complete)'
+`
+
+func completionSpec() commandSpec {
+	return commandSpec{
+		usage:   []string{"completion <bash|zsh|fish>"},
+		summary: "Print the shell completion script.",
+		args:    []completer{shells, completeNone},
+	}
+}
+
+func (r Runner) runCompletion(ctx context.Context, args []string) int {
+	_ = ctx
+
+	spec := completionSpec()
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	args = p.all()
+	if len(args) != 1 {
+		return r.usageError(spec)
+	}
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		return r.usagef("Unsupported shell: %s (want bash, zsh or fish)", args[0])
+	}
+
+	fmt.Fprint(r.Stdout, script)
+	return exitSuccess
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/zchee/git-worktree-runner/wr"
+)
+
+// Exit statuses for failure classes. They are part of the CLI contract: never renumber them.
+const (
+	exitTargetNotFound = 3
+	exitLockTimeout    = 4
+	exitHookFailed     = 5
+	exitGitFailed      = 6
+	exitWorktreeDirty  = 7
+	exitDeclined       = 8
+)
+
+// exitCodes documents every exit status and the error codes (see wr.ErrorCodeOf) that map to it.
+var exitCodes = []struct {
+	code    int
+	errors  []wr.ErrorCode
+	summary string
+}{
+	{exitSuccess, nil, "Success"},
+	{exitFailure, nil, "Any other failure"},
+	{exitUsage, []wr.ErrorCode{wr.ErrorCodeUsage}, "Invalid command line"},
+	{exitTargetNotFound, []wr.ErrorCode{wr.ErrorCodeTargetNotFound, wr.ErrorCodeAmbiguousTarget}, "No worktree, or more than one, matches the identifier"},
+	{exitLockTimeout, []wr.ErrorCode{wr.ErrorCodeLockTimeout}, "Timed out waiting for another git wr process to release the repository lock"},
+	{exitHookFailed, []wr.ErrorCode{wr.ErrorCodeHookFailed}, "A hook exited non-zero or timed out"},
+	{exitGitFailed, []wr.ErrorCode{wr.ErrorCodeGitFailed}, "A git command failed"},
+	{exitWorktreeDirty, []wr.ErrorCode{wr.ErrorCodeWorktreeDirty, wr.ErrorCodeWorktreeLocked}, "Refused to remove a worktree with uncommitted changes or a lock (see --force)"},
+	{exitDeclined, []wr.ErrorCode{wr.ErrorCodeDeclined}, "A prompt was declined or the selection was canceled"},
+}
+
+// exitCodeFor returns the exit status for err.
+func exitCodeFor(err error) int {
+	code := wr.ErrorCodeOf(err)
+	for _, c := range exitCodes {
+		if slices.Contains(c.errors, code) {
+			return c.code
+		}
+	}
+	return exitFailure
+}
+
+// writeExitCodes prints the exit status table for `git wr help exit-codes`.
+func writeExitCodes(w io.Writer) {
+	fmt.Fprintln(w, "Exit codes of git wr:")
+	fmt.Fprintln(w)
+	for _, c := range exitCodes {
+		fmt.Fprintf(w, "  %d  %s\n", c.code, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "run, editor and ai exit with the status of the command they start, which may overlap these values.")
+}
@@ -0,0 +1,360 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// errHelp is returned by commandSpec.parse when -h or --help was given.
+var errHelp = errors.New("help requested")
+
+// flagSpec declares one flag of a subcommand.
+type flagSpec struct {
+	// names lists the accepted spellings, e.g. {"-n", "--dry-run"}.
+	names []string
+	// value is the placeholder shown in help for flags that take a value, e.g. "<ref>". Switches leave it empty.
+	value string
+	// usage is the one-line description shown in help.
+	usage string
+	// env names an environment variable used when the flag is not given on the command line.
+	env string
+	// complete completes the flag value.
+	complete completer
+	// apply records one occurrence of the flag. Switches receive "true" unless spelled --flag=<bool>.
+	apply func(value string) error
+}
+
+func (f flagSpec) takesValue() bool {
+	return f.value != ""
+}
+
+// withEnv returns f with an environment variable fallback.
+func (f flagSpec) withEnv(name string) flagSpec {
+	f.env = name
+	return f
+}
+
+// withCompletion returns f with value completion.
+func (f flagSpec) withCompletion(c completer) flagSpec {
+	f.complete = c
+	return f
+}
+
+// switchFlag declares a flag without a value that sets *p.
+func switchFlag(p *bool, usage string, names ...string) flagSpec {
+	return switchFunc(usage, func(on bool) { *p = on }, names...)
+}
+
+// switchFunc declares a flag without a value that calls fn.
+func switchFunc(usage string, fn func(on bool), names ...string) flagSpec {
+	return flagSpec{
+		names: names,
+		usage: usage,
+		apply: func(value string) error {
+			on, err := strconv.ParseBool(value)
+			if err != nil {
+				return errors.New("expected true or false")
+			}
+			fn(on)
+			return nil
+		},
+	}
+}
+
+// stringFlag declares a flag whose value is stored in *p; the last occurrence wins.
+func stringFlag(p *string, value, usage string, names ...string) flagSpec {
+	return valueFunc(value, usage, func(v string) error {
+		*p = v
+		return nil
+	}, names...)
+}
+
+// listFlag declares a repeatable flag whose values are appended to *p.
+func listFlag(p *[]string, value, usage string, names ...string) flagSpec {
+	return valueFunc(value, usage, func(v string) error {
+		*p = append(*p, v)
+		return nil
+	}, names...)
+}
+
+// valueFunc declares a flag whose value is passed to fn.
+func valueFunc(value, usage string, fn func(string) error, names ...string) flagSpec {
+	return flagSpec{names: names, value: value, usage: usage, apply: fn}
+}
+
+// commandSpec declares the command line of a subcommand: its flags for parsing, help and completion,
+// and what its positional arguments complete to.
+type commandSpec struct {
+	// usage lists the synopsis lines without the leading "git wr ".
+	usage []string
+	// summary is the one-line description shown in help.
+	summary string
+	flags   []flagSpec
+	// args completes the positional arguments in order; the last completer repeats.
+	args []completer
+	// argsWith replaces args once the keyed flag was given, e.g. `run --all <command...>`.
+	argsWith map[string][]completer
+	// stopAtArg ends flag parsing at the first positional argument so that a command line that
+	// follows it is passed through untouched.
+	stopAtArg bool
+}
+
+// parsedArgs holds the arguments left after flag parsing.
+type parsedArgs struct {
+	// args are the positional arguments before "--".
+	args []string
+	// rest are the arguments after "--".
+	rest []string
+	// dashdash reports whether "--" was given.
+	dashdash bool
+}
+
+// all returns the positional arguments on both sides of "--".
+func (p parsedArgs) all() []string {
+	return append(slices.Clone(p.args), p.rest...)
+}
+
+func (s commandSpec) lookup(name string) (flagSpec, bool) {
+	for _, f := range s.flags {
+		if slices.Contains(f.names, name) {
+			return f, true
+		}
+	}
+	return flagSpec{}, false
+}
+
+// parse applies the flags in args and returns the remaining arguments.
+//
+// It accepts "--flag value", "--flag=value", combined short switches ("-an") and short values ("-j4").
+// Flags missing from args fall back to their environment variable, looked up with lookupEnv.
+func (s commandSpec) parse(args []string, lookupEnv func(string) (string, bool)) (parsedArgs, error) {
+	var p parsedArgs
+	seen := map[string]bool{}
+	apply := func(f flagSpec, spelled, value string) error {
+		seen[f.names[0]] = true
+		if err := f.apply(value); err != nil {
+			return fmt.Errorf("invalid %s value %q: %w", spelled, value, err)
+		}
+		return nil
+	}
+	// value returns the argument after args[i] as the value of flag name.
+	value := func(i int, name string) (string, error) {
+		if i+1 >= len(args) || args[i+1] == "--" {
+			return "", fmt.Errorf("%s requires a value", name)
+		}
+		if _, ok := s.lookup(args[i+1]); ok {
+			return "", fmt.Errorf("%s requires a value", name)
+		}
+		return args[i+1], nil
+	}
+
+parse:
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			p.dashdash = true
+			p.rest = args[i+1:]
+			break parse
+		case arg == "-h" || arg == "--help":
+			return parsedArgs{}, errHelp
+		case strings.HasPrefix(arg, "--"):
+			name, v, hasValue := strings.Cut(arg, "=")
+			f, ok := s.lookup(name)
+			if !ok {
+				return parsedArgs{}, s.unknownFlag(name)
+			}
+			switch {
+			case f.takesValue() && !hasValue:
+				var err error
+				if v, err = value(i, name); err != nil {
+					return parsedArgs{}, err
+				}
+				i++
+			case !f.takesValue() && !hasValue:
+				v = "true"
+			}
+			if err := apply(f, name, v); err != nil {
+				return parsedArgs{}, err
+			}
+		case len(arg) > 1 && arg[0] == '-':
+			for j := 1; j < len(arg); j++ {
+				name := "-" + arg[j:j+1]
+				f, ok := s.lookup(name)
+				if !ok {
+					if j == 1 {
+						name = arg
+					}
+					return parsedArgs{}, s.unknownFlag(name)
+				}
+				if !f.takesValue() {
+					if err := apply(f, name, "true"); err != nil {
+						return parsedArgs{}, err
+					}
+					continue
+				}
+				v := arg[j+1:]
+				if v == "" {
+					var err error
+					if v, err = value(i, name); err != nil {
+						return parsedArgs{}, err
+					}
+					i++
+				}
+				if err := apply(f, name, v); err != nil {
+					return parsedArgs{}, err
+				}
+				break
+			}
+		default:
+			if s.stopAtArg {
+				p.args = args[i:]
+				break parse
+			}
+			p.args = append(p.args, arg)
+		}
+	}
+
+	for _, f := range s.flags {
+		if f.env == "" || seen[f.names[0]] {
+			continue
+		}
+		if v, ok := lookupEnv(f.env); ok && v != "" {
+			if err := apply(f, f.env, v); err != nil {
+				return parsedArgs{}, err
+			}
+		}
+	}
+	return p, nil
+}
+
+// unknownFlag returns the error for an unknown flag, suggesting the closest known spelling.
+func (s commandSpec) unknownFlag(name string) error {
+	best, bestDist := "", 3
+	if len(name) <= 2 {
+		// Single letters are too short to guess from.
+		bestDist = 0
+	}
+	for _, f := range s.flags {
+		for _, known := range f.names {
+			d := editDistance(name, known)
+			if strings.HasPrefix(known, name) && len(name) > 2 {
+				d = 1
+			}
+			if d < bestDist {
+				best, bestDist = known, d
+			}
+		}
+	}
+	if best == "" {
+		return fmt.Errorf("Unknown flag: %s", name)
+	}
+	return fmt.Errorf("Unknown flag: %s (did you mean %s?)", name, best)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// writeHelp prints the generated usage of the command.
+func (s commandSpec) writeHelp(w io.Writer) {
+	for i, line := range s.usage {
+		if i == 0 {
+			fmt.Fprintf(w, "Usage: git wr %s\n", line)
+		} else {
+			fmt.Fprintf(w, "       git wr %s\n", line)
+		}
+	}
+	if s.summary != "" {
+		fmt.Fprintf(w, "\n%s\n", s.summary)
+	}
+
+	if len(s.flags) == 0 {
+		return
+	}
+	labels := make([]string, len(s.flags))
+	width := 0
+	for i, f := range s.flags {
+		labels[i] = strings.Join(f.names, ", ")
+		if f.takesValue() {
+			labels[i] += " " + f.value
+		}
+		width = max(width, len(labels[i]))
+	}
+	fmt.Fprintln(w, "\nOptions:")
+	for i, f := range s.flags {
+		usage := f.usage
+		if f.env != "" {
+			usage += " [$" + f.env + "]"
+		}
+		fmt.Fprintf(w, "  %-*s  %s\n", width, labels[i], usage)
+	}
+}
+
+// parseArgs parses args against spec plus the --output flag, and switches r to the requested output.
+// When ok is false the command must return code: help was printed (exitSuccess) or a usage error was
+// reported (exitUsage).
+func (r *Runner) parseArgs(spec commandSpec, args []string) (p parsedArgs, code int, ok bool) {
+	lookupEnv := r.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	output := "text"
+	spec = spec.withOutput(&output)
+	p, err := spec.parse(args, lookupEnv)
+	if outErr := r.setOutput(output); outErr != nil && err == nil {
+		err = outErr
+	}
+	switch {
+	case errors.Is(err, errHelp):
+		spec.writeHelp(r.Stdout)
+		return parsedArgs{}, exitSuccess, false
+	case err != nil:
+		return parsedArgs{}, r.usagef("%v", err), false
+	}
+	return p, exitSuccess, true
+}
+
+// usageError reports the synopsis of spec as a usage error.
+func (r Runner) usageError(spec commandSpec) int {
+	return r.usagef("Usage: git wr %s", strings.Join(spec.usage, "\n       git wr "))
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCommandSpecParse(t *testing.T) {
+	t.Parallel()
+
+	type result struct {
+		All      bool
+		DryRun   bool
+		From     string
+		Tags     []string
+		Jobs     string
+		Args     []string
+		Rest     []string
+		Dashdash bool
+	}
+
+	tests := map[string]struct {
+		args      []string
+		env       map[string]string
+		stopAtArg bool
+		want      result
+		wantErr   string
+	}{
+		"success: separate and inline values": {
+			args: []string{"--from", "main", "--tag=a", "--tag", "b", "x"},
+			want: result{From: "main", Tags: []string{"a", "b"}, Args: []string{"x"}},
+		},
+		"success: combined short flags": {
+			args: []string{"-an", "-j4"},
+			want: result{All: true, DryRun: true, Jobs: "4"},
+		},
+		"success: short flag value in the next argument": {
+			args: []string{"-aj", "8", "x"},
+			want: result{All: true, Jobs: "8", Args: []string{"x"}},
+		},
+		"success: explicit switch value": {
+			args: []string{"--all=false", "--dry-run=true"},
+			want: result{DryRun: true},
+		},
+		"success: arguments after --": {
+			args: []string{"x", "--", "--from", "y"},
+			want: result{Args: []string{"x"}, Rest: []string{"--from", "y"}, Dashdash: true},
+		},
+		"success: flags after the first argument are kept with stopAtArg": {
+			args:      []string{"-a", "echo", "-n", "--from"},
+			stopAtArg: true,
+			want:      result{All: true, Args: []string{"echo", "-n", "--from"}},
+		},
+		"success: environment fallback": {
+			args: []string{"--from=main"},
+			env:  map[string]string{"TEST_FROM": "env", "TEST_DRY_RUN": "1"},
+			want: result{From: "main", DryRun: true},
+		},
+		"error: value swallowing --": {
+			args:    []string{"--from", "--", "x"},
+			wantErr: "--from requires a value",
+		},
+		"error: value swallowing a flag": {
+			args:    []string{"--from", "-a"},
+			wantErr: "--from requires a value",
+		},
+		"error: missing value": {
+			args:    []string{"-j"},
+			wantErr: "-j requires a value",
+		},
+		"error: unknown flag with suggestion": {
+			args:    []string{"--dryrun"},
+			wantErr: "Unknown flag: --dryrun (did you mean --dry-run?)",
+		},
+		"error: unknown flag prefix": {
+			args:    []string{"--fr"},
+			wantErr: "Unknown flag: --fr (did you mean --from?)",
+		},
+		"error: single dash long flag": {
+			args:    []string{"-from", "x"},
+			wantErr: "Unknown flag: -from (did you mean --from?)",
+		},
+		"error: unknown flag without suggestion": {
+			args:    []string{"--bogus"},
+			wantErr: "Unknown flag: --bogus",
+		},
+		"error: unknown short flag in a cluster": {
+			args:    []string{"-ax"},
+			wantErr: "Unknown flag: -x",
+		},
+		"error: invalid switch value": {
+			args:    []string{"--all=maybe"},
+			wantErr: `invalid --all value "maybe": expected true or false`,
+		},
+		"error: invalid environment value": {
+			env:     map[string]string{"TEST_DRY_RUN": "maybe"},
+			wantErr: `invalid TEST_DRY_RUN value "maybe": expected true or false`,
+		},
+		"error: help": {
+			args:    []string{"x", "-h"},
+			wantErr: errHelp.Error(),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got result
+			spec := commandSpec{
+				flags: []flagSpec{
+					switchFlag(&got.All, "all", "-a", "--all"),
+					switchFlag(&got.DryRun, "dry run", "-n", "--dry-run").withEnv("TEST_DRY_RUN"),
+					stringFlag(&got.From, "<ref>", "from", "--from").withEnv("TEST_FROM"),
+					listFlag(&got.Tags, "<tag>", "tag", "--tag"),
+					stringFlag(&got.Jobs, "<n>", "jobs", "-j", "--jobs"),
+				},
+				stopAtArg: tc.stopAtArg,
+			}
+			lookupEnv := func(key string) (string, bool) {
+				v, ok := tc.env[key]
+				return v, ok
+			}
+
+			p, err := spec.parse(tc.args, lookupEnv)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("parse(%q) error = %v, want %q", tc.args, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse(%q) error: %v", tc.args, err)
+			}
+			got.Args, got.Rest, got.Dashdash = p.args, p.rest, p.dashdash
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("parse(%q) mismatch (-want +got):\n%s", tc.args, diff)
+			}
+		})
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args          []string
+		wantExitCode  int
+		wantStdoutSub string
+		wantStderrSub string
+	}{
+		"success: --help": {
+			args:          []string{"new", "--help"},
+			wantExitCode:  exitSuccess,
+			wantStdoutSub: "Usage: git wr new <branch> [options]\n",
+		},
+		"success: help subcommand": {
+			args:          []string{"help", "clean"},
+			wantExitCode:  exitSuccess,
+			wantStdoutSub: "  --yes               Do not prompt [$GTR_YES]\n",
+		},
+		"success: -h with alias": {
+			args:          []string{"ls", "-h"},
+			wantExitCode:  exitSuccess,
+			wantStdoutSub: "Usage: git wr list",
+		},
+		"error: unknown flag": {
+			args:          []string{"rm", "--forse"},
+			wantExitCode:  exitUsage,
+			wantStderrSub: "[x] Unknown flag: --forse (did you mean --force?)\n",
+		},
+		"error: usage lists every synopsis": {
+			args:          []string{"run"},
+			wantExitCode:  exitUsage,
+			wantStderrSub: "[x] Usage: git wr run <id|branch|worktree-name> <command...>\n       git wr run -- <command...>\n       git wr run {--all",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			r := Runner{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr}
+			if got := r.Run(t.Context(), tc.args); got != tc.wantExitCode {
+				t.Fatalf("Run(%q) = %d, want %d (stderr=%q)", tc.args, got, tc.wantExitCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tc.wantStdoutSub) {
+				t.Fatalf("stdout mismatch: expected substring %q, got %q", tc.wantStdoutSub, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tc.wantStderrSub) {
+				t.Fatalf("stderr mismatch: expected substring %q, got %q", tc.wantStderrSub, stderr.String())
+			}
+		})
+	}
+}
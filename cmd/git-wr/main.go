@@ -24,8 +24,10 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -71,7 +73,13 @@ type Runner struct {
 	Stdout io.Writer
 	Stderr io.Writer
 
+	// LookupEnv looks up the environment variables that flags fall back to. It defaults to os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+
 	Version VersionInfo
+
+	// events receives JSON events instead of the text on Stderr when --output=json was given.
+	events io.Writer
 }
 
 func main() {
@@ -115,7 +123,7 @@ func (r Runner) Run(ctx context.Context, args []string) int {
 			fmt.Fprint(r.Stderr, "Use 'git wr help' for available commands\n")
 			return exitUsage
 		}
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
+		r.reportError(err)
 		return exitUsage
 	}
 }
@@ -133,7 +141,12 @@ CORE COMMANDS:
   rm <id|name>... [options]   Remove worktree(s)
   go <id|name>                Print worktree path for shell navigation
   run <id|name> <cmd...>      Run a command in a worktree
-  list [--porcelain]          List worktrees
+  run --all [-j <n>] <cmd...> Run a command in every worktree in parallel
+  list [--format <fmt>]       List worktrees (table, porcelain, json, ndjson)
+  status [--format json]      Show dirty state, ahead/behind and merge state
+  diff <a> [<b>] [options]    Diff two worktrees (or <a> against the main repo)
+  describe <id|name> [<text>] Show or set a worktree description
+  tag <id|name> [<tag>...]    Show or add worktree tags (-d removes)
 
 INTEGRATIONS:
   editor <id|name> [--editor <name>]     Open worktree in editor
@@ -141,15 +154,42 @@ INTEGRATIONS:
 
 SETUP & MAINTENANCE:
   copy <target>... [-- <pattern>...]     Copy files between worktrees
-  clean                                 Remove stale/prunable worktrees
+  clean [--merged] [--stale <dur>] [-n]  Remove stale/prunable/merged worktrees
+  init <bash|zsh|fish>                  Print shell integration (cd wrapper)
+  completion <bash|zsh|fish>            Print shell completion script
   doctor                                Health check
   adapter                               List adapters
   config {get|set|add|unset} <key> ...   Manage configuration
   version                               Show version
-  help                                  Show this help
+  help [<command>]                      Show this help, or the usage of <command>
+  help exit-codes                       Show the exit codes
 `)
 }
 
+// commandSpecs maps each subcommand to its command line specification for help and completion.
+// The flag values they parse into are discarded.
+var commandSpecs = map[string]func() commandSpec{
+	"list":       func() commandSpec { return listSpec(&listFlags{}) },
+	"status":     func() commandSpec { return statusSpec(new(string)) },
+	"diff":       func() commandSpec { return diffSpec(new(string), &wr.DiffOptions{}) },
+	"describe":   describeSpec,
+	"tag":        func() commandSpec { return tagSpec(new(bool)) },
+	"go":         goSpec,
+	"run":        func() commandSpec { return runSpec(&runFlags{}) },
+	"new":        func() commandSpec { return newSpec(&newFlags{}) },
+	"rm":         func() commandSpec { return removeSpec(&removeFlags{}) },
+	"copy":       func() commandSpec { return copySpec(&copyFlags{}) },
+	"config":     func() commandSpec { return configSpec(new(bool)) },
+	"editor":     func() commandSpec { return editorSpec(new(string)) },
+	"ai":         func() commandSpec { return aiSpec(new(string)) },
+	"clean":      func() commandSpec { return cleanSpec(&wr.CleanOptions{}, &hookFlags{}) },
+	"doctor":     doctorSpec,
+	"init":       initSpec,
+	"adapter":    adapterSpec,
+	"completion": completionSpec,
+	"version":    func() commandSpec { return commandSpec{usage: []string{"version"}, args: []completer{completeNone}} },
+}
+
 func (r Runner) newRootCommand() *cobra.Command {
 	showVersion := false
 
@@ -172,6 +212,14 @@ func (r Runner) newRootCommand() *cobra.Command {
 	}
 	root.SetHelpFunc(func(cmd *cobra.Command, args []string) {
 		_ = args
+		if cmd.Name() == "exit-codes" {
+			writeExitCodes(cmd.OutOrStdout())
+			return
+		}
+		if spec, ok := commandSpecs[cmd.Name()]; ok && cmd != root {
+			spec().withOutput(new(string)).writeHelp(cmd.OutOrStdout())
+			return
+		}
 		writeHelp(cmd.OutOrStdout())
 	})
 
@@ -179,6 +227,10 @@ func (r Runner) newRootCommand() *cobra.Command {
 
 	root.AddCommand(
 		r.newCommand("list", []string{"ls"}, r.runList),
+		r.newCommand("status", []string{"st"}, r.runStatus),
+		r.newCommand("diff", nil, r.runDiff),
+		r.newCommand("describe", nil, r.runDescribe),
+		r.newCommand("tag", nil, r.runTag),
 		r.newCommand("go", nil, r.runGo),
 		r.newCommand("run", nil, r.runRun),
 		r.newCommand("new", nil, r.runNew),
@@ -189,9 +241,18 @@ func (r Runner) newRootCommand() *cobra.Command {
 		r.newCommand("ai", nil, r.runAI),
 		r.newCommand("clean", nil, r.runClean),
 		r.newCommand("doctor", nil, r.runDoctor),
+		r.newCommand("init", []string{"shell-init"}, r.runInit),
 		r.newCommand("adapter", []string{"adapters"}, r.runAdapters),
+		r.newCommand("completion", nil, r.runCompletion),
 		r.versionCommand(),
+		// A help topic: `git wr help exit-codes`.
+		&cobra.Command{Use: "exit-codes", Short: "Exit codes"},
 	)
+	for _, cmd := range root.Commands() {
+		if spec, ok := commandSpecs[cmd.Name()]; ok {
+			cmd.ValidArgsFunction = r.validArgsFunction(spec().withOutput(new(string)))
+		}
+	}
 
 	return root
 }
@@ -220,13 +281,46 @@ func (r Runner) newCommand(use string, aliases []string, run func(ctx context.Co
 }
 
 func (r Runner) newManager(ctx context.Context) (*wr.Manager, error) {
-	m, err := wr.NewManager(ctx, wr.ManagerOptions{})
+	m, err := wr.NewManager(ctx, r.managerOptions())
 	if err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
+// managerOptions returns the options shared by every Manager, forwarding events with --output=json.
+func (r Runner) managerOptions() wr.ManagerOptions {
+	var opts wr.ManagerOptions
+	if r.events != nil {
+		opts.OnEvent = r.emit
+	}
+	return opts
+}
+
+// hookFlags holds the --quiet/--verbose flags accepted by commands that run hooks.
+type hookFlags struct {
+	quiet   bool
+	verbose bool
+}
+
+func (f *hookFlags) specs() []flagSpec {
+	return []flagSpec{
+		switchFlag(&f.quiet, "Hide hook output", "-q", "--quiet").withEnv("GTR_QUIET"),
+		switchFlag(&f.verbose, "Print each hook command before it runs", "--verbose").withEnv("GTR_VERBOSE"),
+	}
+}
+
+// newHookManager is like newManager but streams hook output to stderr unless --quiet was given.
+func (r Runner) newHookManager(ctx context.Context, f hookFlags) (*wr.Manager, error) {
+	opts := r.managerOptions()
+	if !f.quiet {
+		opts.HookStdout = r.Stderr
+		opts.HookStderr = r.Stderr
+		opts.HookVerbose = f.verbose
+	}
+	return wr.NewManager(ctx, opts)
+}
+
 func parseUnknownCommand(err error) (cmd string, ok bool) {
 	msg := err.Error()
 	const prefix = "unknown command \""
@@ -241,51 +335,246 @@ func parseUnknownCommand(err error) (cmd string, ok bool) {
 	return before, true
 }
 
+type listFlags struct {
+	format string
+	tag    string
+}
+
+func listSpec(f *listFlags) commandSpec {
+	return commandSpec{
+		usage:   []string{"list [--porcelain] [--format table|porcelain|json|ndjson] [--tag <tag>]"},
+		summary: "List the main repository and its worktrees.",
+		flags: []flagSpec{
+			switchFunc("Same as --format porcelain", func(on bool) {
+				if on {
+					f.format = "porcelain"
+				}
+			}, "--porcelain"),
+			stringFlag(&f.format, "<format>", "Output format: table, porcelain, json or ndjson", "--format").
+				withCompletion(completeWords("table", "porcelain", "json", "ndjson")),
+			stringFlag(&f.tag, "<tag>", "Only list worktrees with this tag", "--tag").
+				withCompletion(completeTags),
+		},
+		args: []completer{completeNone},
+	}
+}
+
 func (r Runner) runList(ctx context.Context, args []string) int {
-	porcelain := false
-	for _, a := range args {
-		if a == "--porcelain" {
-			porcelain = true
-		}
+	f := listFlags{format: "table"}
+	spec := listSpec(&f)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	if len(p.all()) != 0 {
+		return r.usageError(spec)
+	}
+	format, tag := f.format, f.tag
+	switch format {
+	case "table", "porcelain", "json", "ndjson":
+	default:
+		return r.usagef("Unknown list format: %s (want table, porcelain, json or ndjson)", format)
 	}
 
 	m, err := r.newManager(ctx)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
-	entries, err := m.List(ctx)
+	entries, err := m.ListWithOptions(ctx, wr.ListOptions{
+		Details: format == "json" || format == "ndjson",
+		Tag:     tag,
+	})
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
-	if porcelain {
+	switch format {
+	case "porcelain":
 		for _, e := range entries {
 			fmt.Fprintf(r.Stdout, "%s\t%s\t%s\n", e.Target.Path, e.Target.Branch, e.Status)
 		}
 		return exitSuccess
+	case "json":
+		if err := wr.WriteListJSON(r.Stdout, entries); err != nil {
+			return r.fail(err)
+		}
+		return exitSuccess
+	case "ndjson":
+		if err := wr.WriteListNDJSON(r.Stdout, entries); err != nil {
+			return r.fail(err)
+		}
+		return exitSuccess
 	}
 
 	fmt.Fprintln(r.Stdout, "Git Worktrees")
 	fmt.Fprintln(r.Stdout)
-	fmt.Fprintf(r.Stdout, "%-30s %s\n", "BRANCH", "PATH")
-	fmt.Fprintf(r.Stdout, "%-30s %s\n", "------", "----")
+	fmt.Fprintf(r.Stdout, "%-4s %-30s %s\n", "ID", "BRANCH", "PATH")
+	fmt.Fprintf(r.Stdout, "%-4s %-30s %s\n", "--", "------", "----")
 
 	for _, e := range entries {
 		branch := e.Target.Branch
 		if e.Target.IsMain {
 			branch += " [main repo]"
 		}
-		fmt.Fprintf(r.Stdout, "%-30s %s\n", branch, e.Target.Path)
+		path := e.Target.Path
+		for _, t := range e.Meta.Tags {
+			path += " #" + t
+		}
+		fmt.Fprintf(r.Stdout, "%-4s %-30s %s\n", formatID(e.ID), branch, path)
+		if e.Meta.Description != "" {
+			fmt.Fprintf(r.Stdout, "%-4s %-30s %s\n", "", "", e.Meta.Description)
+		}
 	}
 
 	fmt.Fprintln(r.Stdout)
-	fmt.Fprintln(r.Stdout, "Tip: Use 'git wr list --porcelain' for machine-readable output")
+	fmt.Fprintln(r.Stdout, "Tip: Use 'git wr list --porcelain' or '--format=json' for machine-readable output")
 	return exitSuccess
 }
 
+func statusSpec(format *string) commandSpec {
+	return commandSpec{
+		usage:   []string{"status [--format table|json]"},
+		summary: "Show dirty state, ahead/behind counts and merge state of every worktree.",
+		flags: []flagSpec{
+			stringFlag(format, "<format>", "Output format: table or json", "--format").
+				withCompletion(completeWords("table", "json")),
+		},
+		args: []completer{completeNone},
+	}
+}
+
+func (r Runner) runStatus(ctx context.Context, args []string) int {
+	format := "table"
+	spec := statusSpec(&format)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	if len(p.all()) != 0 {
+		return r.usageError(spec)
+	}
+	switch format {
+	case "table", "json":
+	default:
+		return r.usagef("Unknown status format: %s (want table or json)", format)
+	}
+
+	m, err := r.newManager(ctx)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	entries, err := m.Status(ctx)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	if format == "json" {
+		if err := wr.WriteStatusJSON(r.Stdout, entries); err != nil {
+			return r.fail(err)
+		}
+		return exitSuccess
+	}
+
+	defaultBranch := ""
+	if len(entries) > 0 {
+		defaultBranch = entries[0].DefaultBranch
+	}
+	fmt.Fprintf(r.Stdout, "Worktree Status (default branch: %s)\n", defaultBranch)
+	fmt.Fprintln(r.Stdout)
+	fmt.Fprintf(r.Stdout, "%-30s %-14s %-10s %-10s %-7s %s\n", "BRANCH", "CHANGES", "UPSTREAM", "DEFAULT", "MERGED", "AGE")
+	fmt.Fprintf(r.Stdout, "%-30s %-14s %-10s %-10s %-7s %s\n", "------", "-------", "--------", "-------", "------", "---")
+
+	var safe []string
+	now := time.Now()
+	for _, e := range entries {
+		branch := e.Target.Branch
+		if e.Target.IsMain {
+			branch += " [main repo]"
+		}
+
+		changes, upstream, def, merged, age := "-", "-", "-", "-", "-"
+		if e.Head != "" || e.Dirty.Total() > 0 {
+			changes = formatDirty(e.Dirty)
+		}
+		if e.Status == wr.WorktreeStatusMissing || e.Status == wr.WorktreeStatusPrunable {
+			changes = string(e.Status)
+		}
+		if e.Upstream != "" {
+			upstream = fmt.Sprintf("+%d/-%d", e.Ahead, e.Behind)
+		}
+		if e.DefaultRef != "" {
+			def = fmt.Sprintf("+%d/-%d", e.AheadOfDefault, e.BehindDefault)
+			merged = "no"
+			if e.Merged {
+				merged = "yes"
+			}
+		}
+		if !e.LastCommit.IsZero() {
+			age = formatAge(now.Sub(e.LastCommit))
+		}
+		fmt.Fprintf(r.Stdout, "%-30s %-14s %-10s %-10s %-7s %s\n", branch, changes, upstream, def, merged, age)
+
+		if e.SafeToRemove() {
+			safe = append(safe, e.Target.Branch)
+		}
+	}
+
+	fmt.Fprintln(r.Stdout)
+	fmt.Fprintln(r.Stdout, "CHANGES: +staged ~unstaged ?untracked !conflicted; UPSTREAM/DEFAULT: +ahead/-behind")
+	if len(safe) > 0 {
+		fmt.Fprintf(r.Stdout, "Safe to remove (clean and merged): %s\n", strings.Join(safe, ", "))
+	}
+	return exitSuccess
+}
+
+// formatID renders a worktree ID, or "-" for worktrees that have none.
+func formatID(id int) string {
+	if id == 0 {
+		return "-"
+	}
+	return strconv.Itoa(id)
+}
+
+func formatDirty(d wr.DirtyCounts) string {
+	if d.Total() == 0 {
+		return "clean"
+	}
+	var parts []string
+	if d.Staged > 0 {
+		parts = append(parts, fmt.Sprintf("+%d", d.Staged))
+	}
+	if d.Unstaged > 0 {
+		parts = append(parts, fmt.Sprintf("~%d", d.Unstaged))
+	}
+	if d.Untracked > 0 {
+		parts = append(parts, fmt.Sprintf("?%d", d.Untracked))
+	}
+	if d.Conflicted > 0 {
+		parts = append(parts, fmt.Sprintf("!%d", d.Conflicted))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
 func (r Runner) promptLine(prompt string) (string, error) {
 	fmt.Fprintf(r.Stderr, "[?] %s ", prompt)
 	reader := bufio.NewReader(r.Stdin)
@@ -309,156 +598,213 @@ func (r Runner) promptYesNo(prompt string) (bool, error) {
 	}
 }
 
+type newFlags struct {
+	hookFlags
+
+	fromRef     string
+	fromCurrent bool
+	trackMode   string
+	noCopy      bool
+	noFetch     bool
+	force       bool
+	nameSuffix  string
+	description string
+	tags        []string
+	yes         bool
+	printPath   bool
+	dryRun      bool
+}
+
+func newSpec(f *newFlags) commandSpec {
+	return commandSpec{
+		usage:   []string{"new <branch> [options]"},
+		summary: "Create a worktree for <branch>, creating the branch when it does not exist.",
+		flags: append(f.hookFlags.specs(),
+			stringFlag(&f.fromRef, "<ref>", "Create the branch from <ref> instead of the default branch", "--from").
+				withCompletion(completeBranches),
+			switchFlag(&f.fromCurrent, "Create the branch from the current branch", "--from-current"),
+			stringFlag(&f.trackMode, "<mode>", "Branch tracking: auto, remote, local or none", "--track").
+				withEnv("GTR_TRACK").
+				withCompletion(completeWords("auto", "remote", "local", "none")),
+			switchFlag(&f.noCopy, "Do not copy files into the new worktree", "--no-copy").withEnv("GTR_NO_COPY"),
+			switchFlag(&f.noFetch, "Do not fetch from origin first", "--no-fetch").withEnv("GTR_NO_FETCH"),
+			switchFlag(&f.force, "Allow a second worktree for the same branch (requires --name)", "--force"),
+			stringFlag(&f.nameSuffix, "<suffix>", "Suffix for the worktree directory name", "--name"),
+			stringFlag(&f.description, "<text>", "Record a description in the worktree registry", "--description"),
+			listFlag(&f.tags, "<tag>", "Record a tag in the worktree registry (repeatable)", "--tag").
+				withCompletion(completeTags),
+			switchFlag(&f.yes, "Do not prompt", "--yes").withEnv("GTR_YES"),
+			switchFlag(&f.printPath, "Print the worktree path to stdout", "--cd"),
+			switchFlag(&f.dryRun, "Show what would be created, copied and run", "-n", "--dry-run"),
+		),
+		args: []completer{completeBranches, completeNone},
+	}
+}
+
 func (r Runner) runNew(ctx context.Context, args []string) int {
-	var (
-		branch      string
-		fromRef     string
-		fromCurrent bool
-		trackMode   = "auto"
-		noCopy      bool
-		noFetch     bool
-		force       bool
-		nameSuffix  string
-		yes         bool
-	)
-	for i := 0; i < len(args); {
-		switch args[i] {
-		case "--from":
-			if i+1 >= len(args) {
-				fmt.Fprintln(r.Stderr, "[x] --from requires a value")
-				return exitUsage
-			}
-			fromRef = args[i+1]
-			i += 2
-		case "--from-current":
-			fromCurrent = true
-			i++
-		case "--track":
-			if i+1 >= len(args) {
-				fmt.Fprintln(r.Stderr, "[x] --track requires a value")
-				return exitUsage
-			}
-			trackMode = args[i+1]
-			i += 2
-		case "--no-copy":
-			noCopy = true
-			i++
-		case "--no-fetch":
-			noFetch = true
-			i++
-		case "--force":
-			force = true
-			i++
-		case "--name":
-			if i+1 >= len(args) {
-				fmt.Fprintln(r.Stderr, "[x] --name requires a value")
-				return exitUsage
-			}
-			nameSuffix = args[i+1]
-			i += 2
-		case "--yes":
-			yes = true
-			i++
-		default:
-			if strings.HasPrefix(args[i], "-") {
-				fmt.Fprintf(r.Stderr, "[x] Unknown flag: %s\n", args[i])
-				return exitUsage
-			}
-			if branch != "" {
-				fmt.Fprintln(r.Stderr, "[x] Usage: git wr new <branch> [options]")
-				return exitUsage
-			}
-			branch = args[i]
-			i++
-		}
+	f := newFlags{trackMode: "auto"}
+	spec := newSpec(&f)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	positional := p.all()
+	if len(positional) > 1 {
+		return r.usageError(spec)
 	}
 
-	if branch == "" {
-		if yes {
-			fmt.Fprintln(r.Stderr, "[x] Branch name required in non-interactive mode (--yes)")
-			return exitUsage
+	var branch string
+	if len(positional) == 1 {
+		branch = positional[0]
+	} else {
+		if f.yes {
+			return r.usagef("Branch name required in non-interactive mode (--yes)")
 		}
 		var err error
 		branch, err = r.promptLine("Enter branch name:")
 		if err != nil {
-			fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-			return exitFailure
+			return r.fail(err)
 		}
 		if branch == "" {
-			fmt.Fprintln(r.Stderr, "[x] Branch name required")
-			return exitUsage
+			return r.usagef("Branch name required")
 		}
 	}
 
-	m, err := r.newManager(ctx)
+	if f.quiet && f.verbose {
+		return r.usagef("--quiet and --verbose are mutually exclusive")
+	}
+
+	m, err := r.newHookManager(ctx, f.hookFlags)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
-	target, err := m.CreateWorktree(ctx, branch, wr.CreateWorktreeOptions{
-		FromRef:     fromRef,
-		FromCurrent: fromCurrent,
-		TrackMode:   wr.TrackMode(trackMode),
-		NoCopy:      noCopy,
-		NoFetch:     noFetch,
-		Force:       force,
-		NameSuffix:  nameSuffix,
-	})
+	createOpts := wr.CreateWorktreeOptions{
+		FromRef:     f.fromRef,
+		FromCurrent: f.fromCurrent,
+		TrackMode:   wr.TrackMode(f.trackMode),
+		NoCopy:      f.noCopy,
+		NoFetch:     f.noFetch,
+		Force:       f.force,
+		NameSuffix:  f.nameSuffix,
+		Description: f.description,
+		Tags:        f.tags,
+	}
+	if f.dryRun {
+		plan, err := m.PlanCreateWorktree(ctx, branch, createOpts)
+		if err != nil {
+			return r.fail(err)
+		}
+		r.writeCreatePlan(plan)
+		return exitSuccess
+	}
+	bar := newProgressBar(r.Stderr, "Copying")
+	if !f.noCopy && !f.quiet && isTerminal(r.Stderr) {
+		createOpts.CopyProgress = bar.Update
+	}
+
+	target, err := m.CreateWorktree(ctx, branch, createOpts)
+	bar.Done()
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.failHook(err, f.hookFlags)
 	}
 
 	fmt.Fprintf(r.Stderr, "[OK] Worktree created: %s\n", target.Path)
+	if f.printPath {
+		fmt.Fprintln(r.Stdout, target.Path)
+	}
 	return exitSuccess
 }
 
+func (r Runner) writeCreatePlan(plan wr.CreatePlan) {
+	fmt.Fprintf(r.Stderr, "==> [dry-run] Would create worktree: %s\n", plan.Path)
+	switch plan.BranchAction {
+	case wr.BranchActionTrackRemote:
+		fmt.Fprintf(r.Stderr, "[dry-run] Would create branch %s tracking origin/%s\n", plan.Branch, plan.Branch)
+	case wr.BranchActionExisting:
+		fmt.Fprintf(r.Stderr, "[dry-run] Would check out existing branch %s\n", plan.Branch)
+	case wr.BranchActionNew:
+		fmt.Fprintf(r.Stderr, "[dry-run] Would create branch %s from %s\n", plan.Branch, plan.FromRef)
+	}
+	if plan.Fetch {
+		fmt.Fprintln(r.Stderr, "[dry-run] Would fetch origin first (the plan uses the refs before the fetch)")
+	}
+	for _, file := range plan.Copied {
+		fmt.Fprintf(r.Stderr, "[dry-run] Would copy: %s\n", file)
+	}
+	r.writePlannedHooks(plan.Hooks)
+}
+
+func (r Runner) writePlannedHooks(planned []wr.PlannedHook) {
+	for _, h := range planned {
+		fmt.Fprintf(r.Stderr, "[dry-run] Would run %s hook #%d in %s: %s\n", h.Phase, h.Index, h.Dir, h.Command)
+	}
+}
+
+type removeFlags struct {
+	hookFlags
+
+	deleteBranch bool
+	force        bool
+	yes          bool
+	dryRun       bool
+}
+
+func removeSpec(f *removeFlags) commandSpec {
+	return commandSpec{
+		usage:   []string{"rm <id|branch|worktree-name> [<id|branch|worktree-name>...]"},
+		summary: "Remove worktrees. Without an identifier, pick them interactively on a terminal.",
+		flags: append(f.hookFlags.specs(),
+			switchFlag(&f.deleteBranch, "Also delete the branch (prompts unless --yes)", "--delete-branch"),
+			switchFlag(&f.force, "Remove even when the worktree is dirty or locked", "--force"),
+			switchFlag(&f.yes, "Do not prompt", "--yes").withEnv("GTR_YES"),
+			switchFlag(&f.dryRun, "Show what would be removed, deleted and run", "-n", "--dry-run"),
+		),
+		args: []completer{completeWorktrees},
+	}
+}
+
 func (r Runner) runRemove(ctx context.Context, args []string) int {
-	var (
-		deleteBranch bool
-		force        bool
-		yes          bool
-		idents       []string
-	)
-	for i := 0; i < len(args); {
-		switch args[i] {
-		case "--delete-branch":
-			deleteBranch = true
-			i++
-		case "--force":
-			force = true
-			i++
-		case "--yes":
-			yes = true
-			i++
-		default:
-			if strings.HasPrefix(args[i], "-") {
-				fmt.Fprintf(r.Stderr, "[x] Unknown flag: %s\n", args[i])
-				return exitUsage
-			}
-			idents = append(idents, args[i])
-			i++
-		}
+	var f removeFlags
+	spec := removeSpec(&f)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
 	}
+	idents := p.all()
 
-	if len(idents) == 0 {
-		fmt.Fprintln(r.Stderr, "[x] Usage: git wr rm <id|branch|worktree-name> [<id|branch|worktree-name>...]")
-		return exitUsage
+	if len(idents) == 0 && !r.canPick() {
+		return r.usageError(spec)
+	}
+	if f.quiet && f.verbose {
+		return r.usagef("--quiet and --verbose are mutually exclusive")
 	}
 
-	m, err := r.newManager(ctx)
+	m, err := r.newHookManager(ctx, f.hookFlags)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
+	}
+
+	if len(idents) == 0 {
+		if idents, err = r.pickWorktrees(ctx, m, true); err != nil {
+			return r.fail(err)
+		}
 	}
 
 	opts := wr.RemoveWorktreeOptions{
-		DeleteBranch: deleteBranch,
-		Force:        force,
-		Yes:          yes,
+		DeleteBranch: f.deleteBranch,
+		Force:        f.force,
+		Yes:          f.yes,
+	}
+	if f.dryRun {
+		plan, err := m.PlanRemove(ctx, idents, opts)
+		r.writeRemovePlan(plan, opts.Yes)
+		if err != nil {
+			return r.fail(err)
+		}
+		return exitSuccess
 	}
-	if deleteBranch && !yes {
+	if f.deleteBranch && !f.yes {
 		opts.ConfirmDeleteBranch = func(ctx context.Context, branch string) (bool, error) {
 			_ = ctx
 			return r.promptYesNo(fmt.Sprintf("Also delete branch %q?", branch))
@@ -466,106 +812,310 @@ func (r Runner) runRemove(ctx context.Context, args []string) int {
 	}
 
 	if err := m.Remove(ctx, idents, opts); err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.failHook(err, f.hookFlags)
 	}
 
 	return exitSuccess
 }
 
-func (r Runner) runCopy(ctx context.Context, args []string) int {
-	source := "1"
-	allMode := false
-	dryRun := false
-	var targets []string
-	var patterns []string
-
-	for i := 0; i < len(args); {
-		switch args[i] {
-		case "--from":
-			if i+1 >= len(args) {
-				fmt.Fprintln(r.Stderr, "[x] --from requires a value")
-				return exitUsage
+func (r Runner) writeRemovePlan(plan wr.RemovePlan, yes bool) {
+	for _, p := range plan.Worktrees {
+		if p.Skipped != "" {
+			detail := formatDirty(p.Dirty)
+			if p.Skipped == "locked" {
+				detail = p.LockReason
+				if detail == "" {
+					detail = "no reason given"
+				}
 			}
-			source = args[i+1]
-			i += 2
-		case "-a", "--all":
-			allMode = true
-			i++
-		case "-n", "--dry-run":
-			dryRun = true
-			i++
-		case "--":
-			i++
-			patterns = append(patterns, args[i:]...)
-			i = len(args)
-		default:
-			if strings.HasPrefix(args[i], "-") {
-				fmt.Fprintf(r.Stderr, "[x] Unknown flag: %s\n", args[i])
-				return exitUsage
+			fmt.Fprintf(r.Stderr, "[!] Would refuse %s (%s: %s; use --force): %s\n", p.Target.Branch, p.Skipped, detail, p.Target.Path)
+			continue
+		}
+		state := ""
+		if p.Dirty.Total() > 0 {
+			state = " (dirty: " + formatDirty(p.Dirty) + ")"
+		}
+		fmt.Fprintf(r.Stderr, "[dry-run] Would remove %s%s: %s\n", p.Target.Branch, state, p.Target.Path)
+		if p.DeleteBranch {
+			if yes {
+				fmt.Fprintf(r.Stderr, "[dry-run] Would delete branch: %s\n", p.Target.Branch)
+			} else {
+				fmt.Fprintf(r.Stderr, "[dry-run] Would ask to delete branch: %s\n", p.Target.Branch)
 			}
-			targets = append(targets, args[i])
-			i++
 		}
+		r.writePlannedHooks(p.Hooks)
 	}
+}
 
-	if !allMode && len(targets) == 0 {
-		fmt.Fprintln(r.Stderr, "[x] Usage: git wr copy <target>... [-n] [-a] [--from <source>] [-- <pattern>...]")
-		return exitUsage
+type copyFlags struct {
+	source      string
+	allMode     bool
+	dryRun      bool
+	mode        string
+	preserve    string
+	syncMode    bool
+	checksum    bool
+	deleteStale bool
+	ignoredOnly bool
+	conflict    string
+}
+
+func copySpec(f *copyFlags) commandSpec {
+	return commandSpec{
+		usage:   []string{"copy <target>... [options] [-- <pattern>...]"},
+		summary: "Copy files from the main repository (or --from) into worktrees.",
+		flags: []flagSpec{
+			switchFlag(&f.dryRun, "Show what would be copied", "-n", "--dry-run"),
+			switchFlag(&f.allMode, "Copy into every worktree", "-a", "--all"),
+			stringFlag(&f.source, "<source>", "Worktree to copy from (default: the main repository)", "--from").
+				withCompletion(completeWorktrees),
+			stringFlag(&f.mode, "<mode>", "Copy mode: copy, reflink, hardlink or symlink", "--mode").
+				withCompletion(completeWords("copy", "reflink", "hardlink", "symlink")),
+			stringFlag(&f.preserve, "<attrs>", "Attributes to preserve: symlinks, mode, times, xattrs, all or none", "--preserve").
+				withCompletion(completeWords("all", "none", "symlinks", "mode", "times", "xattrs")),
+			stringFlag(&f.conflict, "<policy>", "Existing files: overwrite, skip-existing, newer, backup or prompt", "--conflict").
+				withCompletion(completeWords("overwrite", "skip-existing", "newer", "backup", "prompt")),
+			switchFlag(&f.ignoredOnly, "Copy only files that git ignores", "--ignored-only"),
+			switchFlag(&f.syncMode, "Copy only files whose size or modification time differ", "--sync"),
+			switchFunc("Like --sync, comparing contents", func(on bool) {
+				f.checksum = on
+				f.syncMode = f.syncMode || on
+			}, "--checksum"),
+			switchFunc("Like --sync, also deleting files missing from the source (subject to --conflict)", func(on bool) {
+				f.deleteStale = on
+				f.syncMode = f.syncMode || on
+			}, "--delete"),
+		},
+		args: []completer{completeWorktrees},
+	}
+}
+
+func (r Runner) runCopy(ctx context.Context, args []string) int {
+	f := copyFlags{source: "1"}
+	spec := copySpec(&f)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	targets, patterns := p.args, p.rest
+
+	if !f.allMode && len(targets) == 0 && !r.canPick() {
+		return r.usageError(spec)
 	}
 
 	m, err := r.newManager(ctx)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
+	}
+
+	if !f.allMode && len(targets) == 0 {
+		if targets, err = r.pickWorktrees(ctx, m, true); err != nil {
+			return r.fail(err)
+		}
 	}
 
-	results, err := m.Copy(ctx, targets, wr.CopyOptions{
-		From:          source,
-		All:           allMode,
-		DryRun:        dryRun,
+	copyOpts := wr.CopyOptions{
+		From:          f.source,
+		All:           f.allMode,
+		DryRun:        f.dryRun,
 		Patterns:      patterns,
 		PreservePaths: true,
-	})
+		Mode:          wr.CopyMode(f.mode),
+		Preserve:      f.preserve,
+		Sync:          f.syncMode,
+		Checksum:      f.checksum,
+		Delete:        f.deleteStale,
+		Conflict:      wr.CopyConflictPolicy(f.conflict),
+		IgnoredOnly:   f.ignoredOnly,
+		ConfirmOverwrite: func(ctx context.Context, target wr.Target, path string) (bool, error) {
+			_ = ctx
+			return r.promptYesNo(fmt.Sprintf("Overwrite %s in %s?", path, target.Branch))
+		},
+		ConfirmDelete: func(ctx context.Context, target wr.Target, path string) (bool, error) {
+			_ = ctx
+			return r.promptYesNo(fmt.Sprintf("Delete %s from %s (no longer in source)?", path, target.Branch))
+		},
+	}
+	bar := newProgressBar(r.Stderr, "")
+	if isTerminal(r.Stderr) {
+		copyOpts.Progress = func(target wr.Target, p wr.CopyProgress) {
+			bar.label = "Copying to " + target.Branch
+			bar.Update(p)
+		}
+	}
+
+	results, err := m.Copy(ctx, targets, copyOpts)
+	bar.Done()
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
 	for _, res := range results {
-		if dryRun {
+		if f.dryRun {
 			fmt.Fprintf(r.Stderr, "==> [dry-run] Would copy to: %s\n", res.Target.Branch)
 		} else {
 			fmt.Fprintf(r.Stderr, "==> Copying to: %s\n", res.Target.Branch)
 		}
-		for _, f := range res.CopiedFiles {
-			if dryRun {
-				fmt.Fprintf(r.Stderr, "[dry-run] Would copy: %s\n", f)
+		for _, file := range res.CopiedFiles {
+			switch used := res.Modes[file]; {
+			case f.dryRun:
+				fmt.Fprintf(r.Stderr, "[dry-run] Would copy: %s\n", file)
+			case used != "" && used != wr.CopyModeCopy:
+				fmt.Fprintf(r.Stderr, "Copied %s (%s)\n", file, used)
+			default:
+				fmt.Fprintf(r.Stderr, "Copied %s\n", file)
+			}
+		}
+		for _, c := range res.Conflicts {
+			switch {
+			case c.Action == wr.CopyConflictActionSkip && c.Stale && f.dryRun:
+				fmt.Fprintf(r.Stderr, "[dry-run] Would keep: %s (no longer in source)\n", c.Path)
+			case c.Action == wr.CopyConflictActionSkip && c.Stale:
+				fmt.Fprintf(r.Stderr, "[!] Kept %s: no longer in source (see --conflict)\n", c.Path)
+			case c.Action == wr.CopyConflictActionSkip && f.dryRun:
+				fmt.Fprintf(r.Stderr, "[dry-run] Would skip: %s (exists in target)\n", c.Path)
+			case c.Action == wr.CopyConflictActionSkip:
+				fmt.Fprintf(r.Stderr, "[!] Skipped %s: exists in target (see --conflict)\n", c.Path)
+			case c.Action == wr.CopyConflictActionBackup && f.dryRun:
+				fmt.Fprintf(r.Stderr, "[dry-run] Would back up: %s to %s\n", c.Path, c.Backup)
+			case c.Action == wr.CopyConflictActionBackup:
+				fmt.Fprintf(r.Stderr, "Backed up %s to %s\n", c.Path, c.Backup)
+			}
+		}
+		for _, file := range res.Deleted {
+			if f.dryRun {
+				fmt.Fprintf(r.Stderr, "[dry-run] Would delete: %s\n", file)
 			} else {
-				fmt.Fprintf(r.Stderr, "Copied %s\n", f)
+				fmt.Fprintf(r.Stderr, "Deleted %s\n", file)
 			}
 		}
+		for _, file := range res.Protected {
+			fmt.Fprintf(r.Stderr, "[!] Kept %s: tracked or not ignored in target\n", file)
+		}
+		if f.syncMode {
+			fmt.Fprintf(r.Stderr, "%d added, %d updated, %d unchanged, %d skipped, %d deleted\n",
+				len(res.Added), len(res.Updated), len(res.Unchanged), len(res.Skipped), len(res.Deleted))
+		}
+	}
+
+	return exitSuccess
+}
+
+func diffSpec(format *string, opts *wr.DiffOptions) commandSpec {
+	return commandSpec{
+		usage:   []string{"diff <a> [<b>] [--stat|--name-only] [-u] [--format text|json]"},
+		summary: "Compare two worktrees (or one worktree and the main repository).",
+		flags: []flagSpec{
+			switchFunc("Show a diffstat", func(on bool) {
+				if on {
+					opts.Format = wr.DiffFormatStat
+				}
+			}, "--stat"),
+			switchFunc("Show only the names of changed files", func(on bool) {
+				if on {
+					opts.Format = wr.DiffFormatNameOnly
+				}
+			}, "--name-only"),
+			switchFlag(&opts.Uncommitted, "Also show uncommitted changes of both sides", "-u", "--uncommitted"),
+			stringFlag(format, "<format>", "Output format: text or json", "--format").
+				withCompletion(completeWords("text", "json")),
+		},
+		args: []completer{completeWorktrees, completeWorktrees, completeNone},
+	}
+}
+
+func (r Runner) runDiff(ctx context.Context, args []string) int {
+	format := "text"
+	var opts wr.DiffOptions
+	spec := diffSpec(&format, &opts)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	idents := p.all()
+	if len(idents) < 1 || len(idents) > 2 {
+		return r.usageError(spec)
+	}
+	switch format {
+	case "text", "json":
+	default:
+		return r.usagef("Unknown diff format: %s (want text or json)", format)
+	}
+	b := ""
+	if len(idents) == 2 {
+		b = idents[1]
+	}
+	opts.Color = format == "text" && isTerminal(r.Stdout)
+
+	m, err := r.newManager(ctx)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	res, err := m.Diff(ctx, idents[0], b, opts)
+	if err != nil {
+		return r.fail(err)
 	}
 
+	if format == "json" {
+		if err := wr.WriteDiffJSON(r.Stdout, res); err != nil {
+			return r.fail(err)
+		}
+		return exitSuccess
+	}
+
+	r.writeDiffSection(fmt.Sprintf("%s (%s) -> %s (%s)", res.A.Branch, shortSHA(res.AHead), res.B.Branch, shortSHA(res.BHead)), res.Committed)
+	if opts.Uncommitted {
+		r.writeDiffSection("Uncommitted in "+res.A.Branch, *res.UncommittedA)
+		r.writeDiffSection("Uncommitted in "+res.B.Branch, *res.UncommittedB)
+	}
 	return exitSuccess
 }
 
+// writeDiffSection prints a header to stderr and the diff text to stdout, so that the text can be piped.
+func (r Runner) writeDiffSection(title string, s wr.DiffSection) {
+	if s.Text == "" {
+		fmt.Fprintf(r.Stderr, "==> %s: no changes\n", title)
+		return
+	}
+	additions, deletions := s.Totals()
+	fmt.Fprintf(r.Stderr, "==> %s: %d file(s), +%d -%d\n", title, len(s.Files), additions, deletions)
+	fmt.Fprintln(r.Stdout, s.Text)
+}
+
+func goSpec() commandSpec {
+	return commandSpec{
+		usage:   []string{"go <id|branch|worktree-name>"},
+		summary: "Print the path of a worktree. Without an identifier, pick it interactively on a terminal.",
+		args:    []completer{completeWorktrees, completeNone},
+	}
+}
+
 func (r Runner) runGo(ctx context.Context, args []string) int {
-	if len(args) != 1 {
-		fmt.Fprintln(r.Stderr, "[x] Usage: git wr go <id|branch|worktree-name>")
-		return exitUsage
+	spec := goSpec()
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	args = p.all()
+	if len(args) > 1 || len(args) == 0 && !r.canPick() {
+		return r.usageError(spec)
 	}
 
 	m, err := r.newManager(ctx)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
+	}
+
+	if len(args) == 0 {
+		if args, err = r.pickWorktrees(ctx, m, false); err != nil {
+			return r.fail(err)
+		}
 	}
 
 	target, err := m.ResolveTarget(ctx, args[0])
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
 	if target.IsMain {
@@ -575,19 +1125,155 @@ func (r Runner) runGo(ctx context.Context, args []string) int {
 	}
 	fmt.Fprintf(r.Stderr, "Branch: %s\n", target.Branch)
 
+	block, err := m.Ports(ctx, target)
+	if err != nil {
+		return r.fail(err)
+	}
+	if !block.IsZero() {
+		fmt.Fprintf(r.Stderr, "Ports: %d-%d\n", block.Base, block.Base+block.Count-1)
+	}
+
 	fmt.Fprintln(r.Stdout, target.Path)
 	return exitSuccess
 }
 
+func describeSpec() commandSpec {
+	return commandSpec{
+		usage:   []string{"describe <id|branch|worktree-name> [<text>]"},
+		summary: "Show or set the description recorded for a worktree.",
+		args:    []completer{completeWorktrees, completeNone},
+	}
+}
+
+func (r Runner) runDescribe(ctx context.Context, args []string) int {
+	spec := describeSpec()
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	args = p.all()
+	if len(args) < 1 || len(args) > 2 {
+		return r.usageError(spec)
+	}
+
+	m, err := r.newManager(ctx)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	if len(args) == 2 {
+		target, err := m.Describe(ctx, args[0], args[1])
+		if err != nil {
+			return r.fail(err)
+		}
+		fmt.Fprintf(r.Stderr, "[OK] Updated description of %s\n", target.Branch)
+		return exitSuccess
+	}
+
+	target, err := m.ResolveTarget(ctx, args[0])
+	if err != nil {
+		return r.fail(err)
+	}
+	meta, err := m.Meta(target)
+	if err != nil {
+		return r.fail(err)
+	}
+	if meta.Description != "" {
+		fmt.Fprintln(r.Stdout, meta.Description)
+	}
+	if meta.FromRef != "" {
+		fmt.Fprintf(r.Stderr, "From: %s\n", meta.FromRef)
+	}
+	if meta.CreatedBy != "" || !meta.CreatedAt.IsZero() {
+		fmt.Fprintf(r.Stderr, "Created: %s by %s\n", meta.CreatedAt.Local().Format(time.DateTime), meta.CreatedBy)
+	}
+	return exitSuccess
+}
+
+func tagSpec(remove *bool) commandSpec {
+	return commandSpec{
+		usage:   []string{"tag [-d] <id|branch|worktree-name> [<tag>...]"},
+		summary: "List, add or remove (-d) the tags recorded for a worktree.",
+		flags: []flagSpec{
+			switchFlag(remove, "Remove the given tags", "-d", "--delete"),
+		},
+		args: []completer{completeWorktrees, completeTags},
+	}
+}
+
+func (r Runner) runTag(ctx context.Context, args []string) int {
+	remove := false
+	spec := tagSpec(&remove)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	rest := p.all()
+	if len(rest) == 0 || remove && len(rest) < 2 {
+		return r.usageError(spec)
+	}
+
+	m, err := r.newManager(ctx)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	if len(rest) == 1 {
+		target, err := m.ResolveTarget(ctx, rest[0])
+		if err != nil {
+			return r.fail(err)
+		}
+		meta, err := m.Meta(target)
+		if err != nil {
+			return r.fail(err)
+		}
+		for _, t := range meta.Tags {
+			fmt.Fprintln(r.Stdout, t)
+		}
+		return exitSuccess
+	}
+
+	target, err := m.Tag(ctx, rest[0], rest[1:], remove)
+	if err != nil {
+		return r.fail(err)
+	}
+	verb := "Tagged"
+	if remove {
+		verb = "Untagged"
+	}
+	fmt.Fprintf(r.Stderr, "[OK] %s %s: %s\n", verb, target.Branch, strings.Join(rest[1:], ", "))
+	return exitSuccess
+}
+
+func configSpec(global *bool) commandSpec {
+	return commandSpec{
+		usage: []string{
+			"config get <key> [--global]",
+			"config {set|add} <key> <value> [--global]",
+			"config unset <key> [--global]",
+		},
+		summary: "Read and write wr.* configuration in the repository or, with --global, the user config.",
+		flags: []flagSpec{
+			switchFlag(global, "Use the global git config", "--global"),
+		},
+		args: []completer{completeWords("get", "set", "add", "unset"), completeConfigKeys, completeNone},
+	}
+}
+
 func (r Runner) runConfig(ctx context.Context, args []string) int {
 	global := false
 	action := ""
 	key := ""
 	value := ""
 
-	for _, a := range args {
+	spec := configSpec(&global)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	for _, a := range p.all() {
 		switch a {
-		case "--global", "global":
+		case "global":
 			global = true
 		case "get", "set", "add", "unset":
 			if action == "" {
@@ -611,20 +1297,17 @@ func (r Runner) runConfig(ctx context.Context, args []string) int {
 
 	m, err := r.newManager(ctx)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
 	switch action {
 	case "get":
 		if key == "" {
-			fmt.Fprintln(r.Stderr, "[x] Usage: git wr config get <key> [--global]")
-			return exitUsage
+			return r.usagef("Usage: git wr config get <key> [--global]")
 		}
 		values, err := m.ConfigGet(ctx, key, global)
 		if err != nil {
-			fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-			return exitFailure
+			return r.fail(err)
 		}
 		for _, v := range values {
 			fmt.Fprintln(r.Stdout, v)
@@ -633,82 +1316,78 @@ func (r Runner) runConfig(ctx context.Context, args []string) int {
 
 	case "set":
 		if key == "" || value == "" {
-			fmt.Fprintln(r.Stderr, "[x] Usage: git wr config set <key> <value> [--global]")
-			return exitUsage
+			return r.usagef("Usage: git wr config set <key> <value> [--global]")
 		}
 		if err := m.ConfigSet(ctx, key, value, global); err != nil {
-			fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-			return exitFailure
+			return r.fail(err)
 		}
 		fmt.Fprintf(r.Stderr, "[OK] Config set: %s = %s\n", key, value)
 		return exitSuccess
 
 	case "add":
 		if key == "" || value == "" {
-			fmt.Fprintln(r.Stderr, "[x] Usage: git wr config add <key> <value> [--global]")
-			return exitUsage
+			return r.usagef("Usage: git wr config add <key> <value> [--global]")
 		}
 		if err := m.ConfigAdd(ctx, key, value, global); err != nil {
-			fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-			return exitFailure
+			return r.fail(err)
 		}
 		fmt.Fprintf(r.Stderr, "[OK] Config added: %s = %s\n", key, value)
 		return exitSuccess
 
 	case "unset":
 		if key == "" {
-			fmt.Fprintln(r.Stderr, "[x] Usage: git wr config unset <key> [--global]")
-			return exitUsage
+			return r.usagef("Usage: git wr config unset <key> [--global]")
 		}
 		if err := m.ConfigUnset(ctx, key, global); err != nil {
-			fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-			return exitFailure
+			return r.fail(err)
 		}
 		fmt.Fprintf(r.Stderr, "[OK] Config unset: %s\n", key)
 		return exitSuccess
 
 	default:
-		fmt.Fprintf(r.Stderr, "[x] Unknown config action: %s\n", action)
-		return exitUsage
+		return r.usagef("Unknown config action: %s", action)
+	}
+}
+
+func editorSpec(editor *string) commandSpec {
+	return commandSpec{
+		usage:   []string{"editor <id|branch|worktree-name> [--editor <name>]"},
+		summary: "Open a worktree in an editor (default: wr.editor.default).",
+		flags: []flagSpec{
+			stringFlag(editor, "<name>", "Editor adapter or command to use", "--editor").
+				withCompletion(completeAdapters(adapters.KindEditor)),
+		},
+		args: []completer{completeWorktrees, completeNone},
 	}
 }
 
 func (r Runner) runEditor(ctx context.Context, args []string) int {
 	editor := ""
-	identifier := ""
-
-	for i := 0; i < len(args); {
-		switch args[i] {
-		case "--editor":
-			if i+1 >= len(args) {
-				fmt.Fprintln(r.Stderr, "[x] --editor requires a value")
-				return exitUsage
-			}
-			editor = args[i+1]
-			i += 2
-		default:
-			if strings.HasPrefix(args[i], "-") {
-				fmt.Fprintf(r.Stderr, "[x] Unknown flag: %s\n", args[i])
-				return exitUsage
-			}
-			if identifier != "" {
-				fmt.Fprintln(r.Stderr, "[x] Usage: git wr editor <id|branch|worktree-name> [--editor <name>]")
-				return exitUsage
-			}
-			identifier = args[i]
-			i++
-		}
+	spec := editorSpec(&editor)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
 	}
-
-	if identifier == "" {
-		fmt.Fprintln(r.Stderr, "[x] Usage: git wr editor <id|branch|worktree-name> [--editor <name>]")
-		return exitUsage
+	positional := p.all()
+	if len(positional) > 1 || len(positional) == 0 && !r.canPick() {
+		return r.usageError(spec)
+	}
+	identifier := ""
+	if len(positional) == 1 {
+		identifier = positional[0]
 	}
 
 	m, err := r.newManager(ctx)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
+	}
+
+	if identifier == "" {
+		picked, err := r.pickWorktrees(ctx, m, false)
+		if err != nil {
+			return r.fail(err)
+		}
+		identifier = picked[0]
 	}
 
 	exitCode, err := m.OpenEditor(ctx, identifier, editor, wr.ExecIO{
@@ -717,56 +1396,55 @@ func (r Runner) runEditor(ctx context.Context, args []string) int {
 		Stderr: r.Stderr,
 	})
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
+		r.reportError(err)
 		if exitCode != 0 {
 			return exitCode
 		}
-		return exitFailure
+		return exitCodeFor(err)
 	}
 
 	return exitCode
 }
 
+func aiSpec(tool *string) commandSpec {
+	return commandSpec{
+		usage:   []string{"ai <id|branch|worktree-name> [--ai <name>] [-- args...]"},
+		summary: "Start an AI tool in a worktree (default: wr.ai.default). Arguments after -- are passed to the tool.",
+		flags: []flagSpec{
+			stringFlag(tool, "<name>", "AI tool adapter or command to use", "--ai").
+				withCompletion(completeAdapters(adapters.KindAI)),
+		},
+		args: []completer{completeWorktrees, completeNone},
+	}
+}
+
 func (r Runner) runAI(ctx context.Context, args []string) int {
 	tool := ""
-	identifier := ""
-	var toolArgs []string
-
-	for i := 0; i < len(args); {
-		switch args[i] {
-		case "--ai":
-			if i+1 >= len(args) {
-				fmt.Fprintln(r.Stderr, "[x] --ai requires a value")
-				return exitUsage
-			}
-			tool = args[i+1]
-			i += 2
-		case "--":
-			toolArgs = append(toolArgs, args[i+1:]...)
-			i = len(args)
-		default:
-			if strings.HasPrefix(args[i], "-") {
-				fmt.Fprintf(r.Stderr, "[x] Unknown flag: %s\n", args[i])
-				return exitUsage
-			}
-			if identifier != "" {
-				fmt.Fprintln(r.Stderr, "[x] Usage: git wr ai <id|branch|worktree-name> [--ai <name>] [-- args...]")
-				return exitUsage
-			}
-			identifier = args[i]
-			i++
-		}
+	spec := aiSpec(&tool)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
 	}
-
-	if identifier == "" {
-		fmt.Fprintln(r.Stderr, "[x] Usage: git wr ai <id|branch|worktree-name> [--ai <name>] [-- args...]")
-		return exitUsage
+	if len(p.args) > 1 || len(p.args) == 0 && !r.canPick() {
+		return r.usageError(spec)
+	}
+	identifier := ""
+	if len(p.args) == 1 {
+		identifier = p.args[0]
 	}
+	toolArgs := p.rest
 
 	m, err := r.newManager(ctx)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
+	}
+
+	if identifier == "" {
+		picked, err := r.pickWorktrees(ctx, m, false)
+		if err != nil {
+			return r.fail(err)
+		}
+		identifier = picked[0]
 	}
 
 	exitCode, err := m.RunAI(ctx, identifier, tool, toolArgs, wr.ExecIO{
@@ -775,31 +1453,104 @@ func (r Runner) runAI(ctx context.Context, args []string) int {
 		Stderr: r.Stderr,
 	})
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitCode
+		r.reportError(err)
+		if exitCode != 0 {
+			return exitCode
+		}
+		return exitCodeFor(err)
 	}
 
 	return exitCode
 }
 
+func cleanSpec(opts *wr.CleanOptions, hookOut *hookFlags) commandSpec {
+	return commandSpec{
+		usage:   []string{"clean [--merged] [--stale <duration>] [-n] [--delete-branch] [--yes]"},
+		summary: "Prune stale worktree metadata and remove empty directories; optionally remove merged or stale worktrees.",
+		flags: append(hookOut.specs(),
+			switchFlag(&opts.Merged, "Remove worktrees whose branch is merged into the default branch", "--merged"),
+			valueFunc("<duration>", "Remove worktrees without commits for <duration> (e.g. 72h, 14d, 2w)", func(v string) error {
+				d, err := parseAge(v)
+				if err != nil || d <= 0 {
+					return errors.New("examples: 72h, 14d, 2w")
+				}
+				opts.Stale = d
+				return nil
+			}, "--stale"),
+			switchFlag(&opts.DryRun, "Show what would be removed", "-n", "--dry-run"),
+			switchFlag(&opts.DeleteBranch, "Also delete the branches of removed worktrees", "--delete-branch"),
+			switchFlag(&opts.Yes, "Do not prompt", "--yes").withEnv("GTR_YES"),
+		),
+		args: []completer{completeNone},
+	}
+}
+
 func (r Runner) runClean(ctx context.Context, args []string) int {
-	if len(args) != 0 {
-		fmt.Fprintln(r.Stderr, "[x] Usage: git wr clean")
-		return exitUsage
+	var (
+		opts    wr.CleanOptions
+		hookOut hookFlags
+	)
+	spec := cleanSpec(&opts, &hookOut)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	if len(p.all()) != 0 {
+		return r.usageError(spec)
+	}
+	if hookOut.quiet && hookOut.verbose {
+		return r.usagef("--quiet and --verbose are mutually exclusive")
 	}
 
-	m, err := r.newManager(ctx)
+	m, err := r.newHookManager(ctx, hookOut)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
-	result, err := m.Clean(ctx)
+	// Confirm runs under the same lock as the removal, so the plan shown is the plan executed.
+	declined := false
+	opts.Confirm = func(ctx context.Context, plan []wr.CleanCandidate) (bool, error) {
+		_ = ctx
+		r.writeCleanPlan(plan, false)
+		ok, err := r.promptYesNo(fmt.Sprintf("Remove %d worktree(s)?", len(plan)))
+		declined = err == nil && !ok
+		return ok, err
+	}
+	if opts.DeleteBranch {
+		opts.ConfirmDeleteBranch = func(ctx context.Context, branch string) (bool, error) {
+			_ = ctx
+			return r.promptYesNo(fmt.Sprintf("Also delete branch %q?", branch))
+		}
+	}
+	result, err := m.CleanWithOptions(ctx, opts)
+	if opts.DryRun || opts.Yes {
+		r.writeCleanPlan(result.Candidates, opts.DryRun)
+	} else {
+		for _, c := range result.Candidates {
+			if c.Skipped != "" {
+				r.writeCleanCandidate(c, false)
+			}
+		}
+	}
+	for _, t := range result.Removed {
+		fmt.Fprintf(r.Stderr, "[OK] Removed worktree: %s\n", t.Path)
+	}
+	for _, b := range result.DeletedBranches {
+		fmt.Fprintf(r.Stderr, "[OK] Deleted branch: %s\n", b)
+	}
+	if declined {
+		err = errors.Join(err, fmt.Errorf("clean %w", wr.ErrDeclined))
+	}
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.failHook(err, hookOut)
 	}
 
+	if opts.DryRun {
+		for _, dir := range result.RemovedEmptyDirs {
+			fmt.Fprintf(r.Stderr, "[dry-run] Would remove empty directory: %s\n", dir)
+		}
+		return exitSuccess
+	}
 	if len(result.RemovedEmptyDirs) == 0 {
 		fmt.Fprintln(r.Stderr, "[OK] Cleanup complete (no empty directories found)")
 		return exitSuccess
@@ -808,32 +1559,90 @@ func (r Runner) runClean(ctx context.Context, args []string) int {
 	return exitSuccess
 }
 
+func (r Runner) writeCleanPlan(candidates []wr.CleanCandidate, dryRun bool) {
+	if len(candidates) == 0 {
+		return
+	}
+	fmt.Fprintln(r.Stderr, "==> Clean plan")
+	for _, c := range candidates {
+		r.writeCleanCandidate(c, dryRun)
+	}
+}
+
+func (r Runner) writeCleanCandidate(c wr.CleanCandidate, dryRun bool) {
+	reasons := strings.Join(c.Reasons, ", ")
+	switch {
+	case c.Skipped != "":
+		fmt.Fprintf(r.Stderr, "[!] Skipping %s (%s; %s): %s\n", c.Target.Branch, reasons, c.Skipped, c.Target.Path)
+	case dryRun:
+		fmt.Fprintf(r.Stderr, "[dry-run] Would remove %s (%s): %s\n", c.Target.Branch, reasons, c.Target.Path)
+	default:
+		fmt.Fprintf(r.Stderr, "Remove %s (%s): %s\n", c.Target.Branch, reasons, c.Target.Path)
+	}
+}
+
+// parseAge parses a duration accepting the time.ParseDuration syntax plus "d" (days) and "w" (weeks).
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+func doctorSpec() commandSpec {
+	return commandSpec{
+		usage:   []string{"doctor"},
+		summary: "Check the git installation, repository configuration and adapters.",
+		args:    []completer{completeNone},
+	}
+}
+
 func (r Runner) runDoctor(ctx context.Context, args []string) int {
-	if len(args) != 0 {
-		fmt.Fprintln(r.Stderr, "[x] Usage: git wr doctor")
-		return exitUsage
+	spec := doctorSpec()
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	if len(p.all()) != 0 {
+		return r.usageError(spec)
 	}
 
 	m, err := r.newManager(ctx)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
 	report, err := m.Doctor(ctx)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
 	wr.WriteDoctorReport(r.Stdout, report)
 	return exitSuccess
 }
 
+func adapterSpec() commandSpec {
+	return commandSpec{
+		usage:   []string{"adapter"},
+		summary: "List the built-in editor and AI tool adapters and whether they are installed.",
+		args:    []completer{completeNone},
+	}
+}
+
 func (r Runner) runAdapters(ctx context.Context, args []string) int {
-	if len(args) != 0 {
-		fmt.Fprintln(r.Stderr, "[x] Usage: git wr adapter")
-		return exitUsage
+	spec := adapterSpec()
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	if len(p.all()) != 0 {
+		return r.usageError(spec)
 	}
 
 	fmt.Fprintln(r.Stdout, "Available Adapters")
@@ -863,25 +1672,89 @@ func (r Runner) runAdapters(ctx context.Context, args []string) int {
 	return exitSuccess
 }
 
+type runFlags struct {
+	allMode     bool
+	filter      string
+	identifiers []string
+	jobs        int
+}
+
+func runSpec(f *runFlags) commandSpec {
+	return commandSpec{
+		usage: []string{
+			"run <id|branch|worktree-name> <command...>",
+			"run -- <command...>",
+			"run {--all | --filter <glob> | -t <id>...} [-j <n>] [--] <command...>",
+		},
+		summary: "Run a command in one worktree, or in several with --all, --filter or --target.",
+		flags: []flagSpec{
+			switchFlag(&f.allMode, "Run in every worktree", "-a", "--all"),
+			stringFlag(&f.filter, "<glob>", "Run in worktrees whose branch matches <glob>", "--filter"),
+			listFlag(&f.identifiers, "<id>", "Run in this worktree (repeatable)", "-t", "--target").
+				withCompletion(completeWorktrees),
+			valueFunc("<n>", "Run at most <n> commands at a time", func(v string) error {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 1 {
+					return errors.New("expected a positive integer")
+				}
+				f.jobs = n
+				return nil
+			}, "-j", "--jobs"),
+		},
+		args: []completer{completeWorktrees, completeFiles},
+		argsWith: map[string][]completer{
+			"-a": commandArgs, "--all": commandArgs, "--filter": commandArgs, "-t": commandArgs, "--target": commandArgs,
+		},
+		stopAtArg: true,
+	}
+}
+
 func (r Runner) runRun(ctx context.Context, args []string) int {
-	if len(args) < 2 {
-		fmt.Fprintln(r.Stderr, "[x] Usage: git wr run <id|branch|worktree-name> <command...>")
-		return exitUsage
+	var f runFlags
+	spec := runSpec(&f)
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	args = p.all()
+
+	if f.allMode || f.filter != "" || len(f.identifiers) > 0 {
+		return r.runRunMulti(ctx, args, wr.RunAllOptions{
+			Identifiers: f.identifiers,
+			Filter:      f.filter,
+			Concurrency: f.jobs,
+		})
+	}
+	if f.jobs != 0 {
+		return r.usagef("--jobs requires --all, --filter or --target")
 	}
 
-	identifier := args[0]
-	command := args[1:]
+	// "run -- <command...>" leaves out the identifier, so the worktree is picked interactively.
+	pick := p.dashdash && len(p.args) == 0
+	if len(args) == 0 || pick && !r.canPick() || !pick && len(args) < 2 {
+		return r.usageError(spec)
+	}
 
 	m, err := r.newManager(ctx)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
+	}
+
+	var identifier string
+	var command []string
+	if pick {
+		picked, err := r.pickWorktrees(ctx, m, false)
+		if err != nil {
+			return r.fail(err)
+		}
+		identifier, command = picked[0], args
+	} else {
+		identifier, command = args[0], args[1:]
 	}
 
 	target, err := m.ResolveTarget(ctx, identifier)
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
-		return exitFailure
+		return r.fail(err)
 	}
 
 	if target.IsMain {
@@ -899,9 +1772,68 @@ func (r Runner) runRun(ctx context.Context, args []string) int {
 		},
 	})
 	if err != nil {
-		fmt.Fprintf(r.Stderr, "[x] %v\n", err)
+		return r.fail(err)
+	}
+
+	return childExitCode(exitCode)
+}
+
+// childExitCode returns the exit status for a command that exited with code. Commands killed by a signal
+// report -1, which becomes exitFailure.
+func childExitCode(code int) int {
+	if code < 0 {
 		return exitFailure
 	}
+	return code
+}
+
+// runRunMulti runs command in several worktrees and returns the largest exit code among them.
+func (r Runner) runRunMulti(ctx context.Context, command []string, opts wr.RunAllOptions) int {
+	if len(command) == 0 {
+		return r.usageError(runSpec(&runFlags{}))
+	}
+
+	m, err := r.newManager(ctx)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	fmt.Fprintf(r.Stderr, "Command: %s\n\n", strings.Join(command, " "))
+
+	opts.Stdout = r.Stdout
+	opts.Stderr = r.Stderr
+	results, err := m.RunAll(ctx, command, opts)
+	if err != nil {
+		return r.fail(err)
+	}
+
+	fmt.Fprintln(r.Stderr)
+	fmt.Fprintln(r.Stderr, "==> Summary")
+	fmt.Fprintf(r.Stderr, "%-30s %-6s %-10s %s\n", "BRANCH", "EXIT", "DURATION", "PATH")
+	fmt.Fprintf(r.Stderr, "%-30s %-6s %-10s %s\n", "------", "----", "--------", "----")
+
+	exitCode := exitSuccess
+	failed := 0
+	for _, res := range results {
+		branch := res.Target.Branch
+		if res.Target.IsMain {
+			branch += " [main repo]"
+		}
+		fmt.Fprintf(r.Stderr, "%-30s %-6d %-10s %s\n", branch, res.ExitCode, res.Duration.Round(time.Millisecond), res.Target.Path)
+		if res.Err != nil {
+			r.reportError(fmt.Errorf("%s: %w", res.Target.Branch, res.Err))
+		}
+		if res.ExitCode != 0 {
+			failed++
+			exitCode = max(exitCode, childExitCode(res.ExitCode))
+		}
+	}
 
+	fmt.Fprintln(r.Stderr)
+	if failed == 0 {
+		fmt.Fprintf(r.Stderr, "[OK] Command succeeded in %d worktree(s)\n", len(results))
+	} else {
+		fmt.Fprintf(r.Stderr, "[x] Command failed in %d of %d worktree(s)\n", failed, len(results))
+	}
 	return exitCode
 }
@@ -19,10 +19,21 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/gitcmd"
+	"github.com/zchee/git-worktree-runner/internal/gitx"
+	"github.com/zchee/git-worktree-runner/internal/hooks"
+	"github.com/zchee/git-worktree-runner/internal/lock"
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+	"github.com/zchee/git-worktree-runner/wr"
 )
 
 func TestRunnerRun(t *testing.T) {
@@ -167,3 +178,229 @@ func TestMain_Help_DefaultsToHelp(t *testing.T) {
 		t.Fatalf("stderr = %q, want empty", got)
 	}
 }
+
+func TestExitCodeFor(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err  error
+		want int
+	}{
+		"target not found": {
+			err:  fmt.Errorf("resolve nope: %w", wr.ErrTargetNotFound),
+			want: exitTargetNotFound,
+		},
+		"ambiguous target": {
+			err:  wr.ErrAmbiguousTarget,
+			want: exitTargetNotFound,
+		},
+		"lock timeout": {
+			err:  fmt.Errorf("lock: %w", lock.ErrAcquireTimeout),
+			want: exitLockTimeout,
+		},
+		"hook failed": {
+			err:  fmt.Errorf("preCreate: %w", hooks.ErrHookFailed),
+			want: exitHookFailed,
+		},
+		"git failed": {
+			err:  fmt.Errorf("git worktree remove: %w", &gitcmd.ExitError{}),
+			want: exitGitFailed,
+		},
+		"worktree dirty": {
+			err:  wr.ErrWorktreeDirty,
+			want: exitWorktreeDirty,
+		},
+		"worktree locked": {
+			err:  wr.ErrWorktreeLocked,
+			want: exitWorktreeDirty,
+		},
+		"declined": {
+			err:  errPickerCanceled,
+			want: exitDeclined,
+		},
+		"other failure": {
+			err:  errors.New("boom"),
+			want: exitFailure,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tc.want, exitCodeFor(tc.err)); diff != "" {
+				t.Fatalf("exit code mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunnerExitCodes(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+	mainBranch, err := gitx.CurrentBranchGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("CurrentBranchGit() error: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "wr.defaultBranch", mainBranch); err != nil {
+		t.Fatalf("git config wr.defaultBranch: %v", err)
+	}
+	worktreesDir := repoDir + "-worktrees"
+	for _, branch := range []string{"dirty", "locked", "merged"} {
+		if _, err := g.Run(t.Context(), repoDir, "worktree", "add", "-b", branch, filepath.Join(worktreesDir, branch)); err != nil {
+			t.Fatalf("git worktree add %s: %v", branch, err)
+		}
+	}
+	if _, err := g.Run(t.Context(), filepath.Join(worktreesDir, "merged"), "commit", "--allow-empty", "-m", "merged"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "merge", "--ff-only", "merged"); err != nil {
+		t.Fatalf("git merge merged: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreesDir, "dirty", "README.md"), []byte("changed\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(README.md): %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "worktree", "lock", filepath.Join(worktreesDir, "locked")); err != nil {
+		t.Fatalf("git worktree lock: %v", err)
+	}
+	t.Chdir(repoDir)
+
+	tests := map[string]struct {
+		args          []string
+		stdin         string
+		config        []string
+		want          int
+		wantStdoutSub string
+		wantStderrSub string
+	}{
+		"usage error": {
+			args: []string{"go"},
+			want: exitUsage,
+		},
+		"target not found": {
+			args: []string{"go", "nope"},
+			want: exitTargetNotFound,
+		},
+		"hook failed": {
+			args:   []string{"new", "hooked", "--no-copy"},
+			config: []string{"wr.hook.preCreate", "exit 3"},
+			want:   exitHookFailed,
+		},
+		"git failed": {
+			args: []string{"new", "bad..name", "--no-copy"},
+			want: exitGitFailed,
+		},
+		"run without command": {
+			args:          []string{"run", "merged"},
+			want:          exitUsage,
+			wantStderrSub: "Usage:",
+		},
+		"run killed by signal": {
+			args: []string{"run", "1", "sh", "-c", "kill -KILL $$"},
+			want: exitFailure,
+		},
+		"run --all killed by signal": {
+			args: []string{"run", "--all", "--", "sh", "-c", "kill -KILL $$"},
+			want: exitFailure,
+		},
+		"worktree dirty": {
+			args: []string{"rm", "dirty", "--yes"},
+			want: exitWorktreeDirty,
+		},
+		"worktree locked": {
+			args: []string{"rm", "locked", "--yes"},
+			want: exitWorktreeDirty,
+		},
+		"declined": {
+			args:          []string{"clean", "--merged"},
+			stdin:         "n\n",
+			want:          exitDeclined,
+			wantStderrSub: "==> Clean plan\nRemove merged (merged): " + filepath.Join(worktreesDir, "merged") + "\n[?] Remove 1 worktree(s)? [y/N]:",
+		},
+		"help exit-codes": {
+			args:          []string{"help", "exit-codes"},
+			want:          exitSuccess,
+			wantStdoutSub: "  7  Refused to remove a worktree with uncommitted changes",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.config != nil {
+				if _, err := g.Run(t.Context(), repoDir, append([]string{"config", "--local"}, tc.config...)...); err != nil {
+					t.Fatalf("git config %q: %v", tc.config, err)
+				}
+				t.Cleanup(func() {
+					_, _ = g.Run(context.Background(), repoDir, "config", "--local", "--unset", tc.config[0])
+				})
+			}
+
+			var stdout, stderr bytes.Buffer
+			r := Runner{Stdin: strings.NewReader(tc.stdin), Stdout: &stdout, Stderr: &stderr}
+			if diff := cmp.Diff(tc.want, r.Run(t.Context(), tc.args)); diff != "" {
+				t.Fatalf("exit code mismatch (-want +got):\n%s\nstderr: %s", diff, stderr.String())
+			}
+			if tc.wantStdoutSub != "" && !strings.Contains(stdout.String(), tc.wantStdoutSub) {
+				t.Fatalf("stdout mismatch: expected substring %q, got %q", tc.wantStdoutSub, stdout.String())
+			}
+			if tc.wantStderrSub != "" && !strings.Contains(stderr.String(), tc.wantStderrSub) {
+				t.Fatalf("stderr mismatch: expected substring %q, got %q", tc.wantStderrSub, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunnerDryRun(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+	worktreePath := filepath.Join(repoDir+"-worktrees", "existing")
+	if _, err := g.Run(t.Context(), repoDir, "worktree", "add", "-b", "existing", worktreePath); err != nil {
+		t.Fatalf("git worktree add: %v", err)
+	}
+	t.Chdir(repoDir)
+
+	tests := map[string]struct {
+		args       []string
+		wantStderr string
+		wantAbsent string
+	}{
+		"success: new": {
+			args: []string{"new", "feature-a", "--from", "existing", "--no-fetch", "-n"},
+			wantStderr: "==> [dry-run] Would create worktree: " + filepath.Join(repoDir+"-worktrees", "feature-a") + "\n" +
+				"[dry-run] Would create branch feature-a from existing\n",
+			wantAbsent: filepath.Join(repoDir+"-worktrees", "feature-a"),
+		},
+		"success: rm": {
+			args:       []string{"rm", "existing", "--delete-branch", "--dry-run"},
+			wantStderr: "[dry-run] Would remove existing: " + worktreePath + "\n[dry-run] Would ask to delete branch: existing\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := Runner{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr}
+			if got := r.Run(t.Context(), tc.args); got != exitSuccess {
+				t.Fatalf("Run(%q) = %d, stderr=%q", tc.args, got, stderr.String())
+			}
+			if diff := cmp.Diff(tc.wantStderr, stderr.String()); diff != "" {
+				t.Fatalf("stderr mismatch (-want +got):\n%s", diff)
+			}
+			if tc.wantAbsent != "" {
+				if _, err := os.Stat(tc.wantAbsent); !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("expected %s not to be created: stat err=%v", tc.wantAbsent, err)
+				}
+			}
+		})
+	}
+
+	if _, err := os.Stat(worktreePath); err != nil {
+		t.Fatalf("expected %s to remain: %v", worktreePath, err)
+	}
+}
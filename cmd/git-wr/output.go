@@ -0,0 +1,110 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/zchee/git-worktree-runner/internal/hooks"
+	"github.com/zchee/git-worktree-runner/wr"
+)
+
+// outputFlag declares --output, which every command accepts.
+func outputFlag(p *string) flagSpec {
+	return stringFlag(p, "<format>", "Report progress and errors on stderr as text or as JSON events (json)", "--output").
+		withEnv("GTR_OUTPUT").
+		withCompletion(completeWords("text", "json"))
+}
+
+// withOutput returns s with the --output flag appended.
+func (s commandSpec) withOutput(p *string) commandSpec {
+	s.flags = append(slices.Clone(s.flags), outputFlag(p))
+	return s
+}
+
+// setOutput switches r to the given --output format. With json, the text normally written to stderr is
+// discarded and stderr receives one wr.Event per line instead.
+func (r *Runner) setOutput(format string) error {
+	switch format {
+	case "", "text":
+	case "json":
+		if r.events == nil {
+			r.events, r.Stderr = r.Stderr, io.Discard
+		}
+	default:
+		return fmt.Errorf("Unknown output format: %s (want text or json)", format)
+	}
+	return nil
+}
+
+// emit writes e to the event stream when --output=json is in effect.
+func (r Runner) emit(e wr.Event) {
+	if r.events == nil {
+		return
+	}
+	_ = wr.WriteEventJSON(r.events, e)
+}
+
+// fail reports err and returns the exit status for it (see exitCodes).
+func (r Runner) fail(err error) int {
+	r.reportError(err)
+	return exitCodeFor(err)
+}
+
+// reportError reports err as "[x] <err>" on stderr, or as an error event with --output=json.
+func (r Runner) reportError(err error) {
+	if r.events != nil {
+		r.emit(wr.ErrorEvent(err))
+		return
+	}
+	fmt.Fprintf(r.Stderr, "[x] %v\n", err)
+}
+
+// usagef reports a usage error and returns exitUsage.
+func (r Runner) usagef(format string, args ...any) int {
+	msg := fmt.Sprintf(format, args...)
+	if r.events != nil {
+		e := wr.ErrorEvent(errors.New(msg))
+		e.Code = wr.ErrorCodeUsage
+		r.emit(e)
+		return exitUsage
+	}
+	fmt.Fprintf(r.Stderr, "[x] %s\n", msg)
+	return exitUsage
+}
+
+// failHook is like fail for commands that run hooks. With --quiet, hook output was not streamed, so the
+// stderr of a failing hook is shown here.
+func (r Runner) failHook(err error, f hookFlags) int {
+	code := r.fail(err)
+
+	var he *hooks.HookError
+	if r.events != nil || !f.quiet || !errors.As(err, &he) || he.Stderr == "" {
+		return code
+	}
+	for line := range strings.Lines(he.Stderr) {
+		fmt.Fprintf(r.Stderr, "    %s", line)
+	}
+	if !strings.HasSuffix(he.Stderr, "\n") {
+		fmt.Fprintln(r.Stderr)
+	}
+	return code
+}
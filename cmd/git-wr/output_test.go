@@ -0,0 +1,113 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/zchee/git-worktree-runner/wr"
+)
+
+func TestOutputJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args         []string
+		env          map[string]string
+		wantExitCode int
+		wantEvents   []wr.Event
+		wantStderr   string
+	}{
+		"success: usage error event": {
+			args:         []string{"go", "--output=json"},
+			wantExitCode: exitUsage,
+			wantEvents:   []wr.Event{{Type: wr.EventError, Code: wr.ErrorCodeUsage, Message: "Usage: git wr go <id|branch|worktree-name>"}},
+		},
+		"success: unknown flag after --output json": {
+			args:         []string{"list", "--output", "json", "--nope"},
+			wantExitCode: exitUsage,
+			wantEvents:   []wr.Event{{Type: wr.EventError, Code: wr.ErrorCodeUsage, Message: "Unknown flag: --nope"}},
+		},
+		"success: environment fallback": {
+			args:         []string{"init", "tcsh"},
+			env:          map[string]string{"GTR_OUTPUT": "json"},
+			wantExitCode: exitUsage,
+			wantEvents:   []wr.Event{{Type: wr.EventError, Code: wr.ErrorCodeUsage, Message: "Unsupported shell: tcsh (want bash, zsh or fish)"}},
+		},
+		"success: text output": {
+			args:         []string{"go", "--output=text", "a", "b"},
+			wantExitCode: exitUsage,
+			wantStderr:   "[x] Usage: git wr go <id|branch|worktree-name>\n",
+		},
+		"error: unknown output format": {
+			args:         []string{"doctor", "--output=yaml"},
+			wantExitCode: exitUsage,
+			wantStderr:   "[x] Unknown output format: yaml (want text or json)\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			r := Runner{
+				Stdin:  strings.NewReader(""),
+				Stdout: &stdout,
+				Stderr: &stderr,
+				LookupEnv: func(key string) (string, bool) {
+					v, ok := tc.env[key]
+					return v, ok
+				},
+			}
+			if got := r.Run(t.Context(), tc.args); got != tc.wantExitCode {
+				t.Fatalf("Run(%q) = %d, want %d (stderr=%q)", tc.args, got, tc.wantExitCode, stderr.String())
+			}
+			if tc.wantEvents == nil {
+				if diff := cmp.Diff(tc.wantStderr, stderr.String()); diff != "" {
+					t.Fatalf("stderr mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+
+			var got []wr.Event
+			dec := json.NewDecoder(&stderr)
+			for dec.More() {
+				var line struct {
+					SchemaVersion int `json:"schemaVersion"`
+					wr.Event
+				}
+				if err := dec.Decode(&line); err != nil {
+					t.Fatalf("decode event: %v", err)
+				}
+				if line.SchemaVersion != wr.EventSchemaVersion || line.Time.IsZero() {
+					t.Fatalf("event %+v: want schemaVersion %d and a time", line, wr.EventSchemaVersion)
+				}
+				got = append(got, line.Event)
+			}
+			if diff := cmp.Diff(tc.wantEvents, got, cmpopts.IgnoreFields(wr.Event{}, "Time")); diff != "" {
+				t.Fatalf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zchee/git-worktree-runner/wr"
+)
+
+// errPickerCanceled is returned when the picker is closed without a selection.
+var errPickerCanceled = fmt.Errorf("selection %w", wr.ErrDeclined)
+
+// pickerHeight is the maximum number of entries shown at once.
+const pickerHeight = 10
+
+// picker is a minimal fuzzy finder over worktree list entries.
+//
+// It is driven by raw key input and renders below the cursor using ANSI escape sequences.
+type picker struct {
+	entries  []wr.ListEntry
+	multi    bool
+	width    int
+	query    []rune
+	matches  []int // indexes into entries, best match first
+	cursor   int   // index into matches
+	offset   int   // first visible index into matches
+	selected map[int]bool
+}
+
+func newPicker(entries []wr.ListEntry, multi bool, width int) *picker {
+	p := &picker{
+		entries:  entries,
+		multi:    multi,
+		width:    width,
+		selected: map[int]bool{},
+	}
+	p.filter()
+	return p
+}
+
+// pickerText returns the text the query is matched against.
+func pickerText(e wr.ListEntry) string {
+	return formatID(e.ID) + " " + e.Target.Branch + " " + e.Target.Path
+}
+
+// filter recomputes the matches for the current query and resets the cursor.
+func (p *picker) filter() {
+	type scored struct {
+		index int
+		score int
+	}
+	var hits []scored
+	for i, e := range p.entries {
+		if score, ok := fuzzyScore(string(p.query), pickerText(e)); ok {
+			hits = append(hits, scored{index: i, score: score})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b scored) int { return b.score - a.score })
+
+	p.matches = p.matches[:0]
+	for _, h := range hits {
+		p.matches = append(p.matches, h.index)
+	}
+	p.cursor, p.offset = 0, 0
+}
+
+// fuzzyScore reports whether every rune of pattern appears in text in order, ignoring case.
+//
+// Higher scores are better: consecutive matches and matches at the start of a word score more,
+// and every skipped rune between two matches costs a point. Each occurrence of the first pattern
+// rune is tried as a starting point and the best score wins.
+func fuzzyScore(pattern, text string) (int, bool) {
+	pat := []rune(strings.ToLower(pattern))
+	if len(pat) == 0 {
+		return 0, true
+	}
+	runes := []rune(strings.ToLower(text))
+
+	best, found := 0, false
+	for start, r := range runes {
+		if r != pat[0] {
+			continue
+		}
+		score, ok := fuzzyScoreFrom(pat, runes, start)
+		if ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+func fuzzyScoreFrom(pat, text []rune, start int) (int, bool) {
+	score, pi, prev := 0, 0, -1
+	for ti := start; ti < len(text) && pi < len(pat); ti++ {
+		if text[ti] != pat[pi] {
+			if prev >= 0 {
+				score--
+			}
+			continue
+		}
+		switch {
+		case prev >= 0 && ti == prev+1:
+			score += 8
+		case ti == 0 || !unicode.IsLetter(text[ti-1]) && !unicode.IsDigit(text[ti-1]):
+			score += 6
+		default:
+			score++
+		}
+		prev = ti
+		pi++
+	}
+	return score, pi == len(pat)
+}
+
+// move moves the cursor by delta, keeping it within the matches and the visible window.
+func (p *picker) move(delta int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.cursor = min(max(p.cursor+delta, 0), len(p.matches)-1)
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+pickerHeight {
+		p.offset = p.cursor - pickerHeight + 1
+	}
+}
+
+// handle applies the keys in b. It returns done when the selection is accepted.
+func (p *picker) handle(b []byte) (done bool, err error) {
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b: // escape sequence or a lone Esc
+			if len(b) >= 3 && b[1] == '[' {
+				switch b[2] {
+				case 'A':
+					p.move(-1)
+				case 'B':
+					p.move(1)
+				}
+				b = b[3:]
+				continue
+			}
+			return false, errPickerCanceled
+		case c == 0x03 || c == 0x04: // Ctrl-C, Ctrl-D
+			return false, errPickerCanceled
+		case c == '\r' || c == '\n':
+			return len(p.result()) > 0, nil
+		case c == '\t':
+			if p.multi && len(p.matches) > 0 {
+				i := p.matches[p.cursor]
+				p.selected[i] = !p.selected[i]
+				p.move(1)
+			}
+		case c == 0x10 || c == 0x0b: // Ctrl-P, Ctrl-K
+			p.move(-1)
+		case c == 0x0e: // Ctrl-N
+			p.move(1)
+		case c == 0x7f || c == 0x08: // Backspace
+			if len(p.query) > 0 {
+				p.query = p.query[:len(p.query)-1]
+				p.filter()
+			}
+		case c == 0x15: // Ctrl-U
+			p.query = p.query[:0]
+			p.filter()
+		case c >= 0x20:
+			r, size := utf8.DecodeRune(b)
+			if r != utf8.RuneError {
+				p.query = append(p.query, r)
+				p.filter()
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return false, nil
+}
+
+// result returns the chosen entry indexes: the marked entries in multi-select mode when any are marked,
+// otherwise the entry under the cursor.
+func (p *picker) result() []int {
+	var out []int
+	for i := range p.entries {
+		if p.selected[i] {
+			out = append(out, i)
+		}
+	}
+	if len(out) == 0 && len(p.matches) > 0 {
+		out = append(out, p.matches[p.cursor])
+	}
+	return out
+}
+
+// render draws the prompt and the visible matches, leaving the terminal cursor after the query.
+func (p *picker) render(w io.Writer) {
+	var b strings.Builder
+	b.WriteString("\r\033[J")
+
+	hint := "enter: select, esc: cancel"
+	if p.multi {
+		hint = "tab: mark, enter: select, esc: cancel"
+	}
+	prompt := fmt.Sprintf("> %s", string(p.query))
+	fmt.Fprintf(&b, "%s  %d/%d (%s)", prompt, len(p.matches), len(p.entries), hint)
+
+	end := min(p.offset+pickerHeight, len(p.matches))
+	for i := p.offset; i < end; i++ {
+		e := p.entries[p.matches[i]]
+		cursor, mark := " ", " "
+		if i == p.cursor {
+			cursor = ">"
+		}
+		if p.selected[p.matches[i]] {
+			mark = "*"
+		}
+		branch := e.Target.Branch
+		if e.Target.IsMain {
+			branch += " [main repo]"
+		}
+		line := fmt.Sprintf("%s%s %-4s %-30s %-9s %s", cursor, mark, formatID(e.ID), branch, e.Status, e.Target.Path)
+		b.WriteString("\r\n")
+		b.WriteString(truncateRunes(line, p.width-1))
+	}
+
+	if n := end - p.offset; n > 0 {
+		fmt.Fprintf(&b, "\033[%dA", n)
+	}
+	fmt.Fprintf(&b, "\r\033[%dC", utf8.RuneCountInString(prompt))
+	_, _ = io.WriteString(w, b.String())
+}
+
+func truncateRunes(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// run reads keys from in and redraws on out until the selection is accepted or canceled.
+func (p *picker) run(in io.Reader, out io.Writer) ([]int, error) {
+	defer func() { _, _ = io.WriteString(out, "\r\033[J") }()
+
+	buf := make([]byte, 64)
+	for {
+		p.render(out)
+		n, err := in.Read(buf)
+		if n > 0 {
+			done, herr := p.handle(buf[:n])
+			if herr != nil {
+				return nil, herr
+			}
+			if done {
+				return p.result(), nil
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errPickerCanceled
+			}
+			return nil, err
+		}
+	}
+}
+
+// canPick reports whether worktrees can be chosen interactively: stdin and stderr must both be terminals.
+func (r Runner) canPick() bool {
+	in, ok := r.Stdin.(*os.File)
+	return ok && isTerminal(in) && isTerminal(r.Stderr)
+}
+
+// pickWorktrees lets the user choose worktrees from Manager.List. Callers check canPick first.
+//
+// The returned identifiers are worktree paths, which ResolveTarget accepts. At least one is returned
+// unless err is non-nil.
+func (r Runner) pickWorktrees(ctx context.Context, m *wr.Manager, multi bool) ([]string, error) {
+	entries, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	in := r.Stdin.(*os.File)
+	var chosen []int
+	restore, err := makeRaw(in)
+	switch {
+	case err == nil:
+		chosen, err = newPicker(entries, multi, terminalWidth(in)).run(in, r.Stderr)
+		restore()
+	case errors.Is(err, errRawUnsupported):
+		chosen, err = r.promptPick(entries, multi)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	idents := make([]string, 0, len(chosen))
+	for _, i := range chosen {
+		idents = append(idents, entries[i].Target.Path)
+	}
+	return idents, nil
+}
+
+// promptPick is the line-based fallback used when the terminal cannot be switched to raw mode.
+func (r Runner) promptPick(entries []wr.ListEntry, multi bool) ([]int, error) {
+	for i, e := range entries {
+		fmt.Fprintf(r.Stderr, "%3d) %-30s %-9s %s\n", i+1, e.Target.Branch, e.Status, e.Target.Path)
+	}
+	prompt := fmt.Sprintf("Select a worktree [1-%d]:", len(entries))
+	if multi {
+		prompt = fmt.Sprintf("Select worktrees [1-%d, separated by spaces]:", len(entries))
+	}
+	line, err := r.promptLine(prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	if len(fields) == 0 {
+		return nil, errPickerCanceled
+	}
+	if !multi && len(fields) > 1 {
+		return nil, fmt.Errorf("expected one selection, got %d", len(fields))
+	}
+	var out []int
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 1 || n > len(entries) {
+			return nil, fmt.Errorf("invalid selection %q", f)
+		}
+		out = append(out, n-1)
+	}
+	return out, nil
+}
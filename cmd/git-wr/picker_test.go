@@ -0,0 +1,158 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/wr"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pattern string
+		text    string
+		wantOK  bool
+	}{
+		"success: empty pattern matches": {pattern: "", text: "main", wantOK: true},
+		"success: subsequence":           {pattern: "fa", text: "feature-auth", wantOK: true},
+		"success: ignores case":          {pattern: "FEAT", text: "feature", wantOK: true},
+		"error: out of order":            {pattern: "af", text: "feature-auth", wantOK: false},
+		"error: missing rune":            {pattern: "fx", text: "feature", wantOK: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, ok := fuzzyScore(tc.pattern, tc.text); ok != tc.wantOK {
+				t.Fatalf("fuzzyScore(%q, %q) ok = %v, want %v", tc.pattern, tc.text, ok, tc.wantOK)
+			}
+		})
+	}
+
+	contiguous, _ := fuzzyScore("auth", "feature-auth")
+	scattered, _ := fuzzyScore("auth", "a-u-t-h-x")
+	if contiguous <= scattered {
+		t.Fatalf("contiguous score %d should beat scattered score %d", contiguous, scattered)
+	}
+}
+
+func TestPickerRun(t *testing.T) {
+	t.Parallel()
+
+	entries := []wr.ListEntry{
+		{ID: 1, Target: wr.Target{Branch: "main", Path: "/repo", IsMain: true}, Status: wr.WorktreeStatusOK},
+		{ID: 2, Target: wr.Target{Branch: "feature-auth", Path: "/wt/feature-auth"}, Status: wr.WorktreeStatusOK},
+		{ID: 3, Target: wr.Target{Branch: "fix-login", Path: "/wt/fix-login"}, Status: wr.WorktreeStatusLocked},
+	}
+
+	tests := map[string]struct {
+		multi   bool
+		input   string
+		want    []int
+		wantErr error
+	}{
+		"success: enter picks first entry": {
+			input: "\r",
+			want:  []int{0},
+		},
+		"success: query filters entries": {
+			input: "login\r",
+			want:  []int{2},
+		},
+		"success: arrow keys move the cursor": {
+			input: "\033[B\033[B\033[A\r",
+			want:  []int{1},
+		},
+		"success: backspace widens the query": {
+			input: "fix\x7f\x7f\x7feat\r",
+			want:  []int{1},
+		},
+		"success: tab marks several entries": {
+			multi: true,
+			input: "\t\x0e\t\r",
+			want:  []int{0, 2},
+		},
+		"success: tab is ignored without multi-select": {
+			input: "\t\r",
+			want:  []int{0},
+		},
+		"error: enter without matches keeps waiting": {
+			input:   "zzz\r",
+			wantErr: errPickerCanceled,
+		},
+		"error: escape cancels": {
+			input:   "\033",
+			wantErr: errPickerCanceled,
+		},
+		"error: ctrl-c cancels": {
+			input:   "fe\x03",
+			wantErr: errPickerCanceled,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			got, err := newPicker(entries, tc.multi, 80).run(strings.NewReader(tc.input), &out)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("run() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("selection mismatch (-want +got):\n%s", diff)
+			}
+			if !strings.HasSuffix(out.String(), "\r\033[J") {
+				t.Fatalf("picker did not clear its output: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestRunnerRunPickerRequiresTerminal(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{{"go"}, {"rm"}, {"editor"}, {"ai"}, {"copy"}, {"run", "--", "make"}} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			r := Runner{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr}
+			if got := r.Run(t.Context(), args); got != exitUsage {
+				t.Fatalf("Run(%q) = %d, want %d (stderr=%q)", args, got, exitUsage, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), "Usage:") {
+				t.Fatalf("expected usage error, got %q", stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/wr"
+)
+
+const (
+	progressBarWidth = 24
+	progressInterval = 100 * time.Millisecond
+)
+
+// isTerminal reports whether w is a character device such as an interactive terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// progressBar renders copy progress on a single, continuously redrawn terminal line.
+type progressBar struct {
+	w     io.Writer
+	label string
+	last  time.Time
+	drawn bool // a partial line is on screen
+}
+
+func newProgressBar(w io.Writer, label string) *progressBar {
+	return &progressBar{w: w, label: label}
+}
+
+// Update redraws the bar at most every progressInterval and ends the line once every file is copied.
+func (b *progressBar) Update(p wr.CopyProgress) {
+	done := p.FilesDone >= p.FilesTotal
+	if !done && time.Since(b.last) < progressInterval {
+		return
+	}
+	b.last = time.Now()
+
+	ratio := 1.0
+	switch {
+	case p.BytesTotal > 0:
+		ratio = float64(p.BytesDone) / float64(p.BytesTotal)
+	case p.FilesTotal > 0:
+		ratio = float64(p.FilesDone) / float64(p.FilesTotal)
+	}
+	filled := int(ratio * progressBarWidth)
+
+	fmt.Fprintf(b.w, "\r\033[K%s [%s%s] %3d%% %d/%d files, %s/%s",
+		b.label,
+		strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled),
+		int(ratio*100),
+		p.FilesDone, p.FilesTotal,
+		formatBytes(p.BytesDone), formatBytes(p.BytesTotal),
+	)
+	b.drawn = true
+	if done {
+		b.Done()
+	}
+}
+
+// Done ends a partially drawn bar, for example when the copy failed.
+func (b *progressBar) Done() {
+	if b.drawn {
+		fmt.Fprintln(b.w)
+		b.drawn = false
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// shellInitScripts holds the wrapper function emitted by `git wr init <shell>`.
+//
+// The wrapper relies on the stdout conventions of the CLI: `go` and `new --cd` print only the target
+// path to stdout, everything else goes to stderr, and failures exit non-zero with nothing to cd into.
+var shellInitScripts = map[string]string{
+	"bash": posixShellInit("bash", "~/.bashrc", `eval "$(git wr init bash)"`),
+	"zsh":  posixShellInit("zsh", "~/.zshrc", `eval "$(git wr init zsh)"`),
+	"fish": fishShellInit,
+}
+
+func posixShellInit(shell, rcFile, line string) string {
+	return fmt.Sprintf(`# git wr shell integration for %s. Add this to %s:
+#
+#   %s
+#
+# "wr go" and "wr new --cd" change into the worktree, and "wr rm" of the
+# current worktree moves back to the main repository.
+wr() {
+  case "$1" in
+    go)
+      local __wr_dir
+      __wr_dir="$(command git wr "$@")" || return
+      [ -n "$__wr_dir" ] && builtin cd -- "$__wr_dir"
+      ;;
+    new)
+      local __wr_arg __wr_dir __wr_cd=
+      for __wr_arg in "$@"; do
+        case "$__wr_arg" in
+          --cd|--cd=1|--cd=t|--cd=T|--cd=true|--cd=TRUE|--cd=True) __wr_cd=1 ;;
+          --cd=*) __wr_cd= ;;
+        esac
+      done
+      if [ -z "$__wr_cd" ]; then
+        command git wr "$@"
+        return
+      fi
+      __wr_dir="$(command git wr "$@")" || return
+      [ -n "$__wr_dir" ] && builtin cd -- "$__wr_dir"
+      ;;
+    rm)
+      local __wr_main __wr_status
+      __wr_main="$(command git wr go 1 2>/dev/null)"
+      command git wr "$@"
+      __wr_status=$?
+      if [ ! -d "$PWD" ] && [ -n "$__wr_main" ]; then
+        builtin cd -- "$__wr_main"
+      fi
+      return $__wr_status
+      ;;
+    *)
+      command git wr "$@"
+      ;;
+  esac
+}
+`, shell, rcFile, line)
+}
+
+const fishShellInit = `# git wr shell integration for fish. Add this to ~/.config/fish/config.fish:
+#
+#   git wr init fish | source
+#
+# "wr go" and "wr new --cd" change into the worktree, and "wr rm" of the
+# current worktree moves back to the main repository.
+function wr --description 'git wr with directory changes'
+    switch "$argv[1]"
+        case go
+            set -l dir (command git wr $argv); or return
+            test -n "$dir"; and cd $dir
+        case new
+            set -l wr_cd
+            for arg in $argv
+                switch $arg
+                    case --cd --cd=1 --cd=t --cd=T --cd=true --cd=TRUE --cd=True
+                        set wr_cd 1
+                    case '--cd=*'
+                        set wr_cd
+                end
+            end
+            if test -z "$wr_cd"
+                command git wr $argv
+                return
+            end
+            set -l dir (command git wr $argv); or return
+            test -n "$dir"; and cd $dir
+        case rm
+            set -l main (command git wr go 1 2>/dev/null)
+            command git wr $argv
+            set -l wr_status $status
+            if not test -d "$PWD"; and test -n "$main"
+                cd $main
+            end
+            return $wr_status
+        case '*'
+            command git wr $argv
+    end
+end
+`
+
+func initSpec() commandSpec {
+	return commandSpec{
+		usage:   []string{"init <bash|zsh|fish>"},
+		summary: "Print shell integration that defines a `wr` function which changes directory.",
+		args:    []completer{shells, completeNone},
+	}
+}
+
+func (r Runner) runInit(ctx context.Context, args []string) int {
+	_ = ctx
+
+	spec := initSpec()
+	p, code, ok := r.parseArgs(spec, args)
+	if !ok {
+		return code
+	}
+	args = p.all()
+	if len(args) != 1 {
+		return r.usageError(spec)
+	}
+	script, ok := shellInitScripts[args[0]]
+	if !ok {
+		return r.usagef("Unsupported shell: %s (want bash, zsh or fish)", args[0])
+	}
+
+	fmt.Fprint(r.Stdout, script)
+	return exitSuccess
+}
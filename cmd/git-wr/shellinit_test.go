@@ -0,0 +1,159 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeGit stands in for `git wr`: go and new --cd print $WR_TARGET (or $WR_MAIN for `go 1`), new without --cd
+// prints a message, rm deletes $WR_TARGET, and go fails for the identifier "missing".
+const fakeGit = `#!/bin/sh
+shift
+case "$1" in
+go)
+  case "$2" in
+  1) echo "$WR_MAIN" ;;
+  missing) echo "[x] worktree target not found: missing" >&2; exit 1 ;;
+  *) echo "Branch: x" >&2; echo "$WR_TARGET" ;;
+  esac ;;
+new)
+  mkdir -p "$WR_TARGET"
+  cd=
+  for a in "$@"; do
+    case "$a" in --cd|--cd=true) cd=1 ;; --cd=false) cd= ;; esac
+  done
+  if [ -n "$cd" ]; then echo "$WR_TARGET"; else echo "Created x"; fi ;;
+rm) rm -rf "$WR_TARGET" ;;
+esac
+`
+
+func TestShellInit(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		script string
+		// fishScript replaces script for fish, which has no $?.
+		fishScript string
+		want       string
+	}{
+		"success: go changes directory": {
+			script: "wr go x; pwd",
+			want:   "target",
+		},
+		"success: failed go stays put": {
+			script:     "wr go missing; echo $?; pwd",
+			fishScript: "wr go missing; echo $status; pwd",
+			want:       "1\nmain",
+		},
+		"success: new --cd changes directory": {
+			script: "wr new x --cd; pwd",
+			want:   "target",
+		},
+		"success: new --cd=true changes directory": {
+			script: "wr new x --cd=true; pwd",
+			want:   "target",
+		},
+		"success: new without --cd stays put": {
+			script: "wr new x; pwd",
+			want:   "Created x\nmain",
+		},
+		"success: new --cd=false stays put": {
+			script: "wr new x --cd --cd=false; pwd",
+			want:   "Created x\nmain",
+		},
+		"success: rm of the current worktree returns to main": {
+			script:     "wr go x; wr rm x; echo $?; pwd",
+			fishScript: "wr go x; wr rm x; echo $status; pwd",
+			want:       "0\nmain",
+		},
+	}
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		shellPath, err := exec.LookPath(shell)
+		if err != nil {
+			continue
+		}
+
+		var init bytes.Buffer
+		if code := (Runner{Stdout: &init, Stderr: &init}).runInit(t.Context(), []string{shell}); code != exitSuccess {
+			t.Fatalf("runInit(%s) = %d: %s", shell, code, init.String())
+		}
+
+		for name, tc := range tests {
+			t.Run(shell+"/"+name, func(t *testing.T) {
+				t.Parallel()
+
+				dir := t.TempDir()
+				binDir := filepath.Join(dir, "bin")
+				mainDir := filepath.Join(dir, "main")
+				targetDir := filepath.Join(dir, "target")
+				for _, d := range []string{binDir, mainDir, targetDir} {
+					if err := os.MkdirAll(d, 0o755); err != nil {
+						t.Fatalf("MkdirAll(%q): %v", d, err)
+					}
+				}
+				if err := os.WriteFile(filepath.Join(binDir, "git"), []byte(fakeGit), 0o755); err != nil {
+					t.Fatalf("WriteFile(git): %v", err)
+				}
+
+				script := tc.script
+				if shell == "fish" && tc.fishScript != "" {
+					script = tc.fishScript
+				}
+				cmd := exec.CommandContext(t.Context(), shellPath, "-c", init.String()+script)
+				cmd.Dir = mainDir
+				cmd.Env = append(os.Environ(),
+					"PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+					"WR_MAIN="+mainDir,
+					"WR_TARGET="+targetDir,
+				)
+				out, err := cmd.Output()
+				if err != nil {
+					t.Fatalf("%s -c %q: %v", shell, script, err)
+				}
+
+				got := strings.ReplaceAll(strings.TrimSpace(string(out)), dir+string(filepath.Separator), "")
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Fatalf("output mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestRunInitUsage(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{nil, {"tcsh"}, {"bash", "zsh"}} {
+		var stdout, stderr bytes.Buffer
+		r := Runner{Stdout: &stdout, Stderr: &stderr}
+		if got := r.runInit(t.Context(), args); got != exitUsage {
+			t.Fatalf("runInit(%q) = %d, want %d", args, got, exitUsage)
+		}
+		if stdout.Len() != 0 {
+			t.Fatalf("runInit(%q) wrote to stdout: %q", args, stdout.String())
+		}
+	}
+}
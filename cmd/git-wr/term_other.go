@@ -0,0 +1,35 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux && !darwin
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// errRawUnsupported is returned by makeRaw on platforms without termios support.
+var errRawUnsupported = errors.New("raw terminal mode is not supported")
+
+func makeRaw(*os.File) (restore func(), err error) {
+	return nil, errRawUnsupported
+}
+
+func terminalWidth(*os.File) int {
+	return 80
+}
@@ -52,6 +52,8 @@ type Spec struct {
 	Args    []string
 	Dir     string
 	Mode    Mode
+	// Env is a list of KEY=VALUE pairs appended to the current process environment.
+	Env []string
 }
 
 // Info describes an adapter's availability.
@@ -162,6 +164,7 @@ func Exec(ctx context.Context, spec Spec, stdin io.Reader, stdout, stderr io.Wri
 	if spec.Mode == ModeStart {
 		cmd := exec.CommandContext(ctx, spec.Command, spec.Args...) //nolint:gosec
 		cmd.Dir = spec.Dir
+		cmd.Env = environ(spec.Env)
 		cmd.Stdin = stdin
 		cmd.Stdout = stdout
 		cmd.Stderr = stderr
@@ -177,6 +180,7 @@ func Exec(ctx context.Context, spec Spec, stdin io.Reader, stdout, stderr io.Wri
 	if spec.Name == "cursor" && filepath.Base(spec.Command) == "cursor" && len(spec.Args) > 0 && spec.Args[0] == "cli" {
 		cmd := exec.CommandContext(ctx, spec.Command, spec.Args...) //nolint:gosec
 		cmd.Dir = spec.Dir
+		cmd.Env = environ(spec.Env)
 		cmd.Stdin = stdin
 		cmd.Stdout = stdout
 		cmd.Stderr = io.Discard
@@ -192,6 +196,7 @@ func Exec(ctx context.Context, spec Spec, stdin io.Reader, stdout, stderr io.Wri
 
 		cmdFallback := exec.CommandContext(ctx, spec.Command, spec.Args[1:]...) //nolint:gosec
 		cmdFallback.Dir = spec.Dir
+		cmdFallback.Env = environ(spec.Env)
 		cmdFallback.Stdin = stdin
 		cmdFallback.Stdout = stdout
 		cmdFallback.Stderr = stderr
@@ -209,6 +214,7 @@ func Exec(ctx context.Context, spec Spec, stdin io.Reader, stdout, stderr io.Wri
 
 	cmd := exec.CommandContext(ctx, spec.Command, spec.Args...) //nolint:gosec
 	cmd.Dir = spec.Dir
+	cmd.Env = environ(spec.Env)
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
@@ -224,6 +230,14 @@ func Exec(ctx context.Context, spec Spec, stdin io.Reader, stdout, stderr io.Wri
 	}
 }
 
+// environ returns the environment for an adapter command, or nil to inherit the current one when extra is empty.
+func environ(extra []string) []string {
+	if len(extra) == 0 {
+		return nil
+	}
+	return append(os.Environ(), extra...)
+}
+
 // ListBuiltins returns the built-in adapter names for kind.
 func ListBuiltins(kind Kind) []string {
 	switch kind {
@@ -21,7 +21,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/zchee/git-worktree-runner/internal/gitcmd"
@@ -53,7 +55,8 @@ func (r Resolver) worktreeIncludePath() string {
 	return filepath.Join(r.MainRoot, ".worktreeinclude")
 }
 
-func (r Resolver) lookupEnv(key string) (string, bool) {
+// LookupEnv looks up an environment variable, honoring Env when it is set.
+func (r Resolver) LookupEnv(key string) (string, bool) {
 	if r.Env != nil {
 		v, ok := r.Env[key]
 		return v, ok
@@ -164,7 +167,7 @@ func (r Resolver) Default(ctx context.Context, key, envName, fallback, fileKey s
 	}
 
 	if envName != "" {
-		if ev, ok := r.lookupEnv(envName); ok && ev != "" {
+		if ev, ok := r.LookupEnv(envName); ok && ev != "" {
 			return ev, nil
 		}
 	}
@@ -224,7 +227,60 @@ func (r Resolver) All(ctx context.Context, key, fileKey string) ([]string, error
 
 // WorktreeIncludePatterns reads .worktreeinclude from the repository root and returns non-empty, non-comment lines.
 func (r Resolver) WorktreeIncludePatterns() ([]string, error) {
-	b, err := os.ReadFile(r.worktreeIncludePath())
+	return readPatternFile(r.worktreeIncludePath())
+}
+
+// WorktreeIncludeFile holds the patterns of one .worktreeinclude file.
+type WorktreeIncludeFile struct {
+	// Dir is the directory of the file relative to the repository root, with `/` separators ("" for the root).
+	Dir      string
+	Patterns []string
+}
+
+// WorktreeIncludeFiles reads .worktreeinclude from the repository root and from every subdirectory,
+// parents before children. Nested files are found with `git ls-files`, so files in ignored directories
+// are not considered; the root file is always read.
+func (r Resolver) WorktreeIncludeFiles(ctx context.Context) ([]WorktreeIncludeFile, error) {
+	var out []WorktreeIncludeFile
+
+	rootPatterns, err := r.WorktreeIncludePatterns()
+	if err != nil {
+		return nil, err
+	}
+	if len(rootPatterns) > 0 {
+		out = append(out, WorktreeIncludeFile{Patterns: rootPatterns})
+	}
+
+	res, err := r.Git.Run(ctx, r.MainRoot, "ls-files", "-z", "--cached", "--others", "--exclude-standard", "--", ":(glob)**/.worktreeinclude")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", err)
+	}
+
+	var nested []string
+	for rel := range strings.SplitSeq(res.Stdout, "\x00") {
+		if rel == "" || rel == ".worktreeinclude" || slices.Contains(nested, rel) {
+			continue
+		}
+		nested = append(nested, rel)
+	}
+	slices.Sort(nested)
+
+	for _, rel := range nested {
+		patterns, err := readPatternFile(filepath.Join(r.MainRoot, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		if len(patterns) > 0 {
+			out = append(out, WorktreeIncludeFile{Dir: path.Dir(rel), Patterns: patterns})
+		}
+	}
+
+	return out, nil
+}
+
+// readPatternFile returns the non-empty, non-comment lines of the file at p, or nil when it does not exist.
+func readPatternFile(p string) ([]string, error) {
+	b, err := os.ReadFile(p)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, nil
@@ -220,3 +220,46 @@ func TestResolverWorktreeIncludePatterns(t *testing.T) {
 		})
 	}
 }
+
+func TestResolverWorktreeIncludeFiles(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	files := map[string]string{
+		".gitignore":                     "ignored/\n",
+		".worktreeinclude":               ".env\n",
+		"apps/web/.worktreeinclude":      "# web\n/.env.local\n",
+		"apps/.worktreeinclude":          "!secret.env\n",
+		"ignored/.worktreeinclude":       "*\n",
+		"apps/empty/.worktreeinclude":    "\n",
+		"apps/web/node/.worktreeinclude": "cache/\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(repoDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+
+	r := New(g, repoDir, nil)
+	got, err := r.WorktreeIncludeFiles(t.Context())
+	if err != nil {
+		t.Fatalf("WorktreeIncludeFiles() error: %v", err)
+	}
+
+	want := []WorktreeIncludeFile{
+		{Patterns: []string{".env"}},
+		{Dir: "apps", Patterns: []string{"!secret.env"}},
+		{Dir: "apps/web", Patterns: []string{"/.env.local"}},
+		{Dir: "apps/web/node", Patterns: []string{"cache/"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("files mismatch (-want +got):\n%s", diff)
+	}
+}
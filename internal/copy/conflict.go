@@ -0,0 +1,173 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidConflictPolicy is returned when a conflict policy is unknown.
+var ErrInvalidConflictPolicy = errors.New("invalid conflict policy")
+
+// BackupSuffix is appended to destination files saved by ConflictBackup.
+// Files with this suffix are never copied, deleted or overwritten.
+const BackupSuffix = ".wr-bak"
+
+// ConflictPolicy decides what happens when a destination file already exists and differs from the source.
+type ConflictPolicy string
+
+const (
+	// ConflictOverwrite replaces the destination file (default).
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictSkipExisting leaves the destination file alone.
+	ConflictSkipExisting ConflictPolicy = "skip-existing"
+	// ConflictNewer replaces the destination file only when the source was modified more recently.
+	ConflictNewer ConflictPolicy = "newer"
+	// ConflictBackup renames the destination file to <name>.wr-bak before replacing it, or to
+	// <name>.<n>.wr-bak when earlier backups exist.
+	ConflictBackup ConflictPolicy = "backup"
+	// ConflictPrompt asks Options.ConfirmOverwrite for every conflicting file.
+	ConflictPrompt ConflictPolicy = "prompt"
+)
+
+// ParseConflictPolicy parses a conflict policy name. The empty string selects ConflictOverwrite.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(strings.TrimSpace(s)); p {
+	case "":
+		return ConflictOverwrite, nil
+	case ConflictOverwrite, ConflictSkipExisting, ConflictNewer, ConflictBackup, ConflictPrompt:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want overwrite, skip-existing, newer, backup or prompt)", ErrInvalidConflictPolicy, s)
+	}
+}
+
+// ConflictAction is what was done with a conflicting destination file.
+type ConflictAction string
+
+const (
+	ConflictActionOverwrite ConflictAction = "overwrite"
+	ConflictActionSkip      ConflictAction = "skip"
+	ConflictActionBackup    ConflictAction = "backup"
+	// ConflictActionDelete means a Stale destination file was removed.
+	ConflictActionDelete ConflictAction = "delete"
+)
+
+// Conflict describes a destination file that already existed and differed from the source, or with Stale,
+// that Options.Delete would remove.
+type Conflict struct {
+	Path   string // relative path from srcRoot
+	Action ConflictAction
+	// Backup is the relative path from dstRoot of the saved destination file when Action is ConflictActionBackup.
+	Backup string
+	// Stale reports that the file no longer exists in the source.
+	Stale bool
+}
+
+// resolveConflict applies opts.Conflict to the existing destination of j.
+func (j job) resolveConflict(ctx context.Context, opts Options) (ConflictAction, error) {
+	switch opts.Conflict {
+	case "", ConflictOverwrite:
+		return ConflictActionOverwrite, nil
+	case ConflictSkipExisting:
+		return ConflictActionSkip, nil
+	case ConflictBackup:
+		return ConflictActionBackup, nil
+	case ConflictNewer:
+		newer, err := j.srcNewer()
+		if err != nil || !newer {
+			return ConflictActionSkip, err
+		}
+		return ConflictActionOverwrite, nil
+	case ConflictPrompt:
+		if opts.ConfirmOverwrite == nil {
+			return "", errors.New("conflict policy prompt requires a confirmation callback")
+		}
+		ok, err := opts.ConfirmOverwrite(ctx, j.rel)
+		if err != nil || !ok {
+			return ConflictActionSkip, err
+		}
+		return ConflictActionOverwrite, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidConflictPolicy, opts.Conflict)
+	}
+}
+
+// resolveDelete applies opts.Conflict to the stale destination file rel. ConflictOverwrite deletes it and
+// ConflictNewer keeps it, since there is no source to compare with.
+func resolveDelete(ctx context.Context, rel string, opts Options) (ConflictAction, error) {
+	switch opts.Conflict {
+	case "", ConflictOverwrite:
+		return ConflictActionDelete, nil
+	case ConflictSkipExisting, ConflictNewer:
+		return ConflictActionSkip, nil
+	case ConflictBackup:
+		return ConflictActionBackup, nil
+	case ConflictPrompt:
+		if opts.ConfirmDelete == nil {
+			return "", errors.New("conflict policy prompt requires a confirmation callback")
+		}
+		ok, err := opts.ConfirmDelete(ctx, rel)
+		if err != nil || !ok {
+			return ConflictActionSkip, err
+		}
+		return ConflictActionDelete, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidConflictPolicy, opts.Conflict)
+	}
+}
+
+// srcNewer reports whether the source of j was modified after its destination.
+func (j job) srcNewer() (bool, error) {
+	srcInfo, err := os.Lstat(j.src)
+	if err != nil {
+		return false, err
+	}
+	dstInfo, err := os.Lstat(j.dst)
+	if err != nil {
+		return false, err
+	}
+	return srcInfo.ModTime().After(dstInfo.ModTime()), nil
+}
+
+// backupPath returns where to save dst under ConflictBackup: the first of <dst>.wr-bak, <dst>.1.wr-bak, ...
+// that neither exists nor is already in taken. The returned path is added to taken.
+func backupPath(dst string, taken map[string]bool) (string, error) {
+	for n := 0; ; n++ {
+		p := dst + BackupSuffix
+		if n > 0 {
+			p = dst + "." + strconv.Itoa(n) + BackupSuffix
+		}
+		if taken[p] {
+			continue
+		}
+		_, err := os.Lstat(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			taken[p] = true
+			return p, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
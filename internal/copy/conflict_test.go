@@ -0,0 +1,165 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyFilesConflictPolicy(t *testing.T) {
+	t.Parallel()
+
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := old.Add(time.Hour)
+
+	tests := map[string]struct {
+		policy        ConflictPolicy
+		confirm       func(ctx context.Context, rel string) (bool, error)
+		wantConflicts []Conflict
+		wantContents  map[string]string
+	}{
+		"success: overwrite": {
+			policy: ConflictOverwrite,
+			wantConflicts: []Conflict{
+				{Path: "local.env", Action: ConflictActionOverwrite},
+				{Path: "stale.env", Action: ConflictActionOverwrite},
+			},
+			wantContents: map[string]string{"local.env": "src\n", "stale.env": "src\n", "new.env": "src\n", "same.env": "src\n", "local.env.wr-bak": "earlier edit\n"},
+		},
+		"success: skip-existing": {
+			policy: ConflictSkipExisting,
+			wantConflicts: []Conflict{
+				{Path: "local.env", Action: ConflictActionSkip},
+				{Path: "stale.env", Action: ConflictActionSkip},
+			},
+			wantContents: map[string]string{"local.env": "local edit\n", "stale.env": "stale\n", "new.env": "src\n", "same.env": "src\n", "local.env.wr-bak": "earlier edit\n"},
+		},
+		"success: newer": {
+			policy: ConflictNewer,
+			wantConflicts: []Conflict{
+				{Path: "local.env", Action: ConflictActionSkip},
+				{Path: "stale.env", Action: ConflictActionOverwrite},
+			},
+			wantContents: map[string]string{"local.env": "local edit\n", "stale.env": "src\n", "new.env": "src\n", "same.env": "src\n", "local.env.wr-bak": "earlier edit\n"},
+		},
+		"success: backup": {
+			policy: ConflictBackup,
+			wantConflicts: []Conflict{
+				{Path: "local.env", Action: ConflictActionBackup, Backup: "local.env.1.wr-bak"},
+				{Path: "stale.env", Action: ConflictActionBackup, Backup: "stale.env.wr-bak"},
+			},
+			wantContents: map[string]string{
+				"local.env":          "src\n",
+				"local.env.wr-bak":   "earlier edit\n",
+				"local.env.1.wr-bak": "local edit\n",
+				"stale.env":          "src\n",
+				"stale.env.wr-bak":   "stale\n",
+				"new.env":            "src\n",
+				"same.env":           "src\n",
+			},
+		},
+		"success: prompt": {
+			policy: ConflictPrompt,
+			confirm: func(ctx context.Context, rel string) (bool, error) {
+				return rel == "stale.env", nil
+			},
+			wantConflicts: []Conflict{
+				{Path: "local.env", Action: ConflictActionSkip},
+				{Path: "stale.env", Action: ConflictActionOverwrite},
+			},
+			wantContents: map[string]string{"local.env": "local edit\n", "stale.env": "src\n", "new.env": "src\n", "same.env": "src\n", "local.env.wr-bak": "earlier edit\n"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			srcRoot := t.TempDir()
+			dstRoot := t.TempDir()
+			for path, mtime := range map[string]time.Time{
+				filepath.Join(srcRoot, "local.env"): old,
+				filepath.Join(srcRoot, "stale.env"): newer,
+				filepath.Join(srcRoot, "new.env"):   old,
+				filepath.Join(srcRoot, "same.env"):  old,
+			} {
+				if err := os.WriteFile(path, []byte("src\n"), 0o644); err != nil {
+					t.Fatalf("WriteFile: %v", err)
+				}
+				if err := os.Chtimes(path, time.Time{}, mtime); err != nil {
+					t.Fatalf("Chtimes: %v", err)
+				}
+			}
+			// same.env matches the source and is no conflict, even though its mtime differs.
+			for name, content := range map[string]string{
+				"local.env":        "local edit\n",
+				"local.env.wr-bak": "earlier edit\n",
+				"stale.env":        "stale\n",
+				"same.env":         "src\n",
+			} {
+				path := filepath.Join(dstRoot, name)
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatalf("WriteFile: %v", err)
+				}
+				mtime := newer
+				if name == "stale.env" {
+					mtime = old
+				}
+				if err := os.Chtimes(path, time.Time{}, mtime); err != nil {
+					t.Fatalf("Chtimes: %v", err)
+				}
+			}
+
+			got, err := CopyFiles(t.Context(), srcRoot, dstRoot, []string{"*.env"}, nil, Options{
+				PreservePaths:    true,
+				Conflict:         tc.policy,
+				ConfirmOverwrite: tc.confirm,
+			})
+			if err != nil {
+				t.Fatalf("CopyFiles() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantConflicts, got.Conflicts); diff != "" {
+				t.Fatalf("conflicts mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]string{"same.env"}, got.Unchanged); diff != "" {
+				t.Fatalf("unchanged mismatch (-want +got):\n%s", diff)
+			}
+
+			contents := map[string]string{}
+			entries, err := os.ReadDir(dstRoot)
+			if err != nil {
+				t.Fatalf("ReadDir: %v", err)
+			}
+			for _, e := range entries {
+				b, err := os.ReadFile(filepath.Join(dstRoot, e.Name()))
+				if err != nil {
+					t.Fatalf("ReadFile: %v", err)
+				}
+				contents[e.Name()] = string(b)
+			}
+			if diff := cmp.Diff(tc.wantContents, contents); diff != "" {
+				t.Fatalf("destination mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
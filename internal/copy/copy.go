@@ -26,6 +26,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	doublestar "github.com/bmatcuk/doublestar/v4"
 )
@@ -36,101 +37,488 @@ var ErrNoPatterns = errors.New("no patterns specified")
 // ErrUnsafePattern is returned when a pattern is unsafe (absolute or contains .. path traversal).
 var ErrUnsafePattern = errors.New("unsafe pattern")
 
+// ErrInvalidMode is returned when a copy mode is unknown.
+var ErrInvalidMode = errors.New("invalid copy mode")
+
+// ErrInvalidPreserve is returned when a preserved attribute name is unknown.
+var ErrInvalidPreserve = errors.New("invalid preserve attribute")
+
+// ErrSymlinkEscape is returned when a symlink preserved with Preserve.Symlinks points outside the source root.
+var ErrSymlinkEscape = errors.New("symlink escapes source root")
+
+// copyChunkSize is the amount of data copied between cancellation checks.
+const copyChunkSize = 8 << 20
+
+// Mode selects how a file is materialized in the destination.
+type Mode string
+
+const (
+	// ModeCopy writes a full, independent copy of the file (default).
+	ModeCopy Mode = "copy"
+	// ModeReflink clones the file copy-on-write (FICLONE on Linux, clonefile on macOS).
+	// It falls back to ModeCopy when the filesystem does not support cloning.
+	ModeReflink Mode = "reflink"
+	// ModeHardlink hard-links the file. It falls back to ModeCopy when linking fails (for example across devices).
+	ModeHardlink Mode = "hardlink"
+	// ModeSymlink creates a symlink to the absolute source path.
+	ModeSymlink Mode = "symlink"
+)
+
+// ParseMode parses a copy mode name. The empty string selects ModeCopy.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.TrimSpace(s)); m {
+	case "":
+		return ModeCopy, nil
+	case ModeCopy, ModeReflink, ModeHardlink, ModeSymlink:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want copy, reflink, hardlink or symlink)", ErrInvalidMode, s)
+	}
+}
+
+// ModeRule overrides the copy mode for files whose relative path (with `/` separators) matches Pattern
+// (doublestar syntax, for example "node_modules/**").
+type ModeRule struct {
+	Pattern string
+	Mode    Mode
+}
+
+// ParseModeRule parses a "<pattern>=<mode>" override such as "node_modules/**=hardlink".
+func ParseModeRule(s string) (ModeRule, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return ModeRule{}, fmt.Errorf("%w: %q (want <pattern>=<mode>)", ErrInvalidMode, s)
+	}
+	pattern := filepath.ToSlash(strings.TrimSpace(s[:i]))
+	if pattern == "" || !isSafePattern(pattern) {
+		return ModeRule{}, fmt.Errorf("%w: %q", ErrUnsafePattern, s[:i])
+	}
+	mode, err := ParseMode(s[i+1:])
+	if err != nil {
+		return ModeRule{}, err
+	}
+	return ModeRule{Pattern: pattern, Mode: mode}, nil
+}
+
+// Preserve selects which attributes of the source are carried over to copied files.
+type Preserve struct {
+	// Symlinks recreates symbolic links as links instead of copying the files they point to.
+	// Links that resolve outside the source root fail with ErrSymlinkEscape; absolute links inside it
+	// are rewritten as relative links so that they resolve within the destination.
+	Symlinks bool
+	// Mode keeps all permission bits, including setuid, setgid and sticky. Without it the destination
+	// gets the source's rwx bits, subject to the umask.
+	Mode bool
+	// Times keeps the modification time.
+	Times bool
+	// Xattrs copies extended attributes on Linux and macOS. Attributes the destination refuses
+	// (unsupported filesystem or privileged namespace) are skipped.
+	Xattrs bool
+}
+
+// ParsePreserve parses a comma-separated list of "symlinks", "mode", "times" and "xattrs".
+// "all" selects every attribute; "none" and the empty string select none.
+func ParsePreserve(s string) (Preserve, error) {
+	var p Preserve
+	for name := range strings.SplitSeq(s, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "", "none":
+		case "all":
+			p = Preserve{Symlinks: true, Mode: true, Times: true, Xattrs: true}
+		case "symlinks":
+			p.Symlinks = true
+		case "mode":
+			p.Mode = true
+		case "times":
+			p.Times = true
+		case "xattrs":
+			p.Xattrs = true
+		default:
+			return Preserve{}, fmt.Errorf("%w: %q (want symlinks, mode, times, xattrs, all or none)", ErrInvalidPreserve, name)
+		}
+	}
+	return p, nil
+}
+
+// String returns p in the form accepted by ParsePreserve.
+func (p Preserve) String() string {
+	var names []string
+	for _, a := range []struct {
+		on   bool
+		name string
+	}{
+		{p.Symlinks, "symlinks"},
+		{p.Mode, "mode"},
+		{p.Times, "times"},
+		{p.Xattrs, "xattrs"},
+	} {
+		if a.on {
+			names = append(names, a.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
 // Options configures copy behavior.
 type Options struct {
 	PreservePaths bool
 	DryRun        bool
+
+	// Mode is the default copy mode. Empty means ModeCopy.
+	Mode Mode
+	// ModeRules override Mode per file; the first matching rule wins.
+	ModeRules []ModeRule
+
+	// Preserve selects the attributes kept on copied files. It applies to full copies and reflinks;
+	// hard links share the source's attributes and ModeSymlink links have none of their own.
+	Preserve Preserve
+
+	// Sync makes CopyFiles compare destination files by size and modification time instead of contents,
+	// or with Checksum by size and contents. Copied files keep the source mtime so that the next sync can
+	// compare them. Without Sync, destination files whose size and contents match the source are left alone.
+	Sync bool
+	// Checksum compares SHA-256 digests instead of modification times in Sync mode.
+	Checksum bool
+	// Delete makes CopyFiles remove destination files that match the include patterns but no longer
+	// exist in the source. It requires PreservePaths. Every such file goes through Conflict like an
+	// overwrite would: ConflictOverwrite deletes it, ConflictBackup moves it to a backup, ConflictPrompt
+	// asks ConfirmDelete, and the other policies keep it.
+	Delete bool
+	// DeleteFilter, if non-nil, receives the relative paths of the files Delete would remove and returns
+	// the ones that may be removed, for example only the files git does not track in the destination.
+	// The others are reported as Protected.
+	DeleteFilter func(ctx context.Context, rels []string) ([]string, error)
+
+	// Conflict decides what happens to destination files that already exist and differ from the source
+	// (see Sync for how they are compared). Empty means ConflictOverwrite.
+	Conflict ConflictPolicy
+	// ConfirmOverwrite is called with the relative path of each conflicting file under ConflictPrompt.
+	// Calls happen one at a time, before any file is copied.
+	ConfirmOverwrite func(ctx context.Context, rel string) (bool, error)
+	// ConfirmDelete is called like ConfirmOverwrite for each file Delete would remove.
+	ConfirmDelete func(ctx context.Context, rel string) (bool, error)
+
+	// Include selects files in addition to the include patterns, using .gitignore syntax.
+	// Its negated rules only apply to its own rules, and the exclude patterns still win.
+	Include *Matcher
+	// Filter, if non-nil, receives the relative paths of the selected files and returns the ones to copy,
+	// for example only the files git ignores. The others are reported as skipped.
+	Filter func(ctx context.Context, rels []string) ([]string, error)
+
+	// Concurrency bounds the number of files copied at once. Defaults to runtime.NumCPU().
+	Concurrency int
+	// Progress, if non-nil, is called after each file is copied. Calls are serialized but may come from
+	// different goroutines. It is not called in dry-run mode.
+	Progress func(Progress)
+}
+
+// modeFor returns the requested mode for rel.
+func (o Options) modeFor(rel string) Mode {
+	for _, r := range o.ModeRules {
+		if ok, err := doublestar.Match(r.Pattern, rel); err == nil && ok {
+			return r.Mode
+		}
+	}
+	if o.Mode == "" {
+		return ModeCopy
+	}
+	return o.Mode
 }
 
 // Result describes what was copied.
 type Result struct {
-	CopiedFiles []string // relative paths from srcRoot
+	CopiedFiles []string // relative paths from srcRoot of the files written (or to be written in dry-run mode)
+	// Modes maps each entry of CopiedFiles to the copy mode actually used, which differs from the requested
+	// mode when reflink or hardlink fell back to a full copy. Symlinks kept by Preserve.Symlinks are
+	// reported as ModeSymlink. In dry-run mode it holds the requested mode.
+	Modes map[string]Mode
+
+	// Added and Updated split CopiedFiles by whether the destination already existed.
+	Added   []string
+	Updated []string
+	// Unchanged lists the files left alone because the destination already matched the source.
+	Unchanged []string
+	// Skipped lists the files matched by the include patterns but excluded, dropped by Filter, or left
+	// alone by the conflict policy.
+	Skipped []string
+	// Conflicts lists the destination files that already existed and differed from the source,
+	// with what the conflict policy did to them.
+	Conflicts []Conflict
+	// Deleted lists the destination files removed (or to be removed in dry-run mode) by Delete, including
+	// the ones moved to a backup.
+	Deleted []string
+	// Protected lists the destination files Delete would have removed but DeleteFilter kept.
+	Protected []string
+}
+
+// matchFiles returns the paths in fsys selected by includePatterns (globs) or include (.gitignore rules),
+// without duplicates.
+func matchFiles(ctx context.Context, fsys fs.FS, includePatterns []string, include *Matcher) ([]string, error) {
+	var (
+		matches []string
+		seen    = map[string]struct{}{}
+	)
+	add := func(match string) {
+		if _, ok := seen[match]; !ok {
+			seen[match] = struct{}{}
+			matches = append(matches, match)
+		}
+	}
+	for _, rawPattern := range includePatterns {
+		rawPattern = strings.TrimSpace(rawPattern)
+		if rawPattern == "" {
+			continue
+		}
+		if !isSafePattern(rawPattern) {
+			return nil, fmt.Errorf("%w: %q", ErrUnsafePattern, rawPattern)
+		}
+
+		globbed, err := doublestar.Glob(fsys, filepath.ToSlash(rawPattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range globbed {
+			add(match)
+		}
+	}
+	ruleMatches, err := include.files(ctx, fsys)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range ruleMatches {
+		add(match)
+	}
+	return matches, nil
 }
 
 // CopyFiles copies files matching include patterns from srcRoot to dstRoot, excluding exclude patterns.
 func CopyFiles(ctx context.Context, srcRoot, dstRoot string, includePatterns, excludePatterns []string, opts Options) (Result, error) {
-	if len(includePatterns) == 0 {
+	if len(includePatterns) == 0 && opts.Include.Empty() {
 		return Result{}, ErrNoPatterns
 	}
-	if opts.PreservePaths == false {
-		// ok; flatten mode is supported.
-	} else {
-		opts.PreservePaths = true
+	if opts.Delete && !opts.PreservePaths {
+		return Result{}, errors.New("delete requires preserved paths")
+	}
+	if opts.Sync {
+		opts.Preserve.Times = true
 	}
 
 	srcFS := os.DirFS(srcRoot)
 
 	excludes := normalizePatterns(excludePatterns)
 
-	var copied []string
-	for _, rawPattern := range includePatterns {
-		rawPattern = strings.TrimSpace(rawPattern)
-		if rawPattern == "" {
+	var (
+		copied  []string
+		skipped []string
+		jobs    []job
+		byDst   = map[string]int{}
+	)
+	matches, err := matchFiles(ctx, srcFS, includePatterns, opts.Include)
+	if err != nil {
+		return Result{}, err
+	}
+
+	for _, match := range matches {
+		select {
+		case <-ctx.Done():
+			return Result{}, ctx.Err()
+		default:
+		}
+
+		rel := filepath.ToSlash(strings.TrimPrefix(match, "./"))
+		if rel == "" || strings.HasSuffix(rel, BackupSuffix) {
 			continue
 		}
-		if !isSafePattern(rawPattern) {
-			return Result{}, fmt.Errorf("%w: %q", ErrUnsafePattern, rawPattern)
+		if excluded(rel, excludes) {
+			if info, err := fs.Stat(srcFS, match); err == nil && !info.IsDir() {
+				skipped = append(skipped, rel)
+			}
+			continue
 		}
 
-		pattern := filepath.ToSlash(rawPattern)
-		matches, err := doublestar.Glob(srcFS, pattern)
+		var link string
+		info, err := fs.Lstat(srcFS, match)
 		if err != nil {
-			return Result{}, err
+			continue
 		}
-
-		for _, match := range matches {
-			select {
-			case <-ctx.Done():
-				return Result{}, ctx.Err()
-			default:
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if opts.Preserve.Symlinks {
+				if link, err = linkTarget(srcRoot, rel); err != nil {
+					return Result{}, err
+				}
+			} else if info, err = fs.Stat(srcFS, match); err != nil {
+				continue
 			}
+		}
+		if info.IsDir() {
+			continue
+		}
 
-			rel := filepath.ToSlash(strings.TrimPrefix(match, "./"))
-			if rel == "" {
-				continue
+		var dstPath string
+		if opts.PreservePaths {
+			dstPath = filepath.Join(dstRoot, filepath.FromSlash(rel))
+		} else {
+			dstPath = filepath.Join(dstRoot, filepath.Base(filepath.FromSlash(rel)))
+		}
+		j := job{
+			rel:  rel,
+			src:  filepath.Join(srcRoot, filepath.FromSlash(rel)),
+			dst:  dstPath,
+			size: info.Size(),
+			link: link,
+		}
+
+		// In flatten mode several matches can map to the same destination; the last one wins,
+		// as if the files had been copied one after another.
+		if i, ok := byDst[dstPath]; ok {
+			copied = slices.DeleteFunc(copied, func(r string) bool { return r == jobs[i].rel })
+			jobs[i] = j
+		} else {
+			byDst[dstPath] = len(jobs)
+			jobs = append(jobs, j)
+		}
+		copied = append(copied, rel)
+	}
+
+	if opts.Filter != nil && len(jobs) > 0 {
+		var dropped []string
+		if jobs, dropped, err = filterJobs(ctx, jobs, opts.Filter); err != nil {
+			return Result{}, err
+		}
+		copied = withoutPaths(copied, dropped)
+		skipped = append(skipped, dropped...)
+	}
+
+	res := Result{Skipped: skipped}
+	pending := jobs[:0]
+	backups := map[string]bool{}
+	for _, j := range jobs {
+		exists, same, err := j.destState(opts)
+		if err != nil {
+			return Result{}, err
+		}
+		switch {
+		case same:
+			res.Unchanged = append(res.Unchanged, j.rel)
+			continue
+		case exists:
+			action, err := j.resolveConflict(ctx, opts)
+			if err != nil {
+				return Result{}, err
 			}
-			if excluded(rel, excludes) {
+			c := Conflict{Path: j.rel, Action: action}
+			switch action {
+			case ConflictActionSkip:
+				res.Skipped = append(res.Skipped, j.rel)
+				res.Conflicts = append(res.Conflicts, c)
 				continue
+			case ConflictActionBackup:
+				if j.backup, err = backupPath(j.dst, backups); err != nil {
+					return Result{}, err
+				}
+				rel, err := filepath.Rel(dstRoot, j.backup)
+				if err != nil {
+					return Result{}, err
+				}
+				c.Backup = filepath.ToSlash(rel)
 			}
+			res.Conflicts = append(res.Conflicts, c)
+			res.Updated = append(res.Updated, j.rel)
+		default:
+			res.Added = append(res.Added, j.rel)
+		}
+		pending = append(pending, j)
+	}
+	res.CopiedFiles = withoutPaths(withoutPaths(copied, res.Unchanged), res.Skipped)
 
-			info, err := fs.Stat(srcFS, match)
-			if err != nil || info.IsDir() {
-				continue
+	var deletes []job
+	if opts.Delete {
+		stale, err := staleFiles(ctx, srcRoot, dstRoot, includePatterns, excludes, opts)
+		if err != nil {
+			return Result{}, err
+		}
+		if opts.DeleteFilter != nil && len(stale) > 0 {
+			keep, err := opts.DeleteFilter(ctx, stale)
+			if err != nil {
+				return Result{}, err
 			}
-
-			if opts.DryRun {
-				copied = appendUnique(copied, rel)
+			res.Protected = withoutPaths(slices.Clone(stale), keep)
+			stale = keep
+		}
+		for _, rel := range stale {
+			action, err := resolveDelete(ctx, rel, opts)
+			if err != nil {
+				return Result{}, err
+			}
+			j := job{rel: rel, dst: filepath.Join(dstRoot, filepath.FromSlash(rel))}
+			c := Conflict{Path: rel, Action: action, Stale: true}
+			switch action {
+			case ConflictActionSkip:
+				res.Skipped = append(res.Skipped, rel)
+				res.Conflicts = append(res.Conflicts, c)
 				continue
+			case ConflictActionBackup:
+				if j.backup, err = backupPath(j.dst, backups); err != nil {
+					return Result{}, err
+				}
+				backupRel, err := filepath.Rel(dstRoot, j.backup)
+				if err != nil {
+					return Result{}, err
+				}
+				c.Backup = filepath.ToSlash(backupRel)
 			}
+			res.Conflicts = append(res.Conflicts, c)
+			res.Deleted = append(res.Deleted, rel)
+			deletes = append(deletes, j)
+		}
+	}
 
-			srcPath := filepath.Join(srcRoot, filepath.FromSlash(rel))
-			var dstPath string
-			if opts.PreservePaths {
-				dstPath = filepath.Join(dstRoot, filepath.FromSlash(rel))
-			} else {
-				dstPath = filepath.Join(dstRoot, filepath.Base(filepath.FromSlash(rel)))
-			}
+	if opts.DryRun {
+		res.Modes = make(map[string]Mode, len(pending))
+		for _, j := range pending {
+			res.Modes[j.rel] = j.mode(opts)
+		}
+		return res, nil
+	}
+
+	modes, err := runJobs(ctx, pending, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	res.Modes = modes
 
-			if err := copyFile(srcPath, dstPath); err != nil {
+	for _, j := range deletes {
+		if j.backup != "" {
+			if err := moveToBackup(j.dst, j.backup); err != nil {
 				return Result{}, err
 			}
-			copied = appendUnique(copied, rel)
+			continue
+		}
+		if err := os.Remove(j.dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return Result{}, err
 		}
 	}
-
-	return Result{CopiedFiles: copied}, nil
+	return res, nil
 }
 
 // DirResult describes directory-copy results.
 type DirResult struct {
 	CopiedDirs []string // relative directory paths from srcRoot
+	// Modes maps every copied file (relative path from srcRoot) to the copy mode actually used.
+	Modes map[string]Mode
 }
 
 // CopyDirectories copies directories whose base name matches any of includeDirPatterns.
 //
 // includeDirPatterns are matched against the directory base name (like `find -name`), not the full path.
 // excludeDirPatterns are matched against the full relative path from srcRoot (with `/` separators).
-func CopyDirectories(ctx context.Context, srcRoot, dstRoot string, includeDirPatterns, excludeDirPatterns []string) (DirResult, error) {
+// opts.Mode and opts.ModeRules select the copy mode of every file and opts.Preserve applies as in CopyFiles;
+// opts.Filter drops files as in CopyFiles; with opts.DryRun nothing is created and Modes reports the mode
+// each file would use. opts.PreservePaths, opts.Sync, opts.Delete and opts.Include are ignored.
+func CopyDirectories(ctx context.Context, srcRoot, dstRoot string, includeDirPatterns, excludeDirPatterns []string, opts Options) (DirResult, error) {
 	if len(includeDirPatterns) == 0 {
 		return DirResult{}, nil
 	}
@@ -138,13 +526,22 @@ func CopyDirectories(ctx context.Context, srcRoot, dstRoot string, includeDirPat
 	includes := normalizePatterns(includeDirPatterns)
 	excludes := normalizePatterns(excludeDirPatterns)
 
+	// A base-name pattern behaves like an unanchored, directory-only .gitignore rule.
+	var dirs Matcher
 	for _, p := range includes {
 		if !isSafePattern(p) || strings.Contains(p, "/") {
 			return DirResult{}, fmt.Errorf("%w: %q", ErrUnsafePattern, p)
 		}
+		if !doublestar.ValidatePattern(p) {
+			return DirResult{}, fmt.Errorf("%w: %q", doublestar.ErrBadPattern, p)
+		}
+		dirs.rules = append(dirs.rules, matchRule{pattern: "**/" + p, dirOnly: true})
 	}
 
-	var copiedDirs []string
+	var (
+		copiedDirs []string
+		jobs       []job
+	)
 
 	walkFn := func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
@@ -162,37 +559,23 @@ func CopyDirectories(ctx context.Context, srcRoot, dstRoot string, includeDirPat
 			return nil
 		}
 
-		base := d.Name()
-		matched := false
-		for _, p := range includes {
-			if !isSafePattern(p) {
-				return fmt.Errorf("%w: %q", ErrUnsafePattern, p)
-			}
-			ok, err := filepath.Match(filepath.FromSlash(p), base)
-			if err != nil {
-				return err
-			}
-			if ok {
-				matched = true
-				break
-			}
-		}
-		if !matched {
-			return nil
-		}
-
 		relDir, err := filepath.Rel(srcRoot, path)
 		if err != nil {
 			return err
 		}
 		relDir = filepath.ToSlash(relDir)
+		if !dirs.Match(relDir, true) {
+			return nil
+		}
 		if excluded(relDir, excludes) {
 			return fs.SkipDir
 		}
 
-		if err := copyDirTree(ctx, srcRoot, dstRoot, relDir, excludes); err != nil {
+		treeJobs, err := collectDirTree(ctx, srcRoot, dstRoot, relDir, excludes, opts.Preserve, !opts.DryRun)
+		if err != nil {
 			return err
 		}
+		jobs = append(jobs, treeJobs...)
 		copiedDirs = appendUnique(copiedDirs, relDir)
 		return fs.SkipDir
 	}
@@ -201,13 +584,35 @@ func CopyDirectories(ctx context.Context, srcRoot, dstRoot string, includeDirPat
 		return DirResult{}, err
 	}
 
-	return DirResult{CopiedDirs: copiedDirs}, nil
+	if opts.Filter != nil && len(jobs) > 0 {
+		var err error
+		if jobs, _, err = filterJobs(ctx, jobs, opts.Filter); err != nil {
+			return DirResult{}, err
+		}
+	}
+
+	if opts.DryRun {
+		modes := make(map[string]Mode, len(jobs))
+		for _, j := range jobs {
+			modes[j.rel] = j.mode(opts)
+		}
+		return DirResult{CopiedDirs: copiedDirs, Modes: modes}, nil
+	}
+
+	modes, err := runJobs(ctx, jobs, opts)
+	if err != nil {
+		return DirResult{}, err
+	}
+	return DirResult{CopiedDirs: copiedDirs, Modes: modes}, nil
 }
 
-func copyDirTree(ctx context.Context, srcRoot, dstRoot, relDir string, excludePatterns []string) error {
+// collectDirTree returns one job per file of relDir and, when mkdir is set, creates its directory structure
+// under dstRoot. Symlinks become link jobs when preserve.Symlinks is set.
+func collectDirTree(ctx context.Context, srcRoot, dstRoot, relDir string, excludePatterns []string, preserve Preserve, mkdir bool) ([]job, error) {
 	srcDir := filepath.Join(srcRoot, filepath.FromSlash(relDir))
 
-	return filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, walkErr error) error {
+	var jobs []job
+	err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
@@ -231,17 +636,155 @@ func copyDirTree(ctx context.Context, srcRoot, dstRoot, relDir string, excludePa
 
 		dstPath := filepath.Join(dstRoot, filepath.FromSlash(rel))
 		if d.IsDir() {
+			if !mkdir {
+				return nil
+			}
 			return os.MkdirAll(dstPath, 0o755)
 		}
+		if d.Type()&fs.ModeSymlink != 0 && preserve.Symlinks {
+			link, err := linkTarget(srcRoot, rel)
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, job{rel: rel, src: path, dst: dstPath, link: link})
+			return nil
+		}
 
-		if err := copyFile(path, dstPath); err != nil {
+		info, err := d.Info()
+		if err != nil {
 			return err
 		}
+		jobs = append(jobs, job{rel: rel, src: path, dst: dstPath, size: info.Size()})
 		return nil
 	})
+	return jobs, err
+}
+
+// linkTarget returns the target to recreate for the symlink at rel under srcRoot.
+//
+// It fails with ErrSymlinkEscape when the link resolves outside srcRoot, and rewrites absolute targets
+// inside srcRoot as relative ones.
+func linkTarget(srcRoot, rel string) (string, error) {
+	root, err := filepath.Abs(srcRoot)
+	if err != nil {
+		return "", err
+	}
+	srcPath := filepath.Join(root, filepath.FromSlash(rel))
+	target, err := os.Readlink(srcPath)
+	if err != nil {
+		return "", err
+	}
+
+	resolved := target
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(srcPath), target)
+	}
+	inRoot, err := filepath.Rel(root, resolved)
+	if err != nil || inRoot == ".." || strings.HasPrefix(inRoot, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s -> %s", ErrSymlinkEscape, rel, target)
+	}
+
+	if filepath.IsAbs(target) {
+		return filepath.Rel(filepath.Dir(srcPath), resolved)
+	}
+	return target, nil
+}
+
+// materialize creates dstPath from srcPath using mode, applies preserve, and returns the mode actually used.
+//
+// An existing dstPath is removed first, so that a previous hardlink or symlink is never written through.
+func materialize(ctx context.Context, srcPath, dstPath string, mode Mode, preserve Preserve) (Mode, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.Remove(dstPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	switch mode {
+	case ModeReflink:
+		if err := reflinkFile(srcPath, dstPath); err == nil {
+			if err := preserveAttrs(srcPath, dstPath, preserve); err != nil {
+				return "", err
+			}
+			return ModeReflink, nil
+		}
+	case ModeHardlink:
+		if err := os.Link(srcPath, dstPath); err == nil {
+			return ModeHardlink, nil
+		}
+	case ModeSymlink:
+		abs, err := filepath.Abs(srcPath)
+		if err != nil {
+			return "", err
+		}
+		if err := os.Symlink(abs, dstPath); err != nil {
+			return "", err
+		}
+		return ModeSymlink, nil
+	}
+
+	if err := copyFile(ctx, srcPath, dstPath); err != nil {
+		return "", err
+	}
+	if err := preserveAttrs(srcPath, dstPath, preserve); err != nil {
+		return "", err
+	}
+	return ModeCopy, nil
+}
+
+// materializeLink recreates a preserved symlink at dstPath.
+func materializeLink(ctx context.Context, target, dstPath string) (Mode, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.Remove(dstPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if err := os.Symlink(target, dstPath); err != nil {
+		return "", err
+	}
+	return ModeSymlink, nil
+}
+
+// preserveAttrs copies the attributes selected by p from srcPath to the regular file dstPath.
+//
+// Extended attributes are copied first because setting them may need write permission that the
+// preserved mode takes away; the mtime is set last because the other changes do not touch it.
+func preserveAttrs(srcPath, dstPath string, p Preserve) error {
+	if !p.Mode && !p.Times && !p.Xattrs {
+		return nil
+	}
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	if p.Xattrs {
+		if err := copyXattrs(srcPath, dstPath); err != nil {
+			return fmt.Errorf("copy xattrs of %s: %w", srcPath, err)
+		}
+	}
+	if p.Mode {
+		if err := os.Chmod(dstPath, info.Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky)); err != nil {
+			return err
+		}
+	}
+	if p.Times {
+		if err := os.Chtimes(dstPath, time.Time{}, info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func copyFile(srcPath, dstPath string) (err error) {
+func copyFile(ctx context.Context, srcPath, dstPath string) (err error) {
 	srcFile, err := os.Open(srcPath)
 	if err != nil {
 		return err
@@ -263,10 +806,19 @@ func copyFile(srcPath, dstPath string) (err error) {
 	}
 	defer func() { _ = dstFile.Close() }()
 
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		return err
+	// Copy in chunks so that cancellation is noticed within large files, while keeping the
+	// kernel fast paths (copy_file_range, sendfile) that io.Copy uses between *os.File values.
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(dstFile, srcFile, copyChunkSize); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
 	}
-	return nil
 }
 
 func isSafePattern(pattern string) bool {
@@ -306,6 +858,21 @@ func excluded(path string, excludePatterns []string) bool {
 	return false
 }
 
+// withoutPaths removes every element of drop from paths in place.
+func withoutPaths(paths, drop []string) []string {
+	if len(drop) == 0 {
+		return paths
+	}
+	set := make(map[string]struct{}, len(drop))
+	for _, p := range drop {
+		set[p] = struct{}{}
+	}
+	return slices.DeleteFunc(paths, func(p string) bool {
+		_, ok := set[p]
+		return ok
+	})
+}
+
 func appendUnique(dst []string, value string) []string {
 	if slices.Contains(dst, value) {
 		return dst
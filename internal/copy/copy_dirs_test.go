@@ -33,6 +33,7 @@ func TestCopyDirectories(t *testing.T) {
 		setupSrc    func(t *testing.T, srcRoot string)
 		includes    []string
 		excludes    []string
+		dryRun      bool
 		wantDirs    []string
 		wantPresent []string
 		wantAbsent  []string
@@ -66,6 +67,21 @@ func TestCopyDirectories(t *testing.T) {
 				"node_modules/.cache/secret.txt",
 			},
 		},
+		"success: dry run creates nothing": {
+			setupSrc: func(t *testing.T, srcRoot string) {
+				t.Helper()
+				if err := os.MkdirAll(filepath.Join(srcRoot, "node_modules", "pkg"), 0o755); err != nil {
+					t.Fatalf("MkdirAll: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(srcRoot, "node_modules", "pkg", "index.js"), []byte("ok\n"), 0o644); err != nil {
+					t.Fatalf("WriteFile: %v", err)
+				}
+			},
+			includes:   []string{"node_modules"},
+			dryRun:     true,
+			wantDirs:   []string{"node_modules"},
+			wantAbsent: []string{"node_modules"},
+		},
 		"error: unsafe include pattern rejected": {
 			setupSrc: func(t *testing.T, _ string) {},
 			includes: []string{"../node_modules"},
@@ -82,7 +98,7 @@ func TestCopyDirectories(t *testing.T) {
 
 			tc.setupSrc(t, srcRoot)
 
-			got, err := CopyDirectories(t.Context(), srcRoot, dstRoot, tc.includes, tc.excludes)
+			got, err := CopyDirectories(t.Context(), srcRoot, dstRoot, tc.includes, tc.excludes, Options{DryRun: tc.dryRun})
 			if tc.wantErr != nil {
 				if err == nil {
 					t.Fatalf("expected error, got nil")
@@ -0,0 +1,174 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseModeRule(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		in      string
+		want    ModeRule
+		wantErr error
+	}{
+		"success: pattern and mode": {
+			in:   "node_modules/**=hardlink",
+			want: ModeRule{Pattern: "node_modules/**", Mode: ModeHardlink},
+		},
+		"error: missing mode": {
+			in:      "node_modules/**",
+			wantErr: ErrInvalidMode,
+		},
+		"error: unknown mode": {
+			in:      "node_modules/**=move",
+			wantErr: ErrInvalidMode,
+		},
+		"error: unsafe pattern": {
+			in:      "../x=copy",
+			wantErr: ErrUnsafePattern,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseModeRule(tc.in)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseModeRule() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("rule mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCopyFilesModes(t *testing.T) {
+	t.Parallel()
+
+	srcRoot := t.TempDir()
+	dstRoot := t.TempDir()
+	for _, rel := range []string{"a.txt", "b.txt", "c.txt", "deps/d.txt"} {
+		p := filepath.Join(srcRoot, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(rel+"\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	opts := Options{
+		PreservePaths: true,
+		Mode:          ModeHardlink,
+		ModeRules: []ModeRule{
+			{Pattern: "b.txt", Mode: ModeCopy},
+			{Pattern: "c.txt", Mode: ModeReflink},
+			{Pattern: "deps/**", Mode: ModeSymlink},
+		},
+	}
+	if runtime.GOOS == "windows" {
+		// Creating symlinks requires elevated privileges on Windows.
+		opts.ModeRules = opts.ModeRules[:2]
+	}
+
+	got, err := CopyFiles(t.Context(), srcRoot, dstRoot, []string{"**/*.txt"}, nil, opts)
+	if err != nil {
+		t.Fatalf("CopyFiles() error: %v", err)
+	}
+
+	// Reflink falls back to a plain copy on filesystems without copy-on-write support.
+	if got.Modes["c.txt"] != ModeReflink {
+		if diff := cmp.Diff(ModeCopy, got.Modes["c.txt"]); diff != "" {
+			t.Fatalf("reflink fallback mismatch (-want +got):\n%s", diff)
+		}
+	}
+	delete(got.Modes, "c.txt")
+	want := map[string]Mode{
+		"a.txt":      ModeHardlink,
+		"b.txt":      ModeCopy,
+		"deps/d.txt": ModeSymlink,
+	}
+	if runtime.GOOS == "windows" {
+		want["deps/d.txt"] = ModeHardlink
+	}
+	if diff := cmp.Diff(want, got.Modes); diff != "" {
+		t.Fatalf("modes mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, rel := range []string{"a.txt", "b.txt", "c.txt", "deps/d.txt"} {
+		b, err := os.ReadFile(filepath.Join(dstRoot, filepath.FromSlash(rel)))
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", rel, err)
+		}
+		if diff := cmp.Diff(rel+"\n", string(b)); diff != "" {
+			t.Fatalf("%s contents mismatch (-want +got):\n%s", rel, diff)
+		}
+	}
+
+	srcInfo, err := os.Stat(filepath.Join(srcRoot, "a.txt"))
+	if err != nil {
+		t.Fatalf("Stat(src): %v", err)
+	}
+	dstInfo, err := os.Stat(filepath.Join(dstRoot, "a.txt"))
+	if err != nil {
+		t.Fatalf("Stat(dst): %v", err)
+	}
+	if !os.SameFile(srcInfo, dstInfo) {
+		t.Fatalf("expected a.txt to be hard-linked")
+	}
+	if runtime.GOOS != "windows" {
+		fi, err := os.Lstat(filepath.Join(dstRoot, "deps", "d.txt"))
+		if err != nil {
+			t.Fatalf("Lstat: %v", err)
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			t.Fatalf("expected deps/d.txt to be a symlink, got mode %v", fi.Mode())
+		}
+	}
+
+	// Re-copying over a hard link must replace the link instead of writing through it.
+	if _, err := CopyFiles(t.Context(), srcRoot, dstRoot, []string{"a.txt"}, nil, Options{PreservePaths: true}); err != nil {
+		t.Fatalf("CopyFiles(copy) error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dstRoot, "a.txt"), []byte("changed\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(srcRoot, "a.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile(src): %v", err)
+	}
+	if diff := cmp.Diff("a.txt\n", string(b)); diff != "" {
+		t.Fatalf("source modified through link (-want +got):\n%s", diff)
+	}
+}
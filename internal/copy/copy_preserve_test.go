@@ -0,0 +1,181 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePreserve(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		in      string
+		want    Preserve
+		wantErr error
+	}{
+		"success: empty": {
+			in:   "",
+			want: Preserve{},
+		},
+		"success: list": {
+			in:   "symlinks, times",
+			want: Preserve{Symlinks: true, Times: true},
+		},
+		"success: all": {
+			in:   "all",
+			want: Preserve{Symlinks: true, Mode: true, Times: true, Xattrs: true},
+		},
+		"error: unknown attribute": {
+			in:      "mode,owner",
+			wantErr: ErrInvalidPreserve,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParsePreserve(tc.in)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePreserve() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("preserve mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCopyDirectoriesPreserve(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and permission bits are not portable to Windows")
+	}
+
+	srcRoot := t.TempDir()
+	cacheDir := filepath.Join(srcRoot, "cache")
+	if err := os.MkdirAll(filepath.Join(cacheDir, "sub"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	obj := filepath.Join(cacheDir, "sub", "obj.o")
+	if err := os.WriteFile(obj, []byte("obj\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.Chmod(obj, 0o751); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(obj, time.Time{}, mtime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	if err := os.Symlink("sub/obj.o", filepath.Join(cacheDir, "rel")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+	if err := os.Symlink(obj, filepath.Join(cacheDir, "abs")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	dstRoot := t.TempDir()
+	got, err := CopyDirectories(t.Context(), srcRoot, dstRoot, []string{"cache"}, nil, Options{
+		Preserve: Preserve{Symlinks: true, Mode: true, Times: true},
+	})
+	if err != nil {
+		t.Fatalf("CopyDirectories() error: %v", err)
+	}
+	want := map[string]Mode{
+		"cache/abs":       ModeSymlink,
+		"cache/rel":       ModeSymlink,
+		"cache/sub/obj.o": ModeCopy,
+	}
+	if diff := cmp.Diff(want, got.Modes); diff != "" {
+		t.Fatalf("modes mismatch (-want +got):\n%s", diff)
+	}
+
+	for name, wantTarget := range map[string]string{"rel": "sub/obj.o", "abs": "sub/obj.o"} {
+		target, err := os.Readlink(filepath.Join(dstRoot, "cache", name))
+		if err != nil {
+			t.Fatalf("Readlink(%s): %v", name, err)
+		}
+		if diff := cmp.Diff(wantTarget, target); diff != "" {
+			t.Fatalf("%s target mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dstRoot, "cache", "sub", "obj.o"))
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if diff := cmp.Diff(os.FileMode(0o751), info.Mode().Perm()); diff != "" {
+		t.Fatalf("mode mismatch (-want +got):\n%s", diff)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Fatalf("mtime = %v, want %v", info.ModTime(), mtime)
+	}
+}
+
+func TestCopyFilesSymlinkEscape(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires elevated privileges on Windows")
+	}
+
+	outside := filepath.Join(t.TempDir(), "secret.env")
+	if err := os.WriteFile(outside, []byte("secret\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	srcRoot := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(srcRoot, ".env")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	_, err := CopyFiles(t.Context(), srcRoot, t.TempDir(), []string{".env"}, nil, Options{
+		PreservePaths: true,
+		Preserve:      Preserve{Symlinks: true},
+	})
+	if !errors.Is(err, ErrSymlinkEscape) {
+		t.Fatalf("expected %v, got %v", ErrSymlinkEscape, err)
+	}
+
+	// Without Preserve.Symlinks the link is followed, as before.
+	dstRoot := t.TempDir()
+	if _, err := CopyFiles(t.Context(), srcRoot, dstRoot, []string{".env"}, nil, Options{PreservePaths: true}); err != nil {
+		t.Fatalf("CopyFiles() error: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dstRoot, ".env"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if diff := cmp.Diff("secret\n", string(b)); diff != "" {
+		t.Fatalf("contents mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// Progress reports how far a copy has advanced.
+type Progress struct {
+	FilesDone  int
+	FilesTotal int
+	BytesDone  int64
+	BytesTotal int64
+}
+
+// job is a single file to materialize.
+type job struct {
+	rel  string // relative path from srcRoot, with `/` separators
+	src  string
+	dst  string
+	size int64
+	link string // symlink target to recreate when the source is a preserved symlink
+
+	backup string // path to move the existing destination to first, if any (see backupPath)
+}
+
+// mode returns the requested mode of j.
+func (j job) mode(opts Options) Mode {
+	if j.link != "" {
+		return ModeSymlink
+	}
+	return opts.modeFor(j.rel)
+}
+
+// run materializes j and returns the mode actually used.
+func (j job) run(ctx context.Context, opts Options) (Mode, error) {
+	if j.backup != "" {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if err := moveToBackup(j.dst, j.backup); err != nil {
+			return "", err
+		}
+	}
+	if j.link != "" {
+		return materializeLink(ctx, j.link, j.dst)
+	}
+	return materialize(ctx, j.src, j.dst, opts.modeFor(j.rel), opts.Preserve)
+}
+
+// moveToBackup renames dst to backup, refusing to replace an existing backup.
+func moveToBackup(dst, backup string) error {
+	if _, err := os.Lstat(backup); err == nil {
+		return fmt.Errorf("back up %s: %s already exists", dst, backup)
+	}
+	if err := os.Rename(dst, backup); err != nil {
+		return fmt.Errorf("back up %s: %w", dst, err)
+	}
+	return nil
+}
+
+// filterJobs keeps the jobs whose relative path filter returns, and returns the others' paths as dropped.
+func filterJobs(ctx context.Context, jobs []job, filter func(ctx context.Context, rels []string) ([]string, error)) (kept []job, dropped []string, err error) {
+	rels := make([]string, 0, len(jobs))
+	for _, j := range jobs {
+		rels = append(rels, j.rel)
+	}
+	keep, err := filter(ctx, rels)
+	if err != nil {
+		return nil, nil, err
+	}
+	keepSet := make(map[string]struct{}, len(keep))
+	for _, rel := range keep {
+		keepSet[rel] = struct{}{}
+	}
+
+	for _, j := range jobs {
+		if _, ok := keepSet[j.rel]; ok {
+			kept = append(kept, j)
+		} else {
+			dropped = append(dropped, j.rel)
+		}
+	}
+	return kept, dropped, nil
+}
+
+// runJobs materializes jobs with a bounded worker pool and returns the copy mode used per relative path.
+//
+// The first failure cancels the remaining jobs and is returned.
+func runJobs(ctx context.Context, jobs []job, opts Options) (map[string]Mode, error) {
+	modes := make(map[string]Mode, len(jobs))
+	if len(jobs) == 0 {
+		return modes, nil
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(jobs))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		progress = Progress{FilesTotal: len(jobs)}
+		queue    = make(chan job)
+	)
+	for _, j := range jobs {
+		progress.BytesTotal += j.size
+	}
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				used, err := j.run(ctx, opts)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+				modes[j.rel] = used
+				progress.FilesDone++
+				progress.BytesDone += j.size
+				if opts.Progress != nil {
+					opts.Progress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, j := range jobs {
+		select {
+		case queue <- j:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return modes, nil
+}
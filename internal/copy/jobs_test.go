@@ -0,0 +1,103 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyDirectoriesConcurrentProgress(t *testing.T) {
+	t.Parallel()
+
+	srcRoot := t.TempDir()
+	var wantBytes int64
+	for i := range 50 {
+		p := filepath.Join(srcRoot, "deps", fmt.Sprintf("pkg%d", i%5), fmt.Sprintf("f%d.txt", i))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		data := []byte(fmt.Sprintf("file %d\n", i))
+		wantBytes += int64(len(data))
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	var updates []Progress
+	dstRoot := t.TempDir()
+	got, err := CopyDirectories(t.Context(), srcRoot, dstRoot, []string{"deps"}, nil, Options{
+		Concurrency: 4,
+		Progress: func(p Progress) {
+			updates = append(updates, p)
+		},
+	})
+	if err != nil {
+		t.Fatalf("CopyDirectories() error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"deps"}, got.CopiedDirs); diff != "" {
+		t.Fatalf("copied mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(50, len(updates)); diff != "" {
+		t.Fatalf("progress calls mismatch (-want +got):\n%s", diff)
+	}
+	for i, p := range updates {
+		if p.FilesDone != i+1 {
+			t.Fatalf("update %d: FilesDone = %d, want %d", i, p.FilesDone, i+1)
+		}
+	}
+	want := Progress{FilesDone: 50, FilesTotal: 50, BytesDone: wantBytes, BytesTotal: wantBytes}
+	if diff := cmp.Diff(want, updates[len(updates)-1]); diff != "" {
+		t.Fatalf("final progress mismatch (-want +got):\n%s", diff)
+	}
+
+	for i := range 50 {
+		rel := filepath.Join("deps", fmt.Sprintf("pkg%d", i%5), fmt.Sprintf("f%d.txt", i))
+		b, err := os.ReadFile(filepath.Join(dstRoot, rel))
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", rel, err)
+		}
+		if diff := cmp.Diff(fmt.Sprintf("file %d\n", i), string(b)); diff != "" {
+			t.Fatalf("%s contents mismatch (-want +got):\n%s", rel, diff)
+		}
+	}
+}
+
+func TestCopyFilesCanceled(t *testing.T) {
+	t.Parallel()
+
+	srcRoot := t.TempDir()
+	for i := range 10 {
+		if err := os.WriteFile(filepath.Join(srcRoot, fmt.Sprintf("f%d.txt", i)), []byte("x\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := CopyFiles(ctx, srcRoot, t.TempDir(), []string{"*.txt"}, nil, Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	doublestar "github.com/bmatcuk/doublestar/v4"
+)
+
+// Matcher selects files by their path relative to the copy source, using .gitignore syntax.
+//
+// A leading "!" negates a rule, a trailing "/" makes it match directories only, and a "/" at the start
+// or in the middle anchors it to the directory of the file it came from; other rules match at any depth.
+// A rule that matches a directory matches everything below it. The last matching rule wins; unlike
+// .gitignore, a negated rule can exclude files inside a directory selected by an earlier rule.
+//
+// The zero Matcher matches nothing.
+type Matcher struct {
+	rules []matchRule
+}
+
+type matchRule struct {
+	base    string // directory of the rule's file relative to the root, with `/` separators; "" for the root
+	pattern string // doublestar pattern relative to base
+	negate  bool
+	dirOnly bool
+}
+
+// AddRules adds .gitignore-syntax lines read from a file in the directory base (relative to the source
+// root with `/` separators, "" for the root). Blank lines and comments are skipped.
+func (m *Matcher) AddRules(base string, lines []string) error {
+	base = strings.Trim(path.Clean("/"+base), "/")
+	for _, line := range lines {
+		r, ok, err := parseRule(base, line)
+		if err != nil {
+			return err
+		}
+		if ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+	return nil
+}
+
+func parseRule(base, line string) (matchRule, bool, error) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return matchRule{}, false, nil
+	}
+
+	r := matchRule{base: base}
+	switch {
+	case strings.HasPrefix(line, "!"):
+		r.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return matchRule{}, false, nil
+	}
+	if !isSafePattern(line) {
+		return matchRule{}, false, fmt.Errorf("%w: %q", ErrUnsafePattern, line)
+	}
+	if !anchored {
+		line = "**/" + line
+	}
+	if !doublestar.ValidatePattern(line) {
+		return matchRule{}, false, fmt.Errorf("%w: %q", doublestar.ErrBadPattern, line)
+	}
+	r.pattern = line
+	return r, true, nil
+}
+
+// Empty reports whether m has no rules.
+func (m *Matcher) Empty() bool {
+	return m == nil || len(m.rules) == 0
+}
+
+// Match reports whether rel (relative to the source root, with `/` separators) is selected.
+// isDir tells whether rel itself is a directory.
+func (m *Matcher) Match(rel string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	matched := false
+	for _, r := range m.rules {
+		if r.match(rel, isDir) {
+			matched = !r.negate
+		}
+	}
+	return matched
+}
+
+func (r matchRule) match(rel string, isDir bool) bool {
+	if r.base != "" {
+		var ok bool
+		if rel, ok = strings.CutPrefix(rel, r.base+"/"); !ok {
+			return false
+		}
+	}
+	// Try rel itself, then every parent directory.
+	for p, dir := rel, isDir; p != "."; p, dir = path.Dir(p), true {
+		if (dir || !r.dirOnly) && doublestar.MatchUnvalidated(r.pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// files returns every regular file or symlink under fsys that m selects, in lexical order per rule.
+func (m *Matcher) files(ctx context.Context, fsys fs.FS) ([]string, error) {
+	if m.Empty() {
+		return nil, nil
+	}
+
+	var (
+		out    []string
+		seen   = map[string]struct{}{}
+		walked []string // directories already expanded
+	)
+	add := func(rel string) {
+		if _, ok := seen[rel]; ok || !m.Match(rel, false) {
+			return
+		}
+		seen[rel] = struct{}{}
+		out = append(out, rel)
+	}
+	underWalked := func(rel string) bool {
+		for _, w := range walked {
+			if rel == w || strings.HasPrefix(rel, w+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, r := range m.rules {
+		if r.negate {
+			continue
+		}
+		matches, err := doublestar.Glob(fsys, path.Join(r.base, r.pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if underWalked(match) {
+				continue
+			}
+			info, err := fs.Lstat(fsys, match)
+			if err != nil {
+				continue
+			}
+			if !info.IsDir() {
+				if !r.dirOnly {
+					add(match)
+				}
+				continue
+			}
+
+			walked = append(walked, match)
+			err = fs.WalkDir(fsys, match, func(p string, d fs.DirEntry, walkErr error) error {
+				if walkErr != nil {
+					return walkErr
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if !d.IsDir() {
+					add(p)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMatcherMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		base  string
+		lines []string
+		want  map[string]bool // path -> selected; paths ending in "/" are directories
+	}{
+		"success: unanchored pattern matches at any depth": {
+			lines: []string{".env"},
+			want:  map[string]bool{".env": true, "apps/web/.env": true, ".env.local": false},
+		},
+		"success: anchored pattern": {
+			lines: []string{"/.env", "config/*.yaml"},
+			want:  map[string]bool{".env": true, "apps/.env": false, "config/dev.yaml": true, "apps/config/dev.yaml": false},
+		},
+		"success: directory-only pattern": {
+			lines: []string{"cache/"},
+			want:  map[string]bool{"cache": false, "cache/": true, "cache/a/b.bin": true, "pkg/cache/x": true},
+		},
+		"success: negation wins when last": {
+			lines: []string{"*.env", "!prod.env", "node_modules/", "!node_modules/.cache/"},
+			want: map[string]bool{
+				"dev.env":                  true,
+				"prod.env":                 false,
+				"node_modules/a.js":        true,
+				"node_modules/.cache/x.js": false,
+			},
+		},
+		"success: escaped leading characters": {
+			lines: []string{`\!important`, `\#notes`, "# comment", ""},
+			want:  map[string]bool{"!important": true, "#notes": true, "comment": false},
+		},
+		"success: nested file base": {
+			base:  "apps/web",
+			lines: []string{".env", "/local/"},
+			want: map[string]bool{
+				"apps/web/.env":         true,
+				"apps/web/src/.env":     true,
+				"apps/web/local/x":      true,
+				"apps/web/src/local/x":  false,
+				".env":                  false,
+				"apps/website/.env":     false,
+				"apps/web/.env.example": false,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var m Matcher
+			if err := m.AddRules(tc.base, tc.lines); err != nil {
+				t.Fatalf("AddRules() error: %v", err)
+			}
+			got := map[string]bool{}
+			for p := range tc.want {
+				rel, isDir := p, false
+				if p[len(p)-1] == '/' {
+					rel, isDir = p[:len(p)-1], true
+				}
+				got[p] = m.Match(rel, isDir)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("match mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMatcherAddRulesUnsafe(t *testing.T) {
+	t.Parallel()
+
+	var m Matcher
+	if err := m.AddRules("", []string{"../secrets"}); !errors.Is(err, ErrUnsafePattern) {
+		t.Fatalf("expected %v, got %v", ErrUnsafePattern, err)
+	}
+}
+
+func TestCopyFilesIncludeRules(t *testing.T) {
+	t.Parallel()
+
+	srcRoot := t.TempDir()
+	for _, rel := range []string{".env", "apps/web/.env", "apps/web/.env.prod", "node_modules/a/a.js", "node_modules/.cache/c.bin", "src/main.go"} {
+		p := filepath.Join(srcRoot, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(rel+"\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	var rules Matcher
+	if err := rules.AddRules("", []string{".env*", "!.env.prod", "node_modules/", "!node_modules/.cache/"}); err != nil {
+		t.Fatalf("AddRules() error: %v", err)
+	}
+
+	got, err := CopyFiles(t.Context(), srcRoot, t.TempDir(), nil, nil, Options{
+		PreservePaths: true,
+		Include:       &rules,
+		Filter: func(ctx context.Context, rels []string) ([]string, error) {
+			return slices.DeleteFunc(rels, func(rel string) bool { return rel == "apps/web/.env" }), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("CopyFiles() error: %v", err)
+	}
+	if diff := cmp.Diff([]string{".env", "node_modules/a/a.js"}, got.CopiedFiles); diff != "" {
+		t.Fatalf("copied mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"apps/web/.env"}, got.Skipped); diff != "" {
+		t.Fatalf("skipped mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// destState reports whether j.dst exists and whether it already matches the source: by size and contents,
+// or in Sync mode without Checksum by size and modification time.
+func (j job) destState(opts Options) (exists, same bool, err error) {
+	dstInfo, err := os.Lstat(j.dst)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	if j.link != "" {
+		target, err := os.Readlink(j.dst)
+		return true, err == nil && target == j.link, nil
+	}
+
+	srcInfo, err := os.Stat(j.src)
+	if err != nil {
+		return true, false, err
+	}
+	mode := opts.modeFor(j.rel)
+	switch mode {
+	case ModeSymlink:
+		abs, err := filepath.Abs(j.src)
+		if err != nil {
+			return true, false, err
+		}
+		target, err := os.Readlink(j.dst)
+		return true, err == nil && target == abs, nil
+	case ModeHardlink:
+		if os.SameFile(srcInfo, dstInfo) {
+			return true, true, nil
+		}
+		// The link may have fallen back to a copy; compare it like one.
+	default:
+		// A destination linked to the source must be replaced to honor the requested mode.
+		if os.SameFile(srcInfo, dstInfo) {
+			return true, false, nil
+		}
+	}
+
+	if !dstInfo.Mode().IsRegular() || srcInfo.Size() != dstInfo.Size() {
+		return true, false, nil
+	}
+	if opts.Sync && !opts.Checksum {
+		return true, srcInfo.ModTime().Equal(dstInfo.ModTime()), nil
+	}
+	same, err = sameContents(j.src, j.dst)
+	return true, same, err
+}
+
+func sameContents(aPath, bPath string) (bool, error) {
+	a, err := fileDigest(aPath)
+	if err != nil {
+		return false, err
+	}
+	b, err := fileDigest(bPath)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(a, b), nil
+}
+
+func fileDigest(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// staleFiles returns the files under dstRoot that have no counterpart under srcRoot but are selected by
+// includePatterns and opts.Include and not excluded. opts.Filter does not apply: it classifies source files.
+func staleFiles(ctx context.Context, srcRoot, dstRoot string, includePatterns, excludePatterns []string, opts Options) ([]string, error) {
+	dstFS := os.DirFS(dstRoot)
+
+	matches, err := matchFiles(ctx, dstFS, includePatterns, opts.Include)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, match := range matches {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rel := filepath.ToSlash(strings.TrimPrefix(match, "./"))
+		if rel == "" || strings.HasSuffix(rel, BackupSuffix) || excluded(rel, excludePatterns) {
+			continue
+		}
+		info, err := fs.Lstat(dstFS, match)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(srcRoot, filepath.FromSlash(rel))); !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		stale = appendUnique(stale, rel)
+	}
+	return stale, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package copy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestCopyFilesSync(t *testing.T) {
+	t.Parallel()
+
+	write := func(t *testing.T, path, content string, mtime time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if err := os.Chtimes(path, time.Time{}, mtime); err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+	}
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := old.Add(time.Hour)
+
+	tests := map[string]struct {
+		opts          Options
+		wantResult    Result
+		wantRemaining []string
+	}{
+		"success: size and mtime": {
+			opts: Options{PreservePaths: true, Sync: true},
+			wantResult: Result{
+				CopiedFiles: []string{"a.env", "c.env", "touched.env"},
+				Added:       []string{"a.env"},
+				Updated:     []string{"c.env", "touched.env"},
+				Unchanged:   []string{"b.env"},
+				Skipped:     []string{"secret.env"},
+				Conflicts: []Conflict{
+					{Path: "c.env", Action: ConflictActionOverwrite},
+					{Path: "touched.env", Action: ConflictActionOverwrite},
+				},
+			},
+			wantRemaining: []string{"a.env", "b.env", "c.env", "gone.env", "touched.env"},
+		},
+		"success: checksum ignores mtime": {
+			opts: Options{PreservePaths: true, Sync: true, Checksum: true},
+			wantResult: Result{
+				CopiedFiles: []string{"a.env", "c.env"},
+				Added:       []string{"a.env"},
+				Updated:     []string{"c.env"},
+				Unchanged:   []string{"b.env", "touched.env"},
+				Skipped:     []string{"secret.env"},
+				Conflicts:   []Conflict{{Path: "c.env", Action: ConflictActionOverwrite}},
+			},
+			wantRemaining: []string{"a.env", "b.env", "c.env", "gone.env", "touched.env"},
+		},
+		"success: delete": {
+			opts: Options{PreservePaths: true, Sync: true, Delete: true},
+			wantResult: Result{
+				CopiedFiles: []string{"a.env", "c.env", "touched.env"},
+				Added:       []string{"a.env"},
+				Updated:     []string{"c.env", "touched.env"},
+				Unchanged:   []string{"b.env"},
+				Skipped:     []string{"secret.env"},
+				Conflicts: []Conflict{
+					{Path: "c.env", Action: ConflictActionOverwrite},
+					{Path: "touched.env", Action: ConflictActionOverwrite},
+					{Path: "gone.env", Action: ConflictActionDelete, Stale: true},
+				},
+				Deleted: []string{"gone.env"},
+			},
+			wantRemaining: []string{"a.env", "b.env", "c.env", "touched.env"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			srcRoot := t.TempDir()
+			write(t, filepath.Join(srcRoot, "a.env"), "a\n", old)
+			write(t, filepath.Join(srcRoot, "b.env"), "b\n", old)
+			write(t, filepath.Join(srcRoot, "c.env"), "c2\n", old)
+			write(t, filepath.Join(srcRoot, "touched.env"), "t\n", newer)
+			write(t, filepath.Join(srcRoot, "secret.env"), "s\n", old)
+
+			dstRoot := t.TempDir()
+			write(t, filepath.Join(dstRoot, "b.env"), "b\n", old)
+			write(t, filepath.Join(dstRoot, "c.env"), "c\n", old)
+			write(t, filepath.Join(dstRoot, "touched.env"), "t\n", old)
+			write(t, filepath.Join(dstRoot, "gone.env"), "g\n", old)
+
+			got, err := CopyFiles(t.Context(), srcRoot, dstRoot, []string{"*.env"}, []string{"secret.env"}, tc.opts)
+			if err != nil {
+				t.Fatalf("CopyFiles() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantResult, got, cmpopts.IgnoreFields(Result{}, "Modes"), cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Fatalf("result mismatch (-want +got):\n%s", diff)
+			}
+
+			entries, err := os.ReadDir(dstRoot)
+			if err != nil {
+				t.Fatalf("ReadDir: %v", err)
+			}
+			var remaining []string
+			for _, e := range entries {
+				remaining = append(remaining, e.Name())
+			}
+			if diff := cmp.Diff(tc.wantRemaining, remaining); diff != "" {
+				t.Fatalf("destination files mismatch (-want +got):\n%s", diff)
+			}
+
+			// A second sync finds nothing to do.
+			again, err := CopyFiles(t.Context(), srcRoot, dstRoot, []string{"*.env"}, []string{"secret.env"}, tc.opts)
+			if err != nil {
+				t.Fatalf("CopyFiles() error: %v", err)
+			}
+			if len(again.CopiedFiles) != 0 {
+				t.Fatalf("second sync copied %v, want nothing", again.CopiedFiles)
+			}
+		})
+	}
+}
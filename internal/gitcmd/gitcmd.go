@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -73,9 +74,15 @@ func (e *ExitError) Error() string {
 
 // Run executes `git` with args in dir and returns captured output.
 func (g Git) Run(ctx context.Context, dir string, args ...string) (Result, error) {
+	return g.RunWithInput(ctx, dir, nil, args...)
+}
+
+// RunWithInput is like Run but connects stdin to the command's standard input.
+func (g Git) RunWithInput(ctx context.Context, dir string, stdin io.Reader, args ...string) (Result, error) {
 	cmd := exec.CommandContext(ctx, g.Path, args...) //nolint:gosec // This is an intentional wrapper around the system `git`.
 	cmd.Dir = dir
 	cmd.Env = append(os.Environ(), g.Env...)
+	cmd.Stdin = stdin
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
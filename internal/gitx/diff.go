@@ -0,0 +1,140 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitx
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zchee/git-worktree-runner/internal/gitcmd"
+)
+
+// DiffFile summarizes the changes to one file in a diff.
+type DiffFile struct {
+	Path string
+	// OldPath is the source path of a rename or copy.
+	OldPath string
+	// Status is the git status letter: A, C, D, M, R or T.
+	Status    string
+	Additions int
+	Deletions int
+	Binary    bool
+}
+
+// DiffFilesGit summarizes `git diff <args...>` run in dir, one entry per changed file.
+func DiffFilesGit(ctx context.Context, g gitcmd.Git, dir string, args ...string) ([]DiffFile, error) {
+	nameStatus, err := g.Run(ctx, dir, append([]string{"diff", "--no-ext-diff", "--name-status", "-z"}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("git diff --name-status: %w", err)
+	}
+	files, err := parseNameStatus(nameStatus.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	numstat, err := g.Run(ctx, dir, append([]string{"diff", "--no-ext-diff", "--numstat", "-z"}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("git diff --numstat: %w", err)
+	}
+	if err := applyNumstat(files, numstat.Stdout); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// parseNameStatus parses `git diff --name-status -z` output.
+func parseNameStatus(out string) ([]DiffFile, error) {
+	fields := splitNUL(out)
+
+	var files []DiffFile
+	for i := 0; i < len(fields); {
+		status := fields[i]
+		if status == "" {
+			return nil, fmt.Errorf("parse git diff --name-status: empty status")
+		}
+		f := DiffFile{Status: status[:1]}
+		switch f.Status {
+		case "R", "C":
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("parse git diff --name-status: truncated %s entry", status)
+			}
+			f.OldPath, f.Path = fields[i+1], fields[i+2]
+			i += 3
+		default:
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("parse git diff --name-status: truncated %s entry", status)
+			}
+			f.Path = fields[i+1]
+			i += 2
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// applyNumstat fills the line counts of files from `git diff --numstat -z` output.
+func applyNumstat(files []DiffFile, out string) error {
+	byPath := make(map[string]*DiffFile, len(files))
+	for i := range files {
+		byPath[files[i].Path] = &files[i]
+	}
+
+	fields := splitNUL(out)
+	for i := 0; i < len(fields); {
+		counts := strings.SplitN(fields[i], "\t", 3)
+		if len(counts) != 3 {
+			return fmt.Errorf("parse git diff --numstat: malformed entry %q", fields[i])
+		}
+		path := counts[2]
+		i++
+		if path == "" {
+			// Renames and copies list the old and the new path as separate fields.
+			if i+1 >= len(fields) {
+				return fmt.Errorf("parse git diff --numstat: truncated rename entry")
+			}
+			path = fields[i+1]
+			i += 2
+		}
+
+		f, ok := byPath[path]
+		if !ok {
+			continue
+		}
+		if counts[0] == "-" && counts[1] == "-" {
+			f.Binary = true
+			continue
+		}
+		var err error
+		if f.Additions, err = strconv.Atoi(counts[0]); err != nil {
+			return fmt.Errorf("parse git diff --numstat: %w", err)
+		}
+		if f.Deletions, err = strconv.Atoi(counts[1]); err != nil {
+			return fmt.Errorf("parse git diff --numstat: %w", err)
+		}
+	}
+	return nil
+}
+
+func splitNUL(out string) []string {
+	out = strings.TrimSuffix(out, "\x00")
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\x00")
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+)
+
+func TestParseNameStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		out     string
+		want    []DiffFile
+		wantErr bool
+	}{
+		"success: empty": {
+			out:  "",
+			want: nil,
+		},
+		"success: modifications and renames": {
+			out: "M\x00a.txt\x00R087\x00old.txt\x00new.txt\x00D\x00gone.txt\x00",
+			want: []DiffFile{
+				{Path: "a.txt", Status: "M"},
+				{Path: "new.txt", OldPath: "old.txt", Status: "R"},
+				{Path: "gone.txt", Status: "D"},
+			},
+		},
+		"error: truncated rename": {
+			out:     "R100\x00old.txt\x00",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseNameStatus(tc.out)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil (files=%+v)", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNameStatus() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffFilesGit(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	if _, err := g.Run(t.Context(), repoDir, "branch", "base"); err != nil {
+		t.Fatalf("git branch base: %v", err)
+	}
+	files := map[string][]byte{
+		"README.md": []byte("changed\nmore\n"),
+		"bin.dat":   {0, 1, 2, 3},
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repoDir, name), content, 0o644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+	if _, err := g.Run(t.Context(), repoDir, "add", "."); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "commit", "-m", "change"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+
+	got, err := DiffFilesGit(t.Context(), g, repoDir, "base", "HEAD")
+	if err != nil {
+		t.Fatalf("DiffFilesGit() error: %v", err)
+	}
+	want := []DiffFile{
+		{Path: "README.md", Status: "M", Additions: 2, Deletions: 1},
+		{Path: "bin.dat", Status: "A", Binary: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("files mismatch (-want +got):\n%s", diff)
+	}
+}
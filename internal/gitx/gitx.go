@@ -122,3 +122,26 @@ func DefaultBranchAuto(repo *git.Repository) (string, error) {
 
 	return "main", nil
 }
+
+// BranchesGit returns the local branch names followed by the remote-tracking branch names
+// (e.g. "origin/main"), omitting symbolic refs such as origin/HEAD.
+func BranchesGit(ctx context.Context, g gitcmd.Git, dir string) ([]string, error) {
+	res, err := g.Run(ctx, dir, "for-each-ref", "--format=%(refname)%00%(symref)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+
+	var out []string
+	for line := range strings.Lines(res.Stdout) {
+		ref, symref, _ := strings.Cut(strings.TrimSuffix(line, "\n"), "\x00")
+		if ref == "" || symref != "" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			out = append(out, name)
+		} else if name, ok := strings.CutPrefix(ref, "refs/remotes/"); ok {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
@@ -95,6 +95,37 @@ func TestCurrentBranchGit(t *testing.T) {
 	}
 }
 
+func TestBranchesGit(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	mainBranch, err := CurrentBranchGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("CurrentBranchGit() error: %v", err)
+	}
+	for _, args := range [][]string{
+		{"branch", "feature/a"},
+		{"update-ref", "refs/remotes/origin/feature/b", "HEAD"},
+		{"symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/feature/b"},
+	} {
+		if _, err := g.Run(t.Context(), repoDir, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	got, err := BranchesGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("BranchesGit() error: %v", err)
+	}
+	want := []string{"feature/a", mainBranch, "origin/feature/b"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("branches mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestCurrentBranchGitDetached(t *testing.T) {
 	t.Parallel()
 
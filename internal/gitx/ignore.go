@@ -0,0 +1,85 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zchee/git-worktree-runner/internal/gitcmd"
+)
+
+// IgnoredGit returns the subset of paths (relative to dir) that git ignores in dir.
+//
+// Tracked files are never reported, even when an ignore rule matches them.
+func IgnoredGit(ctx context.Context, g gitcmd.Git, dir string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	var in strings.Builder
+	for _, p := range paths {
+		in.WriteString(p)
+		in.WriteByte(0)
+	}
+
+	res, err := g.RunWithInput(ctx, dir, strings.NewReader(in.String()), "check-ignore", "--stdin", "-z")
+	if err != nil {
+		// check-ignore exits with 1 when none of the paths are ignored.
+		if exitErr := new(gitcmd.ExitError); errors.As(err, &exitErr) && exitErr.ExitCode == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("git check-ignore: %w", err)
+	}
+
+	var out []string
+	for p := range strings.SplitSeq(res.Stdout, "\x00") {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// TrackedGit returns the subset of paths (relative to dir) that are in the index of the worktree at dir,
+// in the order of paths. Files staged for addition count as tracked.
+func TrackedGit(ctx context.Context, g gitcmd.Git, dir string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	res, err := g.Run(ctx, dir, "ls-files", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", err)
+	}
+	tracked := map[string]bool{}
+	for p := range strings.SplitSeq(res.Stdout, "\x00") {
+		if p != "" {
+			tracked[p] = true
+		}
+	}
+
+	var out []string
+	for _, p := range paths {
+		if tracked[p] {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+)
+
+func TestIgnoredGit(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	files := map[string]string{
+		".gitignore":        "node_modules/\n*.log\n",
+		"node_modules/a.js": "a\n",
+		"debug.log":         "log\n",
+		"tracked.log":       "log\n",
+		"notes.txt":         "notes\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(repoDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+	if _, err := g.Run(t.Context(), repoDir, "add", "-f", "tracked.log"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+
+	got, err := IgnoredGit(t.Context(), g, repoDir, []string{"node_modules/a.js", "debug.log", "tracked.log", "notes.txt"})
+	if err != nil {
+		t.Fatalf("IgnoredGit() error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"node_modules/a.js", "debug.log"}, got); diff != "" {
+		t.Fatalf("ignored mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = IgnoredGit(t.Context(), g, repoDir, []string{"notes.txt"})
+	if err != nil {
+		t.Fatalf("IgnoredGit() error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no ignored paths, got %v", got)
+	}
+}
+
+func TestTrackedGit(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	for _, name := range []string{"config/staged.env", "config/local.env"} {
+		p := filepath.Join(repoDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(name+"\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+	if _, err := g.Run(t.Context(), repoDir, "add", "config/staged.env"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+
+	got, err := TrackedGit(t.Context(), g, repoDir, []string{"config/local.env", "config/staged.env", "README.md"})
+	if err != nil {
+		t.Fatalf("TrackedGit() error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"config/staged.env", "README.md"}, got); diff != "" {
+		t.Fatalf("tracked mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/internal/gitcmd"
+)
+
+// Status summarizes `git status --porcelain=v2 --branch` for a worktree.
+type Status struct {
+	// Head is the commit SHA of HEAD, or empty for an unborn branch.
+	Head string
+	// Upstream is the configured upstream branch (for example "origin/main"), or empty.
+	Upstream string
+	// Ahead and Behind count commits relative to Upstream. Both are zero when there is no upstream.
+	Ahead  int
+	Behind int
+
+	Staged     int
+	Unstaged   int
+	Untracked  int
+	Conflicted int
+}
+
+// Dirty reports whether the worktree has any staged, unstaged, untracked or conflicted files.
+func (s Status) Dirty() bool {
+	return s.Staged+s.Unstaged+s.Untracked+s.Conflicted > 0
+}
+
+// StatusGit returns the status of the worktree at dir by asking the `git` binary.
+func StatusGit(ctx context.Context, g gitcmd.Git, dir string) (Status, error) {
+	res, err := g.Run(ctx, dir, "status", "--porcelain=v2", "--branch", "--untracked-files=normal")
+	if err != nil {
+		return Status{}, fmt.Errorf("git status --porcelain=v2: %w", err)
+	}
+	return parseStatus(res.Stdout)
+}
+
+func parseStatus(out string) (Status, error) {
+	var st Status
+	for line := range strings.SplitSeq(out, "\n") {
+		if line == "" {
+			continue
+		}
+
+		if header, ok := strings.CutPrefix(line, "# "); ok {
+			key, value, _ := strings.Cut(header, " ")
+			switch key {
+			case "branch.oid":
+				if value != "(initial)" {
+					st.Head = value
+				}
+			case "branch.upstream":
+				st.Upstream = value
+			case "branch.ab":
+				ahead, behind, ok := strings.Cut(value, " ")
+				if !ok {
+					return Status{}, fmt.Errorf("malformed branch.ab header: %q", line)
+				}
+				var err error
+				if st.Ahead, err = strconv.Atoi(strings.TrimPrefix(ahead, "+")); err != nil {
+					return Status{}, fmt.Errorf("malformed branch.ab header: %q: %w", line, err)
+				}
+				if st.Behind, err = strconv.Atoi(strings.TrimPrefix(behind, "-")); err != nil {
+					return Status{}, fmt.Errorf("malformed branch.ab header: %q: %w", line, err)
+				}
+			}
+			continue
+		}
+
+		switch line[0] {
+		case '1', '2':
+			// "1 XY ..." (changed) or "2 XY ..." (renamed/copied).
+			if len(line) < 4 {
+				return Status{}, fmt.Errorf("malformed status entry: %q", line)
+			}
+			if line[2] != '.' {
+				st.Staged++
+			}
+			if line[3] != '.' {
+				st.Unstaged++
+			}
+		case 'u':
+			st.Conflicted++
+		case '?':
+			st.Untracked++
+		}
+	}
+	return st, nil
+}
+
+// LastCommitTimeGit returns the committer time of HEAD in dir.
+//
+// It returns the zero time when HEAD does not point to a commit yet (unborn branch).
+func LastCommitTimeGit(ctx context.Context, g gitcmd.Git, dir string) (time.Time, error) {
+	res, err := g.Run(ctx, dir, "log", "-1", "--format=%ct")
+	if err != nil {
+		var ee *gitcmd.ExitError
+		if errors.As(err, &ee) && strings.Contains(ee.Stderr, "does not have any commits") {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("git log -1: %w", err)
+	}
+
+	raw := strings.TrimSpace(res.Stdout)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	sec, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse commit time %q: %w", raw, err)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// BranchStartGit returns the oldest commit recorded in the reflog of branch, which is the commit the branch
+// was created at unless the reflog has been expired. It returns "" when the branch has no reflog.
+func BranchStartGit(ctx context.Context, g gitcmd.Git, dir, branch string) (string, error) {
+	res, err := g.Run(ctx, dir, "log", "--walk-reflogs", "--format=%H", "refs/heads/"+branch, "--")
+	if err != nil {
+		return "", fmt.Errorf("git log --walk-reflogs %s: %w", branch, err)
+	}
+	lines := strings.Fields(res.Stdout)
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return lines[len(lines)-1], nil
+}
+
+// ownCommitActions are the reflog subject prefixes of operations that make new commits on a branch, as opposed
+// to creating, resetting or fast-forwarding it to commits made elsewhere.
+var ownCommitActions = []string{"commit", "cherry-pick", "revert", "rebase"}
+
+// BranchOwnCommitsGit returns the commits that the reflog of branch records as made on the branch itself by
+// commit, cherry-pick, revert or rebase, newest first. It returns nil when the branch has no reflog.
+func BranchOwnCommitsGit(ctx context.Context, g gitcmd.Git, dir, branch string) ([]string, error) {
+	res, err := g.Run(ctx, dir, "log", "--walk-reflogs", "--format=%H %gs", "refs/heads/"+branch, "--")
+	if err != nil {
+		return nil, fmt.Errorf("git log --walk-reflogs %s: %w", branch, err)
+	}
+	var out []string
+	for line := range strings.Lines(res.Stdout) {
+		hash, subject, _ := strings.Cut(strings.TrimSuffix(line, "\n"), " ")
+		for _, action := range ownCommitActions {
+			if strings.HasPrefix(subject, action) {
+				out = append(out, hash)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// AheadBehindGit counts commits reachable from head but not base (ahead) and from base but not head (behind).
+func AheadBehindGit(ctx context.Context, g gitcmd.Git, dir, base, head string) (ahead, behind int, err error) {
+	res, err := g.Run(ctx, dir, "rev-list", "--left-right", "--count", base+"..."+head)
+	if err != nil {
+		return 0, 0, fmt.Errorf("git rev-list --left-right --count %s...%s: %w", base, head, err)
+	}
+
+	left, right, ok := strings.Cut(strings.TrimSpace(res.Stdout), "\t")
+	if !ok {
+		return 0, 0, fmt.Errorf("malformed rev-list output: %q", res.Stdout)
+	}
+	if behind, err = strconv.Atoi(left); err != nil {
+		return 0, 0, fmt.Errorf("malformed rev-list output: %q: %w", res.Stdout, err)
+	}
+	if ahead, err = strconv.Atoi(right); err != nil {
+		return 0, 0, fmt.Errorf("malformed rev-list output: %q: %w", res.Stdout, err)
+	}
+	return ahead, behind, nil
+}
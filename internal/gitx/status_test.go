@@ -0,0 +1,282 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gitx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+)
+
+func TestParseStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		out     string
+		want    Status
+		wantErr bool
+	}{
+		"success: clean branch with upstream": {
+			out: "# branch.oid 0123abcd\n# branch.head main\n# branch.upstream origin/main\n# branch.ab +2 -3\n",
+			want: Status{
+				Head:     "0123abcd",
+				Upstream: "origin/main",
+				Ahead:    2,
+				Behind:   3,
+			},
+		},
+		"success: unborn branch": {
+			out:  "# branch.oid (initial)\n# branch.head main\n",
+			want: Status{},
+		},
+		"success: counts entries": {
+			out: "# branch.oid 0123abcd\n# branch.head main\n" +
+				"1 M. N... 100644 100644 100644 aaa bbb staged.txt\n" +
+				"1 .M N... 100644 100644 100644 aaa bbb unstaged.txt\n" +
+				"1 MM N... 100644 100644 100644 aaa bbb both.txt\n" +
+				"2 R. N... 100644 100644 100644 aaa bbb R100 new.txt\told.txt\n" +
+				"u UU N... 100644 100644 100644 100644 aaa bbb ccc conflict.txt\n" +
+				"? untracked.txt\n",
+			want: Status{
+				Head:       "0123abcd",
+				Staged:     3,
+				Unstaged:   2,
+				Untracked:  1,
+				Conflicted: 1,
+			},
+		},
+		"error: malformed branch.ab": {
+			out:     "# branch.ab nope\n",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseStatus(tc.out)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil (status=%+v)", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStatus() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("status mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStatusGit(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("changed\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(README.md): %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "new.txt"), []byte("new\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(new.txt): %v", err)
+	}
+
+	got, err := StatusGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("StatusGit() error: %v", err)
+	}
+	if got.Head == "" {
+		t.Fatalf("expected HEAD SHA, got %+v", got)
+	}
+	if diff := cmp.Diff(1, got.Unstaged); diff != "" {
+		t.Fatalf("unstaged mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(1, got.Untracked); diff != "" {
+		t.Fatalf("untracked mismatch (-want +got):\n%s", diff)
+	}
+	if !got.Dirty() {
+		t.Fatalf("expected dirty status, got %+v", got)
+	}
+}
+
+func TestLastCommitTimeGit(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	got, err := LastCommitTimeGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("LastCommitTimeGit() error: %v", err)
+	}
+	if got.IsZero() {
+		t.Fatalf("expected non-zero commit time")
+	}
+
+	emptyDir := filepath.Join(t.TempDir(), "empty")
+	if err := os.MkdirAll(emptyDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll(%q): %v", emptyDir, err)
+	}
+	if _, err := g.Run(t.Context(), emptyDir, "init"); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+
+	got, err = LastCommitTimeGit(t.Context(), g, emptyDir)
+	if err != nil {
+		t.Fatalf("LastCommitTimeGit(unborn) error: %v", err)
+	}
+	if !got.IsZero() {
+		t.Fatalf("expected zero commit time for unborn branch, got %v", got)
+	}
+}
+
+func TestBranchStartGit(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	start, err := g.Run(t.Context(), repoDir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("git rev-parse HEAD: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "branch", "feature"); err != nil {
+		t.Fatalf("git branch feature: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "-c", "core.logAllRefUpdates=false", "branch", "nolog"); err != nil {
+		t.Fatalf("git branch nolog: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "switch", "feature"); err != nil {
+		t.Fatalf("git switch feature: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "commit", "--allow-empty", "-m", "feature"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+
+	got, err := BranchStartGit(t.Context(), g, repoDir, "feature")
+	if err != nil {
+		t.Fatalf("BranchStartGit() error: %v", err)
+	}
+	if diff := cmp.Diff(strings.TrimSpace(start.Stdout), got); diff != "" {
+		t.Fatalf("branch start mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = BranchStartGit(t.Context(), g, repoDir, "nolog")
+	if err != nil {
+		t.Fatalf("BranchStartGit(nolog) error: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("expected no start for a branch without reflog, got %q", got)
+	}
+}
+
+func TestBranchOwnCommitsGit(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	for _, args := range [][]string{
+		{"branch", "forwarded"},
+		{"switch", "-c", "feature"},
+		{"commit", "--allow-empty", "-m", "feature"},
+	} {
+		if _, err := g.Run(t.Context(), repoDir, args...); err != nil {
+			t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+		}
+	}
+	head, err := g.Run(t.Context(), repoDir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("git rev-parse HEAD: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "branch", "-f", "forwarded", "feature"); err != nil {
+		t.Fatalf("git branch -f forwarded: %v", err)
+	}
+
+	tests := map[string]struct {
+		branch string
+		want   []string
+	}{
+		"success: commit made on the branch": {
+			branch: "feature",
+			want:   []string{strings.TrimSpace(head.Stdout)},
+		},
+		"success: branch only moved to other commits": {
+			branch: "forwarded",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := BranchOwnCommitsGit(t.Context(), g, repoDir, tc.branch)
+			if err != nil {
+				t.Fatalf("BranchOwnCommitsGit() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("own commits mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAheadBehindGit(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	testutil.InitRepo(t, g, repoDir)
+
+	if _, err := g.Run(t.Context(), repoDir, "branch", "base"); err != nil {
+		t.Fatalf("git branch base: %v", err)
+	}
+	for _, msg := range []string{"one", "two"} {
+		if _, err := g.Run(t.Context(), repoDir, "commit", "--allow-empty", "-m", msg); err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+	}
+
+	ahead, behind, err := AheadBehindGit(t.Context(), g, repoDir, "base", "HEAD")
+	if err != nil {
+		t.Fatalf("AheadBehindGit() error: %v", err)
+	}
+	if diff := cmp.Diff([2]int{2, 0}, [2]int{ahead, behind}); diff != "" {
+		t.Fatalf("ahead/behind mismatch (-want +got):\n%s", diff)
+	}
+
+	ahead, behind, err = AheadBehindGit(t.Context(), g, repoDir, "HEAD", "base")
+	if err != nil {
+		t.Fatalf("AheadBehindGit() error: %v", err)
+	}
+	if diff := cmp.Diff([2]int{0, 2}, [2]int{ahead, behind}); diff != "" {
+		t.Fatalf("ahead/behind mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -25,15 +25,45 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"sync"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/internal/lineprefix"
 )
 
 // ErrHookFailed is returned when a hook command exits non-zero.
 var ErrHookFailed = errors.New("hook failed")
 
+// Hook phases. Each phase is configured via the multi-valued `wr.hook.<phase>` key
+// (falling back to `hooks.<phase>` in .wrconfig).
+//
+// A failing pre* hook aborts the operation before git is invoked; post* hooks run after it succeeded.
+const (
+	PhasePreCreate  = "preCreate"
+	PhasePostCreate = "postCreate"
+	PhasePreRemove  = "preRemove"
+	PhasePostRemove = "postRemove"
+)
+
+// waitDelay bounds how long Run waits for output pipes to close after a hook was killed.
+const waitDelay = 5 * time.Second
+
 // Options configures hook execution output.
 type Options struct {
+	// Stdout and Stderr receive hook output, each line prefixed with "[hook <phase> #<n>] ".
+	// Nil discards the output.
 	Stdout io.Writer
 	Stderr io.Writer
+
+	// Verbose writes each hook command to Stderr before running it.
+	Verbose bool
+
+	// Timeout limits how long a single hook may run. When it expires the hook's whole process group is killed.
+	// Zero means no timeout.
+	Timeout time.Duration
+
+	// OnStart, if non-nil, is called before each hook command runs. index starts at 1.
+	OnStart func(phase string, index int, command string)
 }
 
 // HookError reports a failing hook.
@@ -43,9 +73,17 @@ type HookError struct {
 	Command  string
 	ExitCode int
 	Stderr   string
+
+	// TimedOut reports whether the hook was killed because it exceeded Options.Timeout.
+	// ExitCode is -1 in that case.
+	TimedOut bool
+	Timeout  time.Duration
 }
 
 func (e *HookError) Error() string {
+	if e.TimedOut {
+		return fmt.Sprintf("%s hook %d timed out after %s: %s", e.Phase, e.Index, e.Timeout, e.Command)
+	}
 	return fmt.Sprintf("%s hook %d failed (exit %d): %s", e.Phase, e.Index, e.ExitCode, e.Command)
 }
 
@@ -70,37 +108,78 @@ func Run(ctx context.Context, phase, dir string, hooks, env []string, opts Optio
 		if hook == "" {
 			continue
 		}
-
-		cmd, err := shellCommand(ctx, hook)
-		if err != nil {
+		if err := runOne(ctx, phase, i+1, dir, hook, env, stdout, stderr, opts); err != nil {
 			return err
 		}
-		cmd.Dir = dir
-		cmd.Env = append(os.Environ(), env...)
+	}
 
-		var hookStderr bytes.Buffer
-		cmd.Stdout = stdout
-		cmd.Stderr = io.MultiWriter(stderr, &hookStderr)
+	return nil
+}
 
-		// Hook execution is explicitly user-configured and uses the system shell.
-		if err := cmd.Run(); err == nil { //nolint:gosec
-			continue
-		} else {
-			var exitErr *exec.ExitError
-			if errors.As(err, &exitErr) {
-				return &HookError{
-					Phase:    phase,
-					Index:    i + 1,
-					Command:  hook,
-					ExitCode: exitErr.ExitCode(),
-					Stderr:   hookStderr.String(),
-				}
-			}
-			return err
+func runOne(ctx context.Context, phase string, index int, dir, hook string, env []string, stdout, stderr io.Writer, opts Options) error {
+	hookCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		hookCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cmd, err := shellCommand(hookCtx, hook)
+	if err != nil {
+		return err
+	}
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	setProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+
+	var mu sync.Mutex
+	prefix := fmt.Sprintf("[hook %s #%d] ", phase, index)
+	out := lineprefix.NewWriter(&mu, stdout, prefix)
+	errOut := lineprefix.NewWriter(&mu, stderr, prefix)
+	defer out.Flush()
+	defer errOut.Flush()
+
+	if opts.OnStart != nil {
+		opts.OnStart(phase, index, hook)
+	}
+	if opts.Verbose {
+		fmt.Fprintf(errOut, "$ %s\n", hook)
+	}
+
+	var hookStderr bytes.Buffer
+	cmd.Stdout = out
+	cmd.Stderr = io.MultiWriter(errOut, &hookStderr)
+
+	// Hook execution is explicitly user-configured and uses the system shell.
+	err = cmd.Run() //nolint:gosec
+	if err == nil {
+		return nil
+	}
+
+	if opts.Timeout > 0 && errors.Is(hookCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return &HookError{
+			Phase:    phase,
+			Index:    index,
+			Command:  hook,
+			ExitCode: -1,
+			Stderr:   hookStderr.String(),
+			TimedOut: true,
+			Timeout:  opts.Timeout,
 		}
 	}
 
-	return nil
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &HookError{
+			Phase:    phase,
+			Index:    index,
+			Command:  hook,
+			ExitCode: exitErr.ExitCode(),
+			Stderr:   hookStderr.String(),
+		}
+	}
+	return err
 }
 
 func shellCommand(ctx context.Context, script string) (*exec.Cmd, error) {
@@ -22,6 +22,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -103,3 +104,69 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestRunStreamsPrefixedOutput(t *testing.T) {
+	t.Parallel()
+
+	hooks := []string{"echo one", "echo two 1>&2"}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	err := Run(t.Context(), PhasePostCreate, t.TempDir(), hooks, nil, Options{
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+		Verbose: true,
+	})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if diff := cmp.Diff("[hook postCreate #1] one\n", strings.ReplaceAll(stdout.String(), "\r", "")); diff != "" {
+		t.Fatalf("stdout mismatch (-want +got):\n%s", diff)
+	}
+	gotErr := strings.ReplaceAll(stderr.String(), "\r", "")
+	for _, want := range []string{
+		"[hook postCreate #1] $ echo one\n",
+		"[hook postCreate #2] $ echo two 1>&2\n",
+		"[hook postCreate #2] two",
+	} {
+		if !strings.Contains(gotErr, want) {
+			t.Fatalf("stderr mismatch: expected %q in %q", want, gotErr)
+		}
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process group kill is not supported on windows")
+	}
+	t.Parallel()
+
+	// The shell forks sleep, which keeps the output pipe open unless the whole process group is killed.
+	hook := "sleep 30; echo done"
+
+	start := time.Now()
+	var stdout bytes.Buffer
+	err := Run(t.Context(), PhasePreRemove, t.TempDir(), []string{hook}, nil, Options{
+		Stdout:  &stdout,
+		Timeout: 200 * time.Millisecond,
+	})
+	elapsed := time.Since(start)
+
+	var he *HookError
+	if !errors.As(err, &he) {
+		t.Fatalf("expected *HookError, got %v", err)
+	}
+	if !he.TimedOut {
+		t.Fatalf("expected TimedOut, got %+v", he)
+	}
+	if diff := cmp.Diff(-1, he.ExitCode); diff != "" {
+		t.Fatalf("exit code mismatch (-want +got):\n%s", diff)
+	}
+	if elapsed >= waitDelay {
+		t.Fatalf("expected the process group to be killed promptly, took %s", elapsed)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("expected no output from killed hook, got %q", stdout.String())
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package lineprefix
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Writer writes complete lines to an underlying writer, each prefixed with a fixed prefix.
+//
+// The mutex passed to NewWriter is shared by every Writer targeting the same destinations so that
+// lines from concurrent commands are never interleaved mid-line.
+type Writer struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+// NewWriter returns a Writer that writes prefixed lines to w while holding mu.
+func NewWriter(mu *sync.Mutex, w io.Writer, prefix string) *Writer {
+	return &Writer{mu: mu, w: w, prefix: prefix}
+}
+
+// Write buffers b and writes every complete line. It never returns an error.
+func (p *Writer) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		p.writeLine(p.buf[:i+1])
+		p.buf = p.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush writes any buffered partial line, terminated with a newline.
+func (p *Writer) Flush() {
+	if len(p.buf) == 0 {
+		return
+	}
+	p.writeLine(append(p.buf, '\n'))
+	p.buf = nil
+}
+
+func (p *Writer) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = io.WriteString(p.w, p.prefix)
+	_, _ = p.w.Write(line)
+}
@@ -16,7 +16,53 @@
 
 package naming
 
-import "strings"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidScheme is returned by ParseScheme for unknown naming schemes.
+var ErrInvalidScheme = errors.New("invalid naming scheme")
+
+// Scheme selects how branch names are turned into directory names (wr.worktrees.naming).
+type Scheme string
+
+const (
+	// SchemeSanitize replaces characters that are unsafe in paths with '-' (see SanitizeBranchName).
+	// Different branches can map to the same name, in which case the second worktree cannot be created.
+	SchemeSanitize Scheme = "sanitize"
+	// SchemeHash sanitizes like SchemeSanitize; callers append a short hash of the branch (see Disambiguate)
+	// when the name is already taken by another branch.
+	SchemeHash Scheme = "hash"
+	// SchemeEscape percent-encodes unsafe characters so that the branch can be recovered from the name
+	// (see UnescapeBranchName). Names that still collide, for example on case-insensitive file systems,
+	// are disambiguated as with SchemeHash.
+	SchemeEscape Scheme = "escape"
+)
+
+// MaxNameLength is the maximum length in bytes of a name returned by DirName and Disambiguate.
+// It leaves room for a prefix and a `--name` suffix within the common 255-byte file name limit.
+const MaxNameLength = 128
+
+// hashLength is the number of hex digits used by Hash.
+const hashLength = 8
+
+// ParseScheme parses a wr.worktrees.naming value. The empty string selects SchemeSanitize.
+func ParseScheme(s string) (Scheme, error) {
+	switch Scheme(s) {
+	case "":
+		return SchemeSanitize, nil
+	case SchemeSanitize, SchemeHash, SchemeEscape:
+		return Scheme(s), nil
+	default:
+		return "", fmt.Errorf("%w: %q (want sanitize, hash or escape)", ErrInvalidScheme, s)
+	}
+}
 
 // SanitizeBranchName converts a branch name into a directory-friendly name.
 //
@@ -33,3 +79,119 @@ func SanitizeBranchName(branch string) string {
 
 	return strings.Trim(replaced, "-")
 }
+
+// DirName converts branch into a directory name using scheme.
+//
+// On top of the scheme it guarantees a usable name on every platform: the result is never empty, never
+// a Windows reserved device name such as CON or LPT1, never ends in '.' or ' ', and is at most
+// MaxNameLength bytes long. Names that had to be shortened end with the hash of the branch.
+func DirName(branch string, scheme Scheme) string {
+	var name string
+	if scheme == SchemeEscape {
+		name = EscapeBranchName(branch)
+	} else {
+		name = strings.TrimRight(SanitizeBranchName(branch), ". ")
+		if isReserved(name) {
+			name += "_"
+		}
+	}
+
+	switch {
+	case name == "":
+		return Hash(branch)
+	case len(name) > MaxNameLength:
+		return truncate(name, MaxNameLength-hashLength-1) + "-" + Hash(branch)
+	default:
+		return name
+	}
+}
+
+// Disambiguate returns DirName(branch, scheme) followed by "-" and the hash of branch.
+func Disambiguate(branch string, scheme Scheme) string {
+	name := DirName(branch, scheme)
+	return truncate(name, MaxNameLength-hashLength-1) + "-" + Hash(branch)
+}
+
+// Hash returns a short, stable hex digest of branch.
+func Hash(branch string) string {
+	sum := sha256.Sum256([]byte(branch))
+	return hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// EscapeBranchName percent-encodes every byte of branch that is not safe in a portable file name.
+//
+// ASCII letters, digits, '-', '_' and '.', and non-ASCII letters, digits and marks are kept as is.
+// A leading or trailing '.', and the first character of a Windows reserved name, are encoded as well, so
+// the result is reversible with UnescapeBranchName unless DirName had to shorten it.
+func EscapeBranchName(branch string) string {
+	var b strings.Builder
+	for i, r := range branch {
+		keep := r == '-' || r == '_' || r == '.' ||
+			r < utf8.RuneSelf && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') ||
+			r >= utf8.RuneSelf && r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r))
+		if r == '.' && (i == 0 || i == len(branch)-1) {
+			keep = false
+		}
+		if i == 0 && isReserved(branch) {
+			keep = false
+		}
+		if keep {
+			b.WriteRune(r)
+			continue
+		}
+		size := utf8.RuneLen(r)
+		if r == utf8.RuneError {
+			_, size = utf8.DecodeRuneInString(branch[i:])
+		}
+		for _, c := range []byte(branch[i : i+size]) {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// UnescapeBranchName reverses EscapeBranchName.
+func UnescapeBranchName(name string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] != '%' {
+			b.WriteByte(name[i])
+			continue
+		}
+		if i+2 >= len(name) {
+			return "", fmt.Errorf("invalid escape at offset %d in %q", i, name)
+		}
+		c, err := hex.DecodeString(name[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("invalid escape at offset %d in %q", i, name)
+		}
+		b.Write(c)
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// isReserved reports whether name is a Windows reserved device name, with or without an extension.
+func isReserved(name string) bool {
+	base, _, _ := strings.Cut(name, ".")
+	base = strings.ToUpper(strings.TrimRight(base, " "))
+	switch base {
+	case "CON", "PRN", "AUX", "NUL":
+		return true
+	}
+	if len(base) == 4 && (strings.HasPrefix(base, "COM") || strings.HasPrefix(base, "LPT")) {
+		return base[3] >= '1' && base[3] <= '9'
+	}
+	return false
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return strings.TrimRight(s[:n], "-. ")
+}
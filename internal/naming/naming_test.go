@@ -17,6 +17,8 @@
 package naming
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -62,3 +64,128 @@ func TestSanitizeBranchName(t *testing.T) {
 		})
 	}
 }
+
+func TestDirName(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("ä", 100)
+
+	tests := map[string]struct {
+		branch string
+		scheme Scheme
+		want   string
+	}{
+		"success: sanitize keeps legacy names": {
+			branch: "feature/auth",
+			scheme: SchemeSanitize,
+			want:   "feature-auth",
+		},
+		"success: sanitize avoids reserved names": {
+			branch: "con",
+			scheme: SchemeSanitize,
+			want:   "con_",
+		},
+		"success: sanitize avoids reserved names with extension": {
+			branch: "LPT1.txt",
+			scheme: SchemeHash,
+			want:   "LPT1.txt_",
+		},
+		"success: sanitize trims trailing dots": {
+			branch: "release/1.",
+			scheme: SchemeSanitize,
+			want:   "release-1",
+		},
+		"success: empty name falls back to hash": {
+			branch: "///",
+			scheme: SchemeSanitize,
+			want:   Hash("///"),
+		},
+		"success: escape is distinct for colliding branches": {
+			branch: "feat/a",
+			scheme: SchemeEscape,
+			want:   "feat%2Fa",
+		},
+		"success: escape keeps hyphens": {
+			branch: "feat-a",
+			scheme: SchemeEscape,
+			want:   "feat-a",
+		},
+		"success: escape encodes reserved names": {
+			branch: "CON",
+			scheme: SchemeEscape,
+			want:   "%43ON",
+		},
+		"success: escape keeps unicode letters": {
+			branch: "föö/bär",
+			scheme: SchemeEscape,
+			want:   "föö%2Fbär",
+		},
+		"success: escape encodes leading dots and spaces": {
+			branch: ".hidden name",
+			scheme: SchemeEscape,
+			want:   "%2Ehidden%20name",
+		},
+		"success: overlong names are shortened on a rune boundary": {
+			branch: long,
+			scheme: SchemeSanitize,
+			want:   strings.Repeat("ä", 59) + "-" + Hash(long),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := DirName(tc.branch, tc.scheme)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("name mismatch (-want +got):\n%s", diff)
+			}
+			if len(got) > MaxNameLength {
+				t.Fatalf("name %q is longer than %d bytes", got, MaxNameLength)
+			}
+		})
+	}
+}
+
+func TestEscapeBranchNameRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, branch := range []string{"feat/a", "feat-a", "feat:a", "100%", "a b", "NUL", "x.", "日本語/ブランチ", "bad\xffbyte"} {
+		got, err := UnescapeBranchName(EscapeBranchName(branch))
+		if err != nil {
+			t.Fatalf("UnescapeBranchName(%q) error: %v", branch, err)
+		}
+		if diff := cmp.Diff(branch, got); diff != "" {
+			t.Fatalf("round trip mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	if _, err := UnescapeBranchName("bad%2"); err == nil {
+		t.Fatalf("expected error for truncated escape")
+	}
+}
+
+func TestDisambiguate(t *testing.T) {
+	t.Parallel()
+
+	names := map[string]string{}
+	for _, branch := range []string{"feat/a", "feat-a", "feat:a"} {
+		name := Disambiguate(branch, SchemeHash)
+		if other, ok := names[name]; ok {
+			t.Fatalf("%q and %q both map to %q", other, branch, name)
+		}
+		names[name] = branch
+	}
+}
+
+func TestParseScheme(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseScheme("")
+	if err != nil || got != SchemeSanitize {
+		t.Fatalf("ParseScheme(\"\") = %q, %v; want %q", got, err, SchemeSanitize)
+	}
+	if _, err := ParseScheme("nope"); !errors.Is(err, ErrInvalidScheme) {
+		t.Fatalf("expected %v, got %v", ErrInvalidScheme, err)
+	}
+}
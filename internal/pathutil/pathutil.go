@@ -60,3 +60,12 @@ func ExpandTilde(path string) (string, error) {
 	}
 	return path, nil
 }
+
+// IsWithin reports whether path is root or lies inside root. Both paths must be clean and absolute.
+func IsWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
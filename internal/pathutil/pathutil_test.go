@@ -99,3 +99,40 @@ func TestExpandTilde(t *testing.T) {
 		})
 	}
 }
+
+func TestIsWithin(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(string(filepath.Separator), "base")
+	tests := map[string]struct {
+		path string
+		want bool
+	}{
+		"success: same path": {
+			path: root,
+			want: true,
+		},
+		"success: nested path": {
+			path: filepath.Join(root, "a", "b"),
+			want: true,
+		},
+		"success: sibling with shared prefix": {
+			path: root + "-other",
+			want: false,
+		},
+		"success: parent": {
+			path: filepath.Dir(root),
+			want: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tc.want, IsWithin(root, tc.path)); diff != "" {
+				t.Fatalf("IsWithin mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/zchee/git-worktree-runner/internal/statefile"
+)
+
+// ErrExhausted is returned when no free port block fits below the highest TCP port.
+var ErrExhausted = errors.New("no free port block")
+
+// maxPort is the highest valid TCP port.
+const maxPort = 65535
+
+// stateVersion is the version of the persisted allocation file.
+const stateVersion = 1
+
+// Block is a contiguous range of Count TCP ports starting at Base.
+type Block struct {
+	Base  int `json:"base"`
+	Count int `json:"count"`
+}
+
+// IsZero reports whether b is the empty block (no ports assigned).
+func (b Block) IsZero() bool {
+	return b.Count == 0
+}
+
+// Ports returns every port in the block in ascending order.
+func (b Block) Ports() []int {
+	out := make([]int, 0, b.Count)
+	for i := range b.Count {
+		out = append(out, b.Base+i)
+	}
+	return out
+}
+
+// Env returns the block as KEY=VALUE pairs:
+// WR_PORT (the first port), WR_PORT_COUNT, and WR_PORT_0 .. WR_PORT_<Count-1>.
+//
+// It returns nil for the zero block.
+func (b Block) Env() []string {
+	if b.IsZero() {
+		return nil
+	}
+	env := []string{
+		"WR_PORT=" + strconv.Itoa(b.Base),
+		"WR_PORT_COUNT=" + strconv.Itoa(b.Count),
+	}
+	for i, p := range b.Ports() {
+		env = append(env, "WR_PORT_"+strconv.Itoa(i)+"="+strconv.Itoa(p))
+	}
+	return env
+}
+
+func (b Block) overlaps(o Block) bool {
+	return b.Base < o.Base+o.Count && o.Base < b.Base+b.Count
+}
+
+type state struct {
+	Version   int              `json:"version"`
+	Worktrees map[string]Block `json:"worktrees"`
+}
+
+// Store persists port blocks keyed by worktree path in a JSON file under the git common dir.
+//
+// Mutations are serialized with a dedicated lock file so that they can run while wr.lock is held.
+type Store struct {
+	file statefile.File
+}
+
+// NewStore returns a Store backed by <commonDir>/wr-ports.json.
+func NewStore(commonDir string) Store {
+	return Store{file: statefile.New(commonDir, "wr-ports", "port allocations")}
+}
+
+// Get returns the block assigned to worktreePath, if any.
+func (s Store) Get(worktreePath string) (Block, bool, error) {
+	st, err := s.load()
+	if err != nil {
+		return Block{}, false, err
+	}
+	b, ok := st.Worktrees[filepath.Clean(worktreePath)]
+	return b, ok, nil
+}
+
+// All returns every assigned block keyed by worktree path.
+func (s Store) All() (map[string]Block, error) {
+	st, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return st.Worktrees, nil
+}
+
+// Allocate returns the block assigned to worktreePath, assigning a new one when none exists or when
+// its size differs from count.
+//
+// New blocks start at base, are aligned to count, and never overlap the block of another worktree.
+func (s Store) Allocate(ctx context.Context, worktreePath string, base, count int) (Block, error) {
+	if count <= 0 {
+		return Block{}, fmt.Errorf("invalid port count %d", count)
+	}
+	key := filepath.Clean(worktreePath)
+
+	var out Block
+	err := s.update(ctx, func(st *state) (bool, error) {
+		if b, ok := st.Worktrees[key]; ok && b.Count == count {
+			out = b
+			return false, nil
+		}
+		delete(st.Worktrees, key)
+
+		taken := make([]Block, 0, len(st.Worktrees))
+		for _, b := range st.Worktrees {
+			taken = append(taken, b)
+		}
+
+		for candidate := (Block{Base: base, Count: count}); candidate.Base+count-1 <= maxPort; candidate.Base += count {
+			if slices.ContainsFunc(taken, candidate.overlaps) {
+				continue
+			}
+			st.Worktrees[key] = candidate
+			out = candidate
+			return true, nil
+		}
+		return false, fmt.Errorf("%w: %d ports starting at %d", ErrExhausted, count, base)
+	})
+	return out, err
+}
+
+// Release frees the block assigned to worktreePath. Releasing an unassigned path is a no-op.
+func (s Store) Release(ctx context.Context, worktreePath string) error {
+	key := filepath.Clean(worktreePath)
+	return s.update(ctx, func(st *state) (bool, error) {
+		if _, ok := st.Worktrees[key]; !ok {
+			return false, nil
+		}
+		delete(st.Worktrees, key)
+		return true, nil
+	})
+}
+
+// Prune frees the blocks of every worktree path for which keep returns false.
+func (s Store) Prune(ctx context.Context, keep func(worktreePath string) bool) error {
+	return s.update(ctx, func(st *state) (bool, error) {
+		changed := false
+		for key := range st.Worktrees {
+			if !keep(key) {
+				delete(st.Worktrees, key)
+				changed = true
+			}
+		}
+		return changed, nil
+	})
+}
+
+func (s Store) update(ctx context.Context, fn func(st *state) (changed bool, err error)) error {
+	return s.file.Update(ctx, func() error {
+		st, err := s.load()
+		if err != nil {
+			return err
+		}
+		changed, err := fn(&st)
+		if err != nil || !changed {
+			return err
+		}
+		return s.file.Save(st)
+	})
+}
+
+func (s Store) load() (state, error) {
+	st := state{Version: stateVersion, Worktrees: map[string]Block{}}
+	if err := s.file.Load(&st); err != nil {
+		return state{}, err
+	}
+	if st.Version != stateVersion {
+		return state{}, fmt.Errorf("unsupported port allocation version %d in %q", st.Version, s.file.Path())
+	}
+	if st.Worktrees == nil {
+		st.Worktrees = map[string]Block{}
+	}
+	return st, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ports
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStoreAllocate(t *testing.T) {
+	t.Parallel()
+
+	s := NewStore(t.TempDir())
+
+	a, err := s.Allocate(t.Context(), "/wt/a", 4000, 10)
+	if err != nil {
+		t.Fatalf("Allocate(a) error: %v", err)
+	}
+	b, err := s.Allocate(t.Context(), "/wt/b", 4000, 10)
+	if err != nil {
+		t.Fatalf("Allocate(b) error: %v", err)
+	}
+	if diff := cmp.Diff([]Block{{Base: 4000, Count: 10}, {Base: 4010, Count: 10}}, []Block{a, b}); diff != "" {
+		t.Fatalf("blocks mismatch (-want +got):\n%s", diff)
+	}
+
+	again, err := s.Allocate(t.Context(), "/wt/a/", 4000, 10)
+	if err != nil {
+		t.Fatalf("Allocate(a again) error: %v", err)
+	}
+	if diff := cmp.Diff(a, again); diff != "" {
+		t.Fatalf("expected stable block (-want +got):\n%s", diff)
+	}
+
+	if err := s.Release(t.Context(), "/wt/a"); err != nil {
+		t.Fatalf("Release(a) error: %v", err)
+	}
+	if _, ok, err := s.Get("/wt/a"); err != nil || ok {
+		t.Fatalf("Get(a) after release = %v, %v; want not found", ok, err)
+	}
+	c, err := s.Allocate(t.Context(), "/wt/c", 4000, 10)
+	if err != nil {
+		t.Fatalf("Allocate(c) error: %v", err)
+	}
+	if diff := cmp.Diff(Block{Base: 4000, Count: 10}, c); diff != "" {
+		t.Fatalf("expected released block to be reused (-want +got):\n%s", diff)
+	}
+
+	// Changing the block size reallocates without overlapping existing blocks.
+	grown, err := s.Allocate(t.Context(), "/wt/b", 4000, 15)
+	if err != nil {
+		t.Fatalf("Allocate(b, 15) error: %v", err)
+	}
+	if diff := cmp.Diff(Block{Base: 4015, Count: 15}, grown); diff != "" {
+		t.Fatalf("resized block mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := s.Prune(t.Context(), func(p string) bool { return p == "/wt/c" }); err != nil {
+		t.Fatalf("Prune() error: %v", err)
+	}
+	all, err := s.All()
+	if err != nil {
+		t.Fatalf("All() error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]Block{"/wt/c": c}, all); diff != "" {
+		t.Fatalf("blocks after prune mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStoreAllocateExhausted(t *testing.T) {
+	t.Parallel()
+
+	s := NewStore(t.TempDir())
+	if _, err := s.Allocate(t.Context(), "/wt/a", 65530, 5); err != nil {
+		t.Fatalf("Allocate(a) error: %v", err)
+	}
+	if _, err := s.Allocate(t.Context(), "/wt/b", 65530, 5); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("expected ErrExhausted, got %v", err)
+	}
+}
+
+func TestBlockEnv(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		block Block
+		want  []string
+	}{
+		"success: zero block": {
+			block: Block{},
+			want:  nil,
+		},
+		"success: two ports": {
+			block: Block{Base: 4010, Count: 2},
+			want:  []string{"WR_PORT=4010", "WR_PORT_COUNT=2", "WR_PORT_0=4010", "WR_PORT_1=4011"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tc.want, tc.block.Env()); diff != "" {
+				t.Fatalf("env mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/internal/statefile"
+)
+
+// stateVersion is the version of the persisted registry.
+const stateVersion = 1
+
+// FirstID is the first ID handed out by AssignIDs. ID 1 is reserved for the main repository.
+const FirstID = 2
+
+// Entry is the metadata recorded for one worktree.
+type Entry struct {
+	// ID is a stable numeric identifier. IDs are never reused, even after the worktree is removed.
+	ID int `json:"id,omitempty"`
+	// Branch is the original branch name. It lets worktrees be found by branch even when their directory
+	// name is hashed, truncated or shared with another sanitized branch name.
+	Branch string `json:"branch,omitempty"`
+	// FromRef is the ref the worktree was created from.
+	FromRef string `json:"fromRef,omitempty"`
+	// FromCommit is the commit HEAD pointed at when the worktree was created.
+	FromCommit string `json:"fromCommit,omitempty"`
+	// CreatedBy is the login name of the user who created the worktree.
+	CreatedBy string `json:"createdBy,omitempty"`
+	// CreatedAt is when the worktree was created.
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	// Description is free text, for example the task the worktree belongs to.
+	Description string `json:"description,omitempty"`
+	// Tags are sorted and unique.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// HasTag reports whether e is tagged with tag.
+func (e Entry) HasTag(tag string) bool {
+	return slices.Contains(e.Tags, tag)
+}
+
+// AddTags adds tags to e, keeping Tags sorted and unique.
+func (e *Entry) AddTags(tags ...string) {
+	e.Tags = append(e.Tags, tags...)
+	slices.Sort(e.Tags)
+	e.Tags = slices.Compact(e.Tags)
+}
+
+// RemoveTags removes tags from e.
+func (e *Entry) RemoveTags(tags ...string) {
+	e.Tags = slices.DeleteFunc(e.Tags, func(t string) bool { return slices.Contains(tags, t) })
+	if len(e.Tags) == 0 {
+		e.Tags = nil
+	}
+}
+
+type state struct {
+	Version   int              `json:"version"`
+	NextID    int              `json:"nextId,omitempty"`
+	Worktrees map[string]Entry `json:"worktrees"`
+}
+
+// nextID returns the next free ID and advances the counter.
+func (st *state) nextID() int {
+	if st.NextID < FirstID {
+		st.NextID = FirstID
+		for _, e := range st.Worktrees {
+			st.NextID = max(st.NextID, e.ID+1)
+		}
+	}
+	id := st.NextID
+	st.NextID++
+	return id
+}
+
+// Store persists worktree metadata keyed by worktree path in a JSON file under the git common dir.
+//
+// Mutations are serialized with a dedicated lock file so that they can run while wr.lock is held.
+type Store struct {
+	file statefile.File
+}
+
+// NewStore returns a Store backed by <commonDir>/wr-registry.json.
+func NewStore(commonDir string) Store {
+	return Store{file: statefile.New(commonDir, "wr-registry", "worktree registry")}
+}
+
+// Get returns the entry recorded for worktreePath, if any.
+func (s Store) Get(worktreePath string) (Entry, bool, error) {
+	st, err := s.load()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	e, ok := st.Worktrees[filepath.Clean(worktreePath)]
+	return e, ok, nil
+}
+
+// All returns every entry keyed by worktree path.
+func (s Store) All() (map[string]Entry, error) {
+	st, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return st.Worktrees, nil
+}
+
+// Update calls fn with the entry of worktreePath, or a zero Entry when there is none, and stores the result.
+func (s Store) Update(ctx context.Context, worktreePath string, fn func(e *Entry) error) error {
+	key := filepath.Clean(worktreePath)
+	return s.update(ctx, func(st *state) (bool, error) {
+		e := st.Worktrees[key]
+		if err := fn(&e); err != nil {
+			return false, err
+		}
+		st.Worktrees[key] = e
+		return true, nil
+	})
+}
+
+// AssignIDs gives every path in worktreePaths that has no ID yet the next free ID, creating entries as
+// needed, and returns the IDs of all of them keyed by cleaned path.
+func (s Store) AssignIDs(ctx context.Context, worktreePaths []string) (map[string]int, error) {
+	out := make(map[string]int, len(worktreePaths))
+	err := s.update(ctx, func(st *state) (bool, error) {
+		changed := false
+		for _, p := range worktreePaths {
+			key := filepath.Clean(p)
+			e := st.Worktrees[key]
+			if e.ID == 0 {
+				e.ID = st.nextID()
+				st.Worktrees[key] = e
+				changed = true
+			}
+			out[key] = e.ID
+		}
+		return changed, nil
+	})
+	return out, err
+}
+
+// Release forgets worktreePath. Releasing an unknown path is a no-op.
+func (s Store) Release(ctx context.Context, worktreePath string) error {
+	key := filepath.Clean(worktreePath)
+	return s.update(ctx, func(st *state) (bool, error) {
+		if _, ok := st.Worktrees[key]; !ok {
+			return false, nil
+		}
+		delete(st.Worktrees, key)
+		return true, nil
+	})
+}
+
+// Prune forgets every worktree path for which keep returns false.
+func (s Store) Prune(ctx context.Context, keep func(worktreePath string) bool) error {
+	return s.update(ctx, func(st *state) (bool, error) {
+		changed := false
+		for key := range st.Worktrees {
+			if !keep(key) {
+				delete(st.Worktrees, key)
+				changed = true
+			}
+		}
+		return changed, nil
+	})
+}
+
+func (s Store) update(ctx context.Context, fn func(st *state) (changed bool, err error)) error {
+	return s.file.Update(ctx, func() error {
+		st, err := s.load()
+		if err != nil {
+			return err
+		}
+		changed, err := fn(&st)
+		if err != nil || !changed {
+			return err
+		}
+		return s.file.Save(st)
+	})
+}
+
+func (s Store) load() (state, error) {
+	st := state{Version: stateVersion, Worktrees: map[string]Entry{}}
+	if err := s.file.Load(&st); err != nil {
+		return state{}, err
+	}
+	if st.Version != stateVersion {
+		return state{}, fmt.Errorf("unsupported worktree registry version %d in %q", st.Version, s.file.Path())
+	}
+	if st.Worktrees == nil {
+		st.Worktrees = map[string]Entry{}
+	}
+	return st, nil
+}
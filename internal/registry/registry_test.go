@@ -0,0 +1,114 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s := NewStore(dir)
+	a := filepath.Join(dir, "wt", "feat-a")
+	b := filepath.Join(dir, "wt", "feat-a-1234abcd")
+
+	if err := s.Update(t.Context(), a, func(e *Entry) error {
+		e.Branch = "feat/a"
+		return nil
+	}); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	for _, tags := range [][]string{{"review", "ai"}, {"ai"}} {
+		if err := s.Update(t.Context(), b+string(filepath.Separator), func(e *Entry) error {
+			e.Branch = "feat-a"
+			e.AddTags(tags...)
+			return nil
+		}); err != nil {
+			t.Fatalf("Update() error: %v", err)
+		}
+	}
+
+	got, ok, err := s.Get(b)
+	if err != nil || !ok {
+		t.Fatalf("Get() = %+v, %v, %v", got, ok, err)
+	}
+	if diff := cmp.Diff(Entry{Branch: "feat-a", Tags: []string{"ai", "review"}}, got); diff != "" {
+		t.Fatalf("entry mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := s.Update(t.Context(), b, func(e *Entry) error {
+		e.RemoveTags("ai", "review")
+		return nil
+	}); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if got, _, _ := s.Get(b); got.Tags != nil {
+		t.Fatalf("expected no tags, got %v", got.Tags)
+	}
+
+	if err := s.Prune(t.Context(), func(p string) bool { return p == a }); err != nil {
+		t.Fatalf("Prune() error: %v", err)
+	}
+	all, err := s.All()
+	if err != nil {
+		t.Fatalf("All() error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]Entry{a: {Branch: "feat/a"}}, all); diff != "" {
+		t.Fatalf("registry mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := s.Release(t.Context(), a); err != nil {
+		t.Fatalf("Release() error: %v", err)
+	}
+	if _, ok, _ := s.Get(a); ok {
+		t.Fatalf("expected %q to be released", a)
+	}
+}
+
+func TestStoreAssignIDs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	s := NewStore(dir)
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	c := filepath.Join(dir, "c")
+
+	got, err := s.AssignIDs(t.Context(), []string{a, b})
+	if err != nil {
+		t.Fatalf("AssignIDs() error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]int{a: FirstID, b: FirstID + 1}, got); diff != "" {
+		t.Fatalf("IDs mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := s.Release(t.Context(), b); err != nil {
+		t.Fatalf("Release() error: %v", err)
+	}
+	got, err = s.AssignIDs(t.Context(), []string{c, a})
+	if err != nil {
+		t.Fatalf("AssignIDs() error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]int{a: FirstID, c: FirstID + 2}, got); diff != "" {
+		t.Fatalf("IDs mismatch after release (-want +got):\n%s", diff)
+	}
+}
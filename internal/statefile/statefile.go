@@ -0,0 +1,113 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statefile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/internal/lock"
+)
+
+// lockTimeout bounds how long Update waits for another process to finish its update.
+const lockTimeout = 30 * time.Second
+
+// File is a JSON document persisted under the git common dir, such as the port allocations or the worktree
+// registry.
+//
+// Updates are serialized with a dedicated lock file next to it, so that they can run while wr.lock is held,
+// and writes are atomic, so that readers never observe a partial file.
+type File struct {
+	path     string
+	lockPath string
+	// name describes the document in error messages, for example "worktree registry".
+	name string
+}
+
+// New returns a File for <dir>/<base>.json locked by <dir>/<base>.lock.
+func New(dir, base, name string) File {
+	return File{
+		path:     filepath.Join(dir, base+".json"),
+		lockPath: filepath.Join(dir, base+".lock"),
+		name:     name,
+	}
+}
+
+// Path returns the path of the document.
+func (f File) Path() string {
+	return f.path
+}
+
+// Load decodes the document into v. It leaves v untouched when the file does not exist.
+func (f File) Load(v any) error {
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read %s %q: %w", f.name, f.path, err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("parse %s %q: %w", f.name, f.path, err)
+	}
+	return nil
+}
+
+// Update calls fn while holding the lock file. fn typically loads, modifies and saves the document.
+func (f File) Update(ctx context.Context, fn func() error) error {
+	l, err := lock.Acquire(ctx, f.lockPath, lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = l.Release() }()
+
+	return fn()
+}
+
+// Save writes v as indented JSON, atomically replacing the document.
+func (f File) Save(v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	pattern := "." + strings.TrimSuffix(filepath.Base(f.path), ".json") + "-*.json"
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), pattern)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", f.name, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write %s: %w", f.name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", f.name, err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("write %s: %w", f.name, err)
+	}
+	return nil
+}
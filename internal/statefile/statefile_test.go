@@ -0,0 +1,86 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statefile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testState struct {
+	Version int            `json:"version"`
+	Values  map[string]int `json:"values"`
+}
+
+func TestFileUpdate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	f := New(dir, "wr-test", "test state")
+
+	var missing testState
+	if err := f.Load(&missing); err != nil {
+		t.Fatalf("Load() missing file error: %v", err)
+	}
+	if diff := cmp.Diff(testState{}, missing); diff != "" {
+		t.Fatalf("Load() missing file mismatch (-want +got):\n%s", diff)
+	}
+
+	want := testState{Version: 1, Values: map[string]int{"a": 1}}
+	if err := f.Update(t.Context(), func() error { return f.Save(want) }); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+
+	var got testState
+	if err := f.Load(&got); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Load() mismatch (-want +got):\n%s", diff)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if diff := cmp.Diff([]string{"wr-test.json", "wr-test.lock"}, names); diff != "" {
+		t.Fatalf("directory entries mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFileLoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "wr-test.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var st testState
+	err := New(dir, "wr-test", "test state").Load(&st)
+	if err == nil || !strings.HasPrefix(err.Error(), "parse test state ") {
+		t.Fatalf("Load() error = %v, want parse test state error", err)
+	}
+}
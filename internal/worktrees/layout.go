@@ -0,0 +1,211 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worktrees
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/internal/naming"
+)
+
+// DefaultPathTemplate is the layout used when wr.worktrees.pathTemplate is unset: <prefix><sanitized-branch>.
+const DefaultPathTemplate = "{{.Prefix}}{{.Name}}"
+
+// ErrInvalidPathTemplate is returned when wr.worktrees.pathTemplate cannot be parsed or rendered into a
+// relative path that is unique per branch.
+var ErrInvalidPathTemplate = errors.New("invalid worktree path template")
+
+// PathData holds the values available to wr.worktrees.pathTemplate.
+type PathData struct {
+	// Repo is the directory name of the main repository.
+	Repo string
+	// User is the sanitized login name of the current user.
+	User string
+	// Branch is the branch name converted to a directory name (see wr.worktrees.naming).
+	Branch string
+	// Name is Branch followed by "-<suffix>" when `git wr new --name` is used.
+	Name string
+	// Date is the creation date as YYYY-MM-DD.
+	Date string
+	// Prefix is wr.worktrees.prefix.
+	Prefix string
+}
+
+// wildcard stands in for the per-worktree fields when the template is turned into a glob pattern.
+const wildcard = "\x00"
+
+func parsePathTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("pathTemplate").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPathTemplate, err)
+	}
+	return tmpl, nil
+}
+
+// validate checks that the template renders to a relative path and that different branches never share one.
+func (p Paths) validate() error {
+	a, err := p.render(p.data("a", "", time.Time{}))
+	if err != nil {
+		return err
+	}
+	b, err := p.render(p.data("b", "", time.Time{}))
+	if err != nil {
+		return err
+	}
+	if a == b {
+		return fmt.Errorf("%w: %q does not depend on the branch; use {{.Branch}} or {{.Name}}", ErrInvalidPathTemplate, p.Template)
+	}
+	return nil
+}
+
+func (p Paths) data(branch, suffix string, now time.Time) PathData {
+	return p.dataWithName(naming.DirName(branch, p.Naming), suffix, now)
+}
+
+func (p Paths) dataWithName(name, suffix string, now time.Time) PathData {
+	d := PathData{
+		Repo:   p.Repo,
+		User:   p.User,
+		Branch: name,
+		Name:   name,
+		Date:   now.Format(time.DateOnly),
+		Prefix: p.Prefix,
+	}
+	if suffix != "" {
+		d.Name += "-" + suffix
+	}
+	return d
+}
+
+// render executes the template and returns the cleaned relative path it produces.
+func (p Paths) render(data PathData) (string, error) {
+	tmpl, err := parsePathTemplate(p.Template)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidPathTemplate, err)
+	}
+
+	parts := strings.Split(filepath.ToSlash(b.String()), "/")
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("%w: %q renders to %q, which is not a relative path below the worktrees dir", ErrInvalidPathTemplate, p.Template, b.String())
+		}
+	}
+	return filepath.Join(parts...), nil
+}
+
+// WorktreePath returns the directory of a new worktree for branch created at now.
+// suffix, when non-empty, is appended to the name as "-<suffix>".
+func (p Paths) WorktreePath(branch, suffix string, now time.Time) (string, error) {
+	rel, err := p.render(p.data(branch, suffix, now))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(p.BaseDir, rel), nil
+}
+
+// DisambiguatedPath is like WorktreePath but appends a short hash of branch to the Branch and Name fields.
+// It is used when WorktreePath is already taken by another branch.
+func (p Paths) DisambiguatedPath(branch, suffix string, now time.Time) (string, error) {
+	rel, err := p.render(p.dataWithName(naming.Disambiguate(branch, p.Naming), suffix, now))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(p.BaseDir, rel), nil
+}
+
+// Depth returns how many directory levels below BaseDir the template produces.
+func (p Paths) Depth() (int, error) {
+	rel, err := p.render(p.data("a", "", time.Time{}))
+	if err != nil {
+		return 0, err
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1, nil
+}
+
+// Glob returns a filepath.Glob pattern that matches every directory the template can produce.
+//
+// Repo and Prefix are matched literally; the remaining fields match any name.
+func (p Paths) Glob() (string, error) {
+	rel, err := p.render(PathData{
+		Repo:   p.Repo,
+		User:   wildcard,
+		Branch: wildcard,
+		Name:   wildcard,
+		Date:   wildcard,
+		Prefix: p.Prefix,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(escapeGlob(p.BaseDir))
+	b.WriteRune(filepath.Separator)
+	inWildcard := false
+	for _, r := range rel {
+		if string(r) == wildcard {
+			if !inWildcard {
+				b.WriteByte('*')
+			}
+			inWildcard = true
+			continue
+		}
+		inWildcard = false
+		b.WriteString(escapeGlob(string(r)))
+	}
+	return b.String(), nil
+}
+
+// RelPath returns path relative to BaseDir using forward slashes, or false when path is outside BaseDir.
+func (p Paths) RelPath(path string) (string, bool) {
+	rel, err := filepath.Rel(p.BaseDir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// escapeGlob quotes the filepath.Match metacharacters in s.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[':
+			b.WriteByte('[')
+			b.WriteRune(r)
+			b.WriteByte(']')
+		case '\\':
+			if filepath.Separator == '\\' {
+				b.WriteRune(r)
+			} else {
+				b.WriteString(`\\`)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worktrees
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPathsWorktreePath(t *testing.T) {
+	t.Parallel()
+
+	base := filepath.Join(string(filepath.Separator), "wt")
+	now := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		paths    Paths
+		branch   string
+		suffix   string
+		want     string
+		wantGlob string
+		wantErr  error
+	}{
+		"success: default template": {
+			paths:    Paths{Template: DefaultPathTemplate, Prefix: "wt-"},
+			branch:   "feature/auth",
+			want:     "wt-feature-auth",
+			wantGlob: "wt-*",
+		},
+		"success: default template with suffix": {
+			paths:    Paths{Template: DefaultPathTemplate},
+			branch:   "feature",
+			suffix:   "2",
+			want:     "feature-2",
+			wantGlob: "*",
+		},
+		"success: nested layout": {
+			paths:    Paths{Template: "{{.Repo}}/{{.User}}/{{.Branch}}", Repo: "repo", User: "alice"},
+			branch:   "feature/auth",
+			want:     filepath.Join("repo", "alice", "feature-auth"),
+			wantGlob: filepath.Join("repo", "*", "*"),
+		},
+		"success: date prefix": {
+			paths:    Paths{Template: "{{.Date}}-{{.Branch}}"},
+			branch:   "fix",
+			want:     "2025-03-04-fix",
+			wantGlob: "*-*",
+		},
+		"success: glob escapes literal metacharacters": {
+			paths:    Paths{Template: "{{.Prefix}}{{.Name}}", Prefix: "[x]*"},
+			branch:   "fix",
+			want:     "[x]*fix",
+			wantGlob: "[[]x][*]*",
+		},
+		"error: escapes the worktrees dir": {
+			paths:   Paths{Template: "../{{.Branch}}"},
+			branch:  "fix",
+			wantErr: ErrInvalidPathTemplate,
+		},
+		"error: unknown field": {
+			paths:   Paths{Template: "{{.Nope}}"},
+			branch:  "fix",
+			wantErr: ErrInvalidPathTemplate,
+		},
+		"error: does not depend on the branch": {
+			paths:   Paths{Template: "{{.User}}"},
+			branch:  "fix",
+			wantErr: ErrInvalidPathTemplate,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := tc.paths
+			p.BaseDir = base
+
+			err := p.validate()
+			if err == nil {
+				var got string
+				got, err = p.WorktreePath(tc.branch, tc.suffix, now)
+				if err == nil {
+					if diff := cmp.Diff(filepath.Join(base, tc.want), got); diff != "" {
+						t.Fatalf("path mismatch (-want +got):\n%s", diff)
+					}
+				}
+			}
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WorktreePath() error: %v", err)
+			}
+
+			glob, err := p.Glob()
+			if err != nil {
+				t.Fatalf("Glob() error: %v", err)
+			}
+			if diff := cmp.Diff(filepath.Join(base, tc.wantGlob), glob); diff != "" {
+				t.Fatalf("glob mismatch (-want +got):\n%s", diff)
+			}
+			if ok, err := filepath.Match(glob, filepath.Join(base, tc.want)); err != nil || !ok {
+				t.Fatalf("glob %q does not match %q (err=%v)", glob, tc.want, err)
+			}
+		})
+	}
+}
@@ -40,6 +40,9 @@ type PorcelainEntry struct {
 	Detached bool
 	Locked   bool
 	Prunable bool
+
+	// LockReason is the reason recorded by `git worktree lock --reason`, if any.
+	LockReason string
 }
 
 // ListPorcelain lists the main repository worktree and all linked worktrees by scanning
@@ -106,7 +109,7 @@ func ListPorcelain(ctx context.Context, commonDir, mainRoot string, resolveBranc
 			return nil, err
 		}
 
-		locked, err := fileExists(filepath.Join(metaDir, "locked"))
+		locked, lockReason, err := worktreeLockFromMeta(metaDir)
 		if err != nil {
 			return nil, err
 		}
@@ -151,6 +154,8 @@ func ListPorcelain(ctx context.Context, commonDir, mainRoot string, resolveBranc
 			Detached: detached,
 			Locked:   locked,
 			Prunable: prunable,
+
+			LockReason: lockReason,
 		})
 	}
 
@@ -214,15 +219,16 @@ func worktreeBranchFromMeta(metaDir string) (branch string, detached bool, err e
 	return gitx.DetachedBranch, true, nil
 }
 
-func fileExists(path string) (bool, error) {
-	_, err := os.Stat(path)
-	if err == nil {
-		return true, nil
-	}
-	if errors.Is(err, os.ErrNotExist) {
-		return false, nil
+func worktreeLockFromMeta(metaDir string) (locked bool, reason string, err error) {
+	lockedFile := filepath.Join(metaDir, "locked")
+	b, err := os.ReadFile(lockedFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("read %q: %w", lockedFile, err)
 	}
-	return false, err
+	return true, strings.TrimSpace(string(b)), nil
 }
 
 func isPrunableWorktree(worktreePath string) (bool, error) {
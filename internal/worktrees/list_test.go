@@ -385,6 +385,48 @@ func TestListPorcelainLockedWorktree(t *testing.T) {
 	}
 }
 
+func TestListPorcelainLockReason(t *testing.T) {
+	t.Parallel()
+
+	g := testutil.Git(t)
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	worktreeDir := filepath.Join(t.TempDir(), "wt1")
+	testutil.InitRepo(t, g, repoDir)
+	testutil.AddWorktree(t, g, repoDir, worktreeDir, "foo")
+
+	if _, err := g.Run(t.Context(), repoDir, "worktree", "lock", "--reason", "on a usb stick", worktreeDir); err != nil {
+		t.Fatalf("git worktree lock --reason: %v", err)
+	}
+
+	worktreeDir, err := pathutil.Canonicalize(worktreeDir)
+	if err != nil {
+		t.Fatalf("Canonicalize(worktreeDir): %v", err)
+	}
+
+	resolveBranch := func(ctx context.Context, dir string) (string, error) {
+		return gitx.CurrentBranchGit(ctx, g, dir)
+	}
+
+	entries, err := ListPorcelain(t.Context(), filepath.Join(repoDir, ".git"), repoDir, resolveBranch)
+	if err != nil {
+		t.Fatalf("ListPorcelain() error: %v", err)
+	}
+
+	for _, e := range entries {
+		if e.Path != worktreeDir {
+			continue
+		}
+		if !e.Locked {
+			t.Fatalf("expected locked worktree, got %+v", e)
+		}
+		if diff := cmp.Diff("on a usb stick", e.LockReason); diff != "" {
+			t.Fatalf("lock reason mismatch (-want +got):\n%s", diff)
+		}
+		return
+	}
+	t.Fatalf("expected worktree entry path %q in %+v", worktreeDir, entries)
+}
+
 func TestListPorcelainPrunableWorktree(t *testing.T) {
 	t.Parallel()
 
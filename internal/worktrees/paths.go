@@ -19,9 +19,11 @@ package worktrees
 import (
 	"context"
 	"fmt"
+	"os/user"
 	"path/filepath"
 
 	"github.com/zchee/git-worktree-runner/internal/config"
+	"github.com/zchee/git-worktree-runner/internal/naming"
 	"github.com/zchee/git-worktree-runner/internal/pathutil"
 )
 
@@ -29,12 +31,22 @@ import (
 type Paths struct {
 	BaseDir string
 	Prefix  string
+	// Template is the text/template that lays out worktree directories below BaseDir (see PathData).
+	Template string
+	// Naming converts branch names into the Branch and Name fields of PathData.
+	Naming naming.Scheme
+
+	// Repo and User are the constant PathData fields.
+	Repo string
+	User string
 }
 
 // ResolvePaths resolves the base directory and prefix for worktrees.
 //
 // Precedence for BaseDir: git config wr.worktrees.dir > env GTR_WORKTREES_DIR > default (<parent>/<repo>-worktrees).
 // Precedence for Prefix: git config wr.worktrees.prefix > env GTR_WORKTREES_PREFIX > default ("").
+// Template is wr.worktrees.pathTemplate (.wrconfig: worktrees.pathTemplate), defaulting to DefaultPathTemplate.
+// Naming is wr.worktrees.naming (.wrconfig: worktrees.naming), defaulting to naming.SchemeSanitize.
 func ResolvePaths(ctx context.Context, cfg config.Resolver) (Paths, error) {
 	prefix, err := cfg.Default(ctx, "wr.worktrees.prefix", "GTR_WORKTREES_PREFIX", "", "")
 	if err != nil {
@@ -64,8 +76,43 @@ func ResolvePaths(ctx context.Context, cfg config.Resolver) (Paths, error) {
 		return Paths{}, fmt.Errorf("canonicalize base dir: %w", err)
 	}
 
-	return Paths{
-		BaseDir: baseDir,
-		Prefix:  prefix,
-	}, nil
+	tmpl, err := cfg.Default(ctx, "wr.worktrees.pathTemplate", "", DefaultPathTemplate, "worktrees.pathTemplate")
+	if err != nil {
+		return Paths{}, fmt.Errorf("resolve wr.worktrees.pathTemplate: %w", err)
+	}
+
+	schemeRaw, err := cfg.Default(ctx, "wr.worktrees.naming", "", string(naming.SchemeSanitize), "worktrees.naming")
+	if err != nil {
+		return Paths{}, fmt.Errorf("resolve wr.worktrees.naming: %w", err)
+	}
+	scheme, err := naming.ParseScheme(schemeRaw)
+	if err != nil {
+		return Paths{}, err
+	}
+
+	p := Paths{
+		BaseDir:  baseDir,
+		Prefix:   prefix,
+		Template: tmpl,
+		Naming:   scheme,
+		Repo:     filepath.Base(cfg.MainRoot),
+		User:     currentUser(cfg),
+	}
+	if err := p.validate(); err != nil {
+		return Paths{}, err
+	}
+	return p, nil
+}
+
+// currentUser returns the sanitized login name from $USER (%USERNAME% on Windows) or the OS user database.
+func currentUser(cfg config.Resolver) string {
+	for _, key := range []string{"USER", "USERNAME"} {
+		if v, ok := cfg.LookupEnv(key); ok && v != "" {
+			return naming.SanitizeBranchName(v)
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return naming.SanitizeBranchName(filepath.Base(u.Username))
+	}
+	return "unknown"
 }
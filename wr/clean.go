@@ -18,22 +18,75 @@ package wr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/zchee/git-worktree-runner/internal/lock"
 	"github.com/zchee/git-worktree-runner/internal/worktrees"
 )
 
+// CleanOptions configures Manager.CleanWithOptions.
+type CleanOptions struct {
+	// Merged selects worktrees whose HEAD is already reachable from the default branch.
+	Merged bool
+	// Stale selects worktrees whose last activity (the later of the last commit time and the
+	// worktree directory mtime) is older than Stale. Zero disables the check.
+	Stale time.Duration
+
+	// DryRun computes the plan without removing, pruning or deleting anything.
+	DryRun bool
+
+	// DeleteBranch also deletes the branch of each removed worktree, but only when it is merged.
+	DeleteBranch bool
+	// Yes skips Confirm and ConfirmDeleteBranch.
+	Yes bool
+
+	// Confirm is called with the worktrees about to be removed, unless Yes is true or there is nothing to remove.
+	// Returning false aborts the removal; pruning and empty-directory cleanup still happen.
+	// It runs while the repository lock is held, so plan is exactly what gets removed.
+	Confirm func(ctx context.Context, plan []CleanCandidate) (bool, error)
+	// ConfirmDeleteBranch has the same semantics as RemoveWorktreeOptions.ConfirmDeleteBranch.
+	ConfirmDeleteBranch func(ctx context.Context, branch string) (bool, error)
+}
+
+// CleanCandidate is a worktree selected by CleanOptions.Merged or CleanOptions.Stale.
+type CleanCandidate struct {
+	StatusEntry
+
+	// Reasons lists why the worktree was selected: "merged" and/or "stale".
+	Reasons []string
+	// LastActivity is the time used for the stale check.
+	LastActivity time.Time
+	// Skipped explains why a selected worktree is not removed (for example "dirty" or "locked"). Empty when it is removed.
+	Skipped string
+}
+
 // CleanResult describes the effect of Clean.
 type CleanResult struct {
 	RemovedEmptyDirs []string
+
+	// Candidates lists every worktree selected for removal, including skipped ones.
+	Candidates []CleanCandidate
+	// Removed lists the worktrees that were removed. It is empty in dry-run mode.
+	Removed []Target
+	// DeletedBranches lists the branches deleted along with their worktrees.
+	DeletedBranches []string
 }
 
 // Clean prunes stale worktree metadata and removes empty worktree directories.
 func (m *Manager) Clean(ctx context.Context) (CleanResult, error) {
+	return m.CleanWithOptions(ctx, CleanOptions{})
+}
+
+// CleanWithOptions is like Clean but can also remove merged or stale worktrees.
+//
+// Dirty and locked worktrees are never removed. Removal goes through the same path as Remove,
+// so preRemove and postRemove hooks run for every removed worktree.
+func (m *Manager) CleanWithOptions(ctx context.Context, opts CleanOptions) (CleanResult, error) {
 	lockPath := filepath.Join(m.repoCtx.CommonDir, "wr.lock")
 	l, err := lock.Acquire(ctx, lockPath, 30*time.Second)
 	if err != nil {
@@ -41,44 +94,159 @@ func (m *Manager) Clean(ctx context.Context) (CleanResult, error) {
 	}
 	defer func() { _ = l.Release() }()
 
-	// Best-effort prune (matches upstream).
-	_, _ = m.git.Run(ctx, m.repoCtx.MainRoot, "worktree", "prune")
+	var result CleanResult
+	var errs []error
+
+	if opts.Merged || opts.Stale > 0 {
+		result.Candidates, err = m.cleanCandidates(ctx, opts)
+		if err != nil {
+			return CleanResult{}, err
+		}
+
+		var plan []CleanCandidate
+		for _, c := range result.Candidates {
+			if c.Skipped == "" {
+				plan = append(plan, c)
+			}
+		}
+
+		proceed := len(plan) > 0 && !opts.DryRun
+		if proceed && !(opts.Yes || m.yes) && opts.Confirm != nil {
+			proceed, err = opts.Confirm(ctx, plan)
+			if err != nil {
+				return CleanResult{}, err
+			}
+		}
+
+		if proceed {
+			for _, c := range plan {
+				deleted, err := m.removeTarget(ctx, c.Target, RemoveWorktreeOptions{
+					DeleteBranch:        opts.DeleteBranch && c.Merged,
+					Yes:                 opts.Yes,
+					ConfirmDeleteBranch: opts.ConfirmDeleteBranch,
+				})
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				result.Removed = append(result.Removed, c.Target)
+				if deleted {
+					result.DeletedBranches = append(result.DeletedBranches, c.Target.Branch)
+				}
+			}
+		}
+	}
+
+	if !opts.DryRun {
+		// Best-effort prune (matches upstream).
+		_, _ = m.git.Run(ctx, m.repoCtx.MainRoot, "worktree", "prune")
+
+		if err := m.prunePorts(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		if err := m.pruneRegistry(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	result.RemovedEmptyDirs, err = m.removeEmptyDirs(ctx, opts.DryRun)
+	if err != nil {
+		errs = append(errs, err)
+	}
 
+	return result, errors.Join(errs...)
+}
+
+func (m *Manager) cleanCandidates(ctx context.Context, opts CleanOptions) ([]CleanCandidate, error) {
+	entries, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var out []CleanCandidate
+	for _, e := range entries {
+		if e.Target.IsMain || !hasWorktreeDir(e.ListEntry) {
+			continue
+		}
+
+		c := CleanCandidate{StatusEntry: e, LastActivity: e.LastCommit}
+		if fi, err := os.Stat(e.Target.Path); err == nil && fi.ModTime().After(c.LastActivity) {
+			c.LastActivity = fi.ModTime()
+		}
+
+		if opts.Merged && e.DefaultRef != "" && e.Merged && e.Target.Branch != e.DefaultBranch {
+			c.Reasons = append(c.Reasons, "merged")
+		}
+		if opts.Stale > 0 && now.Sub(c.LastActivity) > opts.Stale {
+			c.Reasons = append(c.Reasons, "stale")
+		}
+		if len(c.Reasons) == 0 {
+			continue
+		}
+
+		switch {
+		case e.Status == WorktreeStatusLocked:
+			c.Skipped = "locked"
+		case e.Dirty.Total() > 0:
+			c.Skipped = "dirty"
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// removeEmptyDirs removes empty directories under the worktrees base dir, down to the depth of
+// wr.worktrees.pathTemplate. Deeper levels go first, so parents emptied by that are removed as well.
+// When dryRun is true, it only reports them.
+func (m *Manager) removeEmptyDirs(ctx context.Context, dryRun bool) ([]string, error) {
 	paths, err := worktrees.ResolvePaths(ctx, m.cfg)
 	if err != nil {
-		return CleanResult{}, err
+		return nil, err
 	}
 
 	if _, err := os.Stat(paths.BaseDir); err != nil {
 		if os.IsNotExist(err) {
-			return CleanResult{}, nil
+			return nil, nil
 		}
-		return CleanResult{}, err
+		return nil, err
 	}
 
-	entries, err := os.ReadDir(paths.BaseDir)
+	depth, err := paths.Depth()
 	if err != nil {
-		return CleanResult{}, err
+		return nil, err
 	}
 
 	var removed []string
-	for _, e := range entries {
-		if !e.IsDir() {
-			continue
-		}
-		dirPath := filepath.Join(paths.BaseDir, e.Name())
-		children, err := os.ReadDir(dirPath)
+	for level := depth; level >= 1; level-- {
+		pattern := filepath.Join(append([]string{paths.BaseDir}, slices.Repeat([]string{"*"}, level)...)...)
+		dirs, err := filepath.Glob(pattern)
 		if err != nil {
-			continue
-		}
-		if len(children) != 0 {
-			continue
+			return removed, err
 		}
-		if err := os.Remove(dirPath); err != nil {
-			return CleanResult{}, fmt.Errorf("remove empty directory %q: %w", dirPath, err)
+		for _, dirPath := range dirs {
+			children, err := os.ReadDir(dirPath)
+			if err != nil {
+				continue
+			}
+			if dryRun {
+				// Directories that only contain empty directories would be removed too.
+				if slices.ContainsFunc(children, func(c os.DirEntry) bool {
+					return !slices.Contains(removed, filepath.Join(dirPath, c.Name()))
+				}) {
+					continue
+				}
+			} else if len(children) != 0 {
+				continue
+			}
+			if !dryRun {
+				if err := os.Remove(dirPath); err != nil {
+					return removed, fmt.Errorf("remove empty directory %q: %w", dirPath, err)
+				}
+			}
+			removed = append(removed, dirPath)
 		}
-		removed = append(removed, dirPath)
 	}
 
-	return CleanResult{RemovedEmptyDirs: removed}, nil
+	return removed, nil
 }
@@ -17,13 +17,17 @@
 package wr
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/zchee/git-worktree-runner/internal/gitx"
 	"github.com/zchee/git-worktree-runner/internal/pathutil"
 	"github.com/zchee/git-worktree-runner/internal/testutil"
 )
@@ -92,3 +96,146 @@ func TestCleanRemovesEmptyDirectories(t *testing.T) {
 		t.Fatalf("removed dirs mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestCleanWithOptionsMergedAndStale(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	mainBranch, err := gitx.CurrentBranchGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("CurrentBranchGit() error: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "wr.defaultBranch", mainBranch); err != nil {
+		t.Fatalf("git config wr.defaultBranch: %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	targets := map[string]Target{}
+	for _, branch := range []string{"merged", "dirty", "fresh", "forwarded", "active", "old"} {
+		target, err := m.CreateWorktree(t.Context(), branch, CreateWorktreeOptions{
+			FromCurrent: true,
+			NoCopy:      true,
+		})
+		if err != nil {
+			t.Fatalf("CreateWorktree(%s) error: %v", branch, err)
+		}
+		targets[branch] = target
+	}
+
+	// "merged" and "dirty" land on the default branch; "fresh" never moves, so it is not merged either.
+	for _, branch := range []string{"merged", "dirty"} {
+		if _, err := g.Run(t.Context(), targets[branch].Path, "commit", "--allow-empty", "-m", branch); err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+		if _, err := g.Run(t.Context(), repoDir, "merge", "--no-edit", branch); err != nil {
+			t.Fatalf("git merge %s: %v", branch, err)
+		}
+	}
+	// "forwarded" catches up with the default branch without any work of its own, so it is not merged.
+	if _, err := g.Run(t.Context(), targets["forwarded"].Path, "merge", "--ff-only", mainBranch); err != nil {
+		t.Fatalf("git merge %s: %v", mainBranch, err)
+	}
+	if err := os.WriteFile(filepath.Join(targets["dirty"].Path, "README.md"), []byte("changed\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(README.md): %v", err)
+	}
+	if _, err := g.Run(t.Context(), targets["active"].Path, "commit", "--allow-empty", "-m", "active"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+	oldGit := g
+	oldGit.Env = append(slices.Clone(g.Env), "GIT_COMMITTER_DATE=2000-01-01T00:00:00Z")
+	if _, err := oldGit.Run(t.Context(), targets["old"].Path, "commit", "--allow-empty", "-m", "old"); err != nil {
+		t.Fatalf("git commit (old): %v", err)
+	}
+	oldTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(targets["old"].Path, oldTime, oldTime); err != nil {
+		t.Fatalf("Chtimes(old): %v", err)
+	}
+
+	type plan struct {
+		Reasons []string
+		Skipped string
+	}
+	planOf := func(res CleanResult) map[string]plan {
+		out := map[string]plan{}
+		for _, c := range res.Candidates {
+			out[c.Target.Branch] = plan{Reasons: c.Reasons, Skipped: c.Skipped}
+		}
+		return out
+	}
+	wantPlan := map[string]plan{
+		"merged": {Reasons: []string{"merged"}},
+		"dirty":  {Reasons: []string{"merged"}, Skipped: "dirty"},
+		"old":    {Reasons: []string{"stale"}},
+	}
+
+	dryRun, err := m.CleanWithOptions(t.Context(), CleanOptions{
+		Merged: true,
+		Stale:  24 * time.Hour,
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("CleanWithOptions(dry-run) error: %v", err)
+	}
+	if diff := cmp.Diff(wantPlan, planOf(dryRun)); diff != "" {
+		t.Fatalf("dry-run plan mismatch (-want +got):\n%s", diff)
+	}
+	if len(dryRun.Removed) != 0 {
+		t.Fatalf("expected dry-run to remove nothing, got %+v", dryRun.Removed)
+	}
+	for branch, target := range targets {
+		if _, err := os.Stat(target.Path); err != nil {
+			t.Fatalf("expected %s worktree to remain after dry-run: %v", branch, err)
+		}
+	}
+
+	declined, err := m.CleanWithOptions(t.Context(), CleanOptions{
+		Merged: true,
+		Confirm: func(context.Context, []CleanCandidate) (bool, error) {
+			return false, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("CleanWithOptions(declined) error: %v", err)
+	}
+	if len(declined.Removed) != 0 {
+		t.Fatalf("expected declined clean to remove nothing, got %+v", declined.Removed)
+	}
+
+	got, err := m.CleanWithOptions(t.Context(), CleanOptions{
+		Merged:       true,
+		Stale:        24 * time.Hour,
+		DeleteBranch: true,
+		Yes:          true,
+	})
+	if err != nil {
+		t.Fatalf("CleanWithOptions() error: %v", err)
+	}
+	if diff := cmp.Diff(wantPlan, planOf(got)); diff != "" {
+		t.Fatalf("plan mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"merged"}, got.DeletedBranches); diff != "" {
+		t.Fatalf("deleted branches mismatch (-want +got):\n%s", diff)
+	}
+
+	for branch, wantExists := range map[string]bool{"merged": false, "old": false, "dirty": true, "fresh": true, "active": true} {
+		_, err := os.Stat(targets[branch].Path)
+		if wantExists && err != nil {
+			t.Fatalf("expected %s worktree to remain: %v", branch, err)
+		}
+		if !wantExists && !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected %s worktree to be removed, stat err=%v", branch, err)
+		}
+	}
+
+	// The unmerged stale branch keeps its commits.
+	if _, err := g.Run(t.Context(), repoDir, "rev-parse", "--verify", "refs/heads/old"); err != nil {
+		t.Fatalf("expected branch old to remain: %v", err)
+	}
+}
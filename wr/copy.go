@@ -19,10 +19,62 @@ package wr
 import (
 	"context"
 	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
 
 	"github.com/zchee/git-worktree-runner/internal/copy"
+	"github.com/zchee/git-worktree-runner/internal/gitx"
 )
 
+// CopyMode selects how copied files are materialized in the destination (see wr.copy.mode).
+type CopyMode = copy.Mode
+
+const (
+	CopyModeCopy     = copy.ModeCopy
+	CopyModeReflink  = copy.ModeReflink
+	CopyModeHardlink = copy.ModeHardlink
+	CopyModeSymlink  = copy.ModeSymlink
+)
+
+// CopyPreserve selects which file attributes copies keep (see wr.copy.preserve).
+type CopyPreserve = copy.Preserve
+
+// defaultCopyPreserve is the wr.copy.preserve default: copies get fresh modification times and only the
+// permission bits of the source.
+const defaultCopyPreserve = "none"
+
+// CopyConflictPolicy decides what happens to target files that already exist (see wr.copy.conflict).
+type CopyConflictPolicy = copy.ConflictPolicy
+
+const (
+	CopyConflictOverwrite    = copy.ConflictOverwrite
+	CopyConflictSkipExisting = copy.ConflictSkipExisting
+	CopyConflictNewer        = copy.ConflictNewer
+	CopyConflictBackup       = copy.ConflictBackup
+	CopyConflictPrompt       = copy.ConflictPrompt
+)
+
+// CopyConflict describes a target file that already existed and what the conflict policy did to it.
+type CopyConflict = copy.Conflict
+
+// CopyConflictAction is what the conflict policy did to a target file.
+type CopyConflictAction = copy.ConflictAction
+
+const (
+	CopyConflictActionOverwrite = copy.ConflictActionOverwrite
+	CopyConflictActionSkip      = copy.ConflictActionSkip
+	CopyConflictActionBackup    = copy.ConflictActionBackup
+	CopyConflictActionDelete    = copy.ConflictActionDelete
+)
+
+// defaultCopyConflict is the wr.copy.conflict default for Copy. CreateWorktree always overwrites.
+const defaultCopyConflict = copy.ConflictSkipExisting
+
+// CopyProgress reports how far a copy has advanced.
+type CopyProgress = copy.Progress
+
 // CopyOptions configures `git wr copy`.
 type CopyOptions struct {
 	From string
@@ -32,12 +84,64 @@ type CopyOptions struct {
 
 	Patterns      []string
 	PreservePaths bool
+
+	// Mode forces the copy mode for every file. When empty, wr.copy.mode and wr.copy.modeOverride apply.
+	Mode CopyMode
+	// Preserve forces the preserved attributes as a comma-separated list such as "symlinks,mode,times".
+	// When empty, wr.copy.preserve applies.
+	Preserve string
+
+	// Sync skips files whose destination already has the same size and modification time
+	// (or contents, with Checksum).
+	Sync     bool
+	Checksum bool
+	// Delete removes files from the destination that match the patterns but no longer exist in the source.
+	// Each removal goes through Conflict like an overwrite (so the skip-existing default keeps every file).
+	// Files git tracks in the destination are never removed, and with IgnoredOnly neither are files git
+	// does not ignore there; they are reported as Protected.
+	Delete bool
+
+	// IgnoredOnly copies only files that git ignores in the source, so that tracked files are never
+	// duplicated. wr.copy.ignoredOnly enables it by default.
+	IgnoredOnly bool
+
+	// Conflict decides what happens to target files that already exist and differ from the source.
+	// When empty, wr.copy.conflict applies (default skip-existing).
+	Conflict CopyConflictPolicy
+	// ConfirmOverwrite is called for each conflicting file under CopyConflictPrompt, unless the manager
+	// was created with Yes, in which case every file is overwritten.
+	ConfirmOverwrite func(ctx context.Context, target Target, path string) (bool, error)
+	// ConfirmDelete is called like ConfirmOverwrite for each file Delete would remove.
+	ConfirmDelete func(ctx context.Context, target Target, path string) (bool, error)
+
+	// Progress, if non-nil, receives progress updates for each destination target in turn.
+	// It is not called in dry-run mode.
+	Progress func(target Target, p CopyProgress)
 }
 
 // CopyResult is a per-target copy outcome.
 type CopyResult struct {
 	Target      Target
 	CopiedFiles []string
+	// Modes maps each entry of CopiedFiles to the copy mode actually used.
+	Modes map[string]CopyMode
+
+	// Added and Updated split CopiedFiles by whether the file already existed in the target.
+	Added   []string
+	Updated []string
+	// Unchanged lists the files left alone because the target already matched the source: by size and
+	// contents, or with Sync by size and modification time.
+	Unchanged []string
+	// Skipped lists the files matched by the patterns but excluded by wr.copy.exclude or left alone
+	// by the conflict policy.
+	Skipped []string
+	// Conflicts lists the files that already existed in the target and differed from the source.
+	Conflicts []CopyConflict
+	// Deleted lists the files removed by Delete, including the ones moved to a backup.
+	Deleted []string
+	// Protected lists the files Delete left alone because git tracks them in the target (or, with
+	// IgnoredOnly, does not ignore them there).
+	Protected []string
 }
 
 // Copy copies files from a source target into one or more destination targets.
@@ -52,18 +156,13 @@ func (m *Manager) Copy(ctx context.Context, targets []string, opts CopyOptions)
 	}
 
 	includes := opts.Patterns
+	var rules *copy.Matcher
 	if len(includes) == 0 {
-		cfgIncludes, err := m.cfg.All(ctx, "wr.copy.include", "copy.include")
-		if err != nil {
+		if includes, rules, err = m.copyIncludes(ctx); err != nil {
 			return nil, err
 		}
-		fileIncludes, err := m.cfg.WorktreeIncludePatterns()
-		if err != nil {
-			return nil, err
-		}
-		includes = append(cfgIncludes, fileIncludes...)
 	}
-	if len(includes) == 0 {
+	if len(includes) == 0 && rules.Empty() {
 		return nil, copy.ErrNoPatterns
 	}
 
@@ -106,26 +205,206 @@ func (m *Manager) Copy(ctx context.Context, targets []string, opts CopyOptions)
 		}
 	}
 
-	if opts.PreservePaths == false {
-		opts.PreservePaths = false
-	} else {
-		opts.PreservePaths = true
+	copyOpts, err := m.copyOptions(ctx, opts.Mode, opts.Preserve)
+	if err != nil {
+		return nil, err
+	}
+	copyOpts.PreservePaths = opts.PreservePaths
+	copyOpts.DryRun = opts.DryRun
+	copyOpts.Sync = opts.Sync
+	copyOpts.Checksum = opts.Checksum
+	copyOpts.Delete = opts.Delete
+	copyOpts.Include = rules
+	ignoredOnly, err := m.copyIgnoredOnly(ctx, opts.IgnoredOnly)
+	if err != nil {
+		return nil, err
+	}
+	if ignoredOnly {
+		copyOpts.Filter = m.ignoredFilter(src.Path)
+	}
+	if copyOpts.Conflict, err = m.copyConflictPolicy(ctx, opts.Conflict); err != nil {
+		return nil, err
+	}
+	if copyOpts.Conflict == copy.ConflictPrompt && m.yes {
+		copyOpts.Conflict = copy.ConflictOverwrite
 	}
 
 	var results []CopyResult
 	for _, dst := range destTargets {
-		res, err := copy.CopyFiles(ctx, src.Path, dst.Path, includes, excludes, copy.Options{
-			PreservePaths: opts.PreservePaths,
-			DryRun:        opts.DryRun,
-		})
+		if opts.Progress != nil {
+			copyOpts.Progress = func(p CopyProgress) { opts.Progress(dst, p) }
+		}
+		if opts.ConfirmOverwrite != nil {
+			copyOpts.ConfirmOverwrite = func(ctx context.Context, rel string) (bool, error) {
+				return opts.ConfirmOverwrite(ctx, dst, rel)
+			}
+		}
+		if opts.ConfirmDelete != nil {
+			copyOpts.ConfirmDelete = func(ctx context.Context, rel string) (bool, error) {
+				return opts.ConfirmDelete(ctx, dst, rel)
+			}
+		}
+		if opts.Delete {
+			copyOpts.DeleteFilter = m.deleteFilter(dst.Path, ignoredOnly)
+		}
+		res, err := copy.CopyFiles(ctx, src.Path, dst.Path, includes, excludes, copyOpts)
 		if err != nil {
 			return nil, err
 		}
+		if !opts.DryRun && len(res.CopiedFiles) > 0 {
+			m.emit(Event{Type: EventCopied, Branch: dst.Branch, Path: dst.Path, Files: res.CopiedFiles})
+		}
 		results = append(results, CopyResult{
 			Target:      dst,
 			CopiedFiles: res.CopiedFiles,
+			Modes:       res.Modes,
+			Added:       res.Added,
+			Updated:     res.Updated,
+			Unchanged:   res.Unchanged,
+			Skipped:     res.Skipped,
+			Deleted:     res.Deleted,
+			Protected:   res.Protected,
+			Conflicts:   res.Conflicts,
 		})
 	}
 
 	return results, nil
 }
+
+// copyOptions resolves wr.copy.mode, wr.copy.modeOverride and wr.copy.preserve into copy options.
+//
+// A non-empty modeOverride replaces both mode settings, and a non-empty preserveOverride replaces wr.copy.preserve.
+func (m *Manager) copyOptions(ctx context.Context, modeOverride CopyMode, preserveOverride string) (copy.Options, error) {
+	preserve, err := m.copyPreserve(ctx, preserveOverride)
+	if err != nil {
+		return copy.Options{}, err
+	}
+
+	if modeOverride != "" {
+		mode, err := copy.ParseMode(string(modeOverride))
+		if err != nil {
+			return copy.Options{}, err
+		}
+		return copy.Options{Mode: mode, Preserve: preserve}, nil
+	}
+
+	raw, err := m.cfg.Default(ctx, "wr.copy.mode", "", string(copy.ModeCopy), "copy.mode")
+	if err != nil {
+		return copy.Options{}, err
+	}
+	mode, err := copy.ParseMode(raw)
+	if err != nil {
+		return copy.Options{}, fmt.Errorf("wr.copy.mode: %w", err)
+	}
+
+	rawRules, err := m.cfg.All(ctx, "wr.copy.modeOverride", "copy.modeOverride")
+	if err != nil {
+		return copy.Options{}, err
+	}
+	rules := make([]copy.ModeRule, 0, len(rawRules))
+	for _, raw := range rawRules {
+		rule, err := copy.ParseModeRule(raw)
+		if err != nil {
+			return copy.Options{}, fmt.Errorf("wr.copy.modeOverride: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return copy.Options{Mode: mode, ModeRules: rules, Preserve: preserve}, nil
+}
+
+// copyIncludes returns the wr.copy.include globs and the rules of every .worktreeinclude file.
+func (m *Manager) copyIncludes(ctx context.Context) ([]string, *copy.Matcher, error) {
+	globs, err := m.cfg.All(ctx, "wr.copy.include", "copy.include")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := m.cfg.WorktreeIncludeFiles(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules := new(copy.Matcher)
+	for _, f := range files {
+		if err := rules.AddRules(f.Dir, f.Patterns); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path.Join(f.Dir, ".worktreeinclude"), err)
+		}
+	}
+	return globs, rules, nil
+}
+
+// copyIgnoredOnly reports whether copies keep only the files git ignores: when force or wr.copy.ignoredOnly
+// is set.
+func (m *Manager) copyIgnoredOnly(ctx context.Context, force bool) (bool, error) {
+	if force {
+		return true, nil
+	}
+	raw, err := m.cfg.Default(ctx, "wr.copy.ignoredOnly", "", "false", "copy.ignoredOnly")
+	if err != nil {
+		return false, err
+	}
+	on, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid wr.copy.ignoredOnly %q: expected true or false", raw)
+	}
+	return on, nil
+}
+
+// ignoredFilter returns a copy filter that keeps only the files git ignores in srcRoot.
+func (m *Manager) ignoredFilter(srcRoot string) func(context.Context, []string) ([]string, error) {
+	return func(ctx context.Context, rels []string) ([]string, error) {
+		return gitx.IgnoredGit(ctx, m.git, srcRoot, rels)
+	}
+}
+
+// deleteFilter returns a copy delete filter that keeps the files git does not track in dstRoot, and with
+// ignoredOnly, only the ones git ignores there.
+func (m *Manager) deleteFilter(dstRoot string, ignoredOnly bool) func(context.Context, []string) ([]string, error) {
+	return func(ctx context.Context, rels []string) ([]string, error) {
+		tracked, err := gitx.TrackedGit(ctx, m.git, dstRoot, rels)
+		if err != nil {
+			return nil, err
+		}
+		isTracked := make(map[string]bool, len(tracked))
+		for _, rel := range tracked {
+			isTracked[rel] = true
+		}
+		untracked := slices.DeleteFunc(slices.Clone(rels), func(rel string) bool { return isTracked[rel] })
+		if !ignoredOnly {
+			return untracked, nil
+		}
+		return gitx.IgnoredGit(ctx, m.git, dstRoot, untracked)
+	}
+}
+
+func (m *Manager) copyConflictPolicy(ctx context.Context, override CopyConflictPolicy) (CopyConflictPolicy, error) {
+	if override != "" {
+		return copy.ParseConflictPolicy(string(override))
+	}
+
+	raw, err := m.cfg.Default(ctx, "wr.copy.conflict", "", string(defaultCopyConflict), "copy.conflict")
+	if err != nil {
+		return "", err
+	}
+	policy, err := copy.ParseConflictPolicy(raw)
+	if err != nil {
+		return "", fmt.Errorf("wr.copy.conflict: %w", err)
+	}
+	return policy, nil
+}
+
+func (m *Manager) copyPreserve(ctx context.Context, override string) (CopyPreserve, error) {
+	if override != "" {
+		return copy.ParsePreserve(override)
+	}
+
+	raw, err := m.cfg.Default(ctx, "wr.copy.preserve", "", defaultCopyPreserve, "copy.preserve")
+	if err != nil {
+		return CopyPreserve{}, err
+	}
+	preserve, err := copy.ParsePreserve(raw)
+	if err != nil {
+		return CopyPreserve{}, fmt.Errorf("wr.copy.preserve: %w", err)
+	}
+	return preserve, nil
+}
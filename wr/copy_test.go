@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -171,3 +172,379 @@ func TestManagerCopyAllSkipsMissingTargets(t *testing.T) {
 		t.Fatalf("expected orphan dir to be skipped, stat err=%v", err)
 	}
 }
+
+func TestManagerCopyModeConfig(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "wr.copy.mode", "hardlink"); err != nil {
+		t.Fatalf("git config wr.copy.mode: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "--add", "wr.copy.modeOverride", "*.local=copy"); err != nil {
+		t.Fatalf("git config wr.copy.modeOverride: %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	target, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+
+	for _, name := range []string{".env.local", "data.bin"} {
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+
+	tests := map[string]struct {
+		mode CopyMode
+		want map[string]CopyMode
+	}{
+		"success: config mode and override": {
+			want: map[string]CopyMode{".env.local": CopyModeCopy, "data.bin": CopyModeHardlink},
+		},
+		"success: explicit mode replaces config": {
+			mode: CopyModeCopy,
+			want: map[string]CopyMode{".env.local": CopyModeCopy, "data.bin": CopyModeCopy},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Identical target files are left alone, so start from an empty target each time.
+			for _, name := range []string{".env.local", "data.bin"} {
+				if err := os.Remove(filepath.Join(target.Path, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("Remove(%s): %v", name, err)
+				}
+			}
+			got, err := m.Copy(t.Context(), []string{"feature-a"}, CopyOptions{
+				Patterns:      []string{".env.local", "data.bin"},
+				PreservePaths: true,
+				Mode:          tc.mode,
+				Conflict:      CopyConflictOverwrite,
+			})
+			if err != nil {
+				t.Fatalf("Copy() error: %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("expected 1 result, got %d: %+v", len(got), got)
+			}
+			if diff := cmp.Diff(tc.want, got[0].Modes); diff != "" {
+				t.Fatalf("modes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerCopyPreserveDefault(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	target, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+
+	src := filepath.Join(repoDir, ".env.local")
+	if err := os.WriteFile(src, []byte("A=B\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	old := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(src, old, old); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	dst := filepath.Join(target.Path, ".env.local")
+
+	tests := map[string]struct {
+		preserve  string
+		wantTimes bool
+	}{
+		"success: default keeps no attributes": {},
+		"success: --preserve times":            {preserve: "times", wantTimes: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("Remove: %v", err)
+			}
+			if _, err := m.Copy(t.Context(), []string{"feature-a"}, CopyOptions{
+				Patterns: []string{".env.local"},
+				Preserve: tc.preserve,
+			}); err != nil {
+				t.Fatalf("Copy() error: %v", err)
+			}
+			fi, err := os.Stat(dst)
+			if err != nil {
+				t.Fatalf("Stat: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantTimes, fi.ModTime().Equal(old)); diff != "" {
+				t.Fatalf("preserved mtime mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerCopyConflictDefault(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	target, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, ".env.local"), []byte("main\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	local := filepath.Join(target.Path, ".env.local")
+	if err := os.WriteFile(local, []byte("worktree edit\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := m.Copy(t.Context(), []string{"feature-a"}, CopyOptions{
+		Patterns:      []string{".env.local"},
+		PreservePaths: true,
+	})
+	if err != nil {
+		t.Fatalf("Copy() error: %v", err)
+	}
+	want := []CopyConflict{{Path: ".env.local", Action: CopyConflictActionSkip}}
+	if diff := cmp.Diff(want, got[0].Conflicts); diff != "" {
+		t.Fatalf("conflicts mismatch (-want +got):\n%s", diff)
+	}
+	b, err := os.ReadFile(local)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if diff := cmp.Diff("worktree edit\n", string(b)); diff != "" {
+		t.Fatalf("contents mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "wr.copy.conflict", "backup"); err != nil {
+		t.Fatalf("git config wr.copy.conflict: %v", err)
+	}
+	if _, err := m.Copy(t.Context(), []string{"feature-a"}, CopyOptions{
+		Patterns:      []string{".env.local"},
+		PreservePaths: true,
+	}); err != nil {
+		t.Fatalf("Copy() error: %v", err)
+	}
+	for path, want := range map[string]string{local: "main\n", local + ".wr-bak": "worktree edit\n"} {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if diff := cmp.Diff(want, string(b)); diff != "" {
+			t.Fatalf("%s mismatch (-want +got):\n%s", filepath.Base(path), diff)
+		}
+	}
+}
+
+func TestManagerCopyIgnoredOnly(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	files := map[string]string{
+		".gitignore":       "local.env\n",
+		".worktreeinclude": "*.env\n!skip.env\n",
+		"defaults.env":     "tracked\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+	if _, err := g.Run(t.Context(), repoDir, "add", "."); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "commit", "-m", "config"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+	for _, name := range []string{"local.env", "notes.env", "skip.env"} {
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	if _, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	}); err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+
+	got, err := m.Copy(t.Context(), []string{"feature-a"}, CopyOptions{
+		PreservePaths: true,
+		IgnoredOnly:   true,
+	})
+	if err != nil {
+		t.Fatalf("Copy() error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"local.env"}, got[0].CopiedFiles); diff != "" {
+		t.Fatalf("copied mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"defaults.env", "notes.env"}, got[0].Skipped); diff != "" {
+		t.Fatalf("skipped mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManagerCopyDeleteKeepsTrackedFiles(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	if err := os.WriteFile(filepath.Join(repoDir, ".gitignore"), []byte("*.local\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile(.gitignore): %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "add", ".gitignore"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "commit", "-m", "ignore"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repoDir, "config"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "config", "app.local"), []byte("app\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile(app.local): %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	protected := []string{"config/branch.yml", "config/edited.yml"}
+	tests := map[string]struct {
+		branch        string
+		opts          CopyOptions
+		wantDeleted   []string
+		wantProtected []string
+		wantRemaining []string
+	}{
+		"success: skip-existing default keeps stale files": {
+			branch:        "skip",
+			wantProtected: protected,
+			wantRemaining: []string{"app.local", "branch.yml", "edited.yml", "notes.txt", "old.local"},
+		},
+		"success: overwrite deletes untracked files only": {
+			branch:        "overwrite",
+			opts:          CopyOptions{Conflict: CopyConflictOverwrite},
+			wantDeleted:   []string{"config/notes.txt", "config/old.local"},
+			wantProtected: protected,
+			wantRemaining: []string{"app.local", "branch.yml", "edited.yml"},
+		},
+		"success: ignored-only keeps files git does not ignore": {
+			branch:        "ignored-only",
+			opts:          CopyOptions{Conflict: CopyConflictOverwrite, IgnoredOnly: true},
+			wantDeleted:   []string{"config/old.local"},
+			wantProtected: []string{"config/branch.yml", "config/edited.yml", "config/notes.txt"},
+			wantRemaining: []string{"app.local", "branch.yml", "edited.yml", "notes.txt"},
+		},
+		"success: backup moves stale files aside": {
+			branch:        "backup",
+			opts:          CopyOptions{Conflict: CopyConflictBackup},
+			wantDeleted:   []string{"config/notes.txt", "config/old.local"},
+			wantProtected: protected,
+			wantRemaining: []string{"app.local", "branch.yml", "edited.yml", "notes.txt.wr-bak", "old.local.wr-bak"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			target, err := m.CreateWorktree(t.Context(), tc.branch, CreateWorktreeOptions{FromCurrent: true, NoCopy: true})
+			if err != nil {
+				t.Fatalf("CreateWorktree() error: %v", err)
+			}
+
+			// branch.yml and edited.yml are tracked only on the worktree's branch, and edited.yml also has
+			// local changes; notes.txt is untracked and old.local is ignored. None exist in the source.
+			configDir := filepath.Join(target.Path, "config")
+			if err := os.MkdirAll(configDir, 0o755); err != nil {
+				t.Fatalf("MkdirAll: %v", err)
+			}
+			for name, content := range map[string]string{"branch.yml": "branch\n", "edited.yml": "edited\n"} {
+				if err := os.WriteFile(filepath.Join(configDir, name), []byte(content), 0o644); err != nil {
+					t.Fatalf("WriteFile(%s): %v", name, err)
+				}
+			}
+			if _, err := g.Run(t.Context(), target.Path, "add", "config"); err != nil {
+				t.Fatalf("git add: %v", err)
+			}
+			if _, err := g.Run(t.Context(), target.Path, "commit", "-m", "branch config"); err != nil {
+				t.Fatalf("git commit: %v", err)
+			}
+			for name, content := range map[string]string{"edited.yml": "local edit\n", "notes.txt": "notes\n", "old.local": "old\n"} {
+				if err := os.WriteFile(filepath.Join(configDir, name), []byte(content), 0o644); err != nil {
+					t.Fatalf("WriteFile(%s): %v", name, err)
+				}
+			}
+
+			opts := tc.opts
+			opts.Patterns = []string{"config/**"}
+			opts.PreservePaths = true
+			opts.Delete = true
+			got, err := m.Copy(t.Context(), []string{tc.branch}, opts)
+			if err != nil {
+				t.Fatalf("Copy() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, got[0].Deleted); diff != "" {
+				t.Fatalf("deleted mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantProtected, got[0].Protected); diff != "" {
+				t.Fatalf("protected mismatch (-want +got):\n%s", diff)
+			}
+
+			entries, err := os.ReadDir(configDir)
+			if err != nil {
+				t.Fatalf("ReadDir: %v", err)
+			}
+			var remaining []string
+			for _, e := range entries {
+				remaining = append(remaining, e.Name())
+			}
+			if diff := cmp.Diff(tc.wantRemaining, remaining); diff != "" {
+				t.Fatalf("remaining mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
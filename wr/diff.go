@@ -0,0 +1,233 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zchee/git-worktree-runner/internal/gitx"
+)
+
+// DiffFormat selects the text rendered by Manager.Diff.
+type DiffFormat string
+
+const (
+	// DiffFormatPatch renders a unified diff (default).
+	DiffFormatPatch DiffFormat = "patch"
+	// DiffFormatStat renders a diffstat.
+	DiffFormatStat DiffFormat = "stat"
+	// DiffFormatNameOnly lists the changed file names.
+	DiffFormatNameOnly DiffFormat = "name-only"
+)
+
+// DiffOptions configures Manager.Diff.
+type DiffOptions struct {
+	Format DiffFormat
+	// Uncommitted also reports the staged and unstaged changes of each worktree relative to its HEAD.
+	// Untracked files are not included.
+	Uncommitted bool
+	// Color renders the text with ANSI colors.
+	Color bool
+}
+
+// DiffFile summarizes the changes to one file.
+type DiffFile struct {
+	Path string `json:"path"`
+	// OldPath is the source path of a rename or copy.
+	OldPath string `json:"oldPath,omitempty"`
+	// Status is the git status letter: A, C, D, M, R or T.
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Binary    bool   `json:"binary,omitempty"`
+}
+
+// DiffSection is one diff: the changed files and the rendered text.
+type DiffSection struct {
+	Files []DiffFile
+	// Text is the diff rendered in DiffOptions.Format, without a trailing newline. It is empty when nothing changed.
+	Text string
+}
+
+// Totals returns the number of added and deleted lines across all files.
+func (s DiffSection) Totals() (additions, deletions int) {
+	for _, f := range s.Files {
+		additions += f.Additions
+		deletions += f.Deletions
+	}
+	return additions, deletions
+}
+
+// DiffResult compares two worktrees.
+type DiffResult struct {
+	A, B         Target
+	AHead, BHead string
+
+	// Committed is the diff from AHead to BHead.
+	Committed DiffSection
+	// UncommittedA and UncommittedB hold each worktree's changes relative to its HEAD.
+	// They are nil unless DiffOptions.Uncommitted is set.
+	UncommittedA *DiffSection
+	UncommittedB *DiffSection
+}
+
+// Diff compares the worktrees identified by a and b (see ResolveTarget), showing the changes from a to b
+// like `git diff a b`.
+//
+// When b is empty, a is compared against the main repository, which becomes the A side: the result shows
+// what a changed relative to the main repository.
+func (m *Manager) Diff(ctx context.Context, a, b string, opts DiffOptions) (DiffResult, error) {
+	if b == "" {
+		a, b = "1", a
+	}
+	var flags []string
+	switch opts.Format {
+	case "", DiffFormatPatch:
+	case DiffFormatStat:
+		flags = append(flags, "--stat")
+	case DiffFormatNameOnly:
+		flags = append(flags, "--name-only")
+	default:
+		return DiffResult{}, fmt.Errorf("unknown diff format %q (want patch, stat or name-only)", opts.Format)
+	}
+	if opts.Color {
+		flags = append(flags, "--color=always")
+	} else {
+		flags = append(flags, "--no-color")
+	}
+
+	var res DiffResult
+	var err error
+	if res.A, err = m.ResolveTarget(ctx, a); err != nil {
+		return DiffResult{}, err
+	}
+	if res.B, err = m.ResolveTarget(ctx, b); err != nil {
+		return DiffResult{}, err
+	}
+	if res.AHead, err = m.headOf(ctx, res.A); err != nil {
+		return DiffResult{}, err
+	}
+	if res.BHead, err = m.headOf(ctx, res.B); err != nil {
+		return DiffResult{}, err
+	}
+
+	if res.Committed, err = m.diffSection(ctx, m.repoCtx.MainRoot, flags, res.AHead, res.BHead); err != nil {
+		return DiffResult{}, err
+	}
+
+	if opts.Uncommitted {
+		for _, side := range []struct {
+			target Target
+			out    **DiffSection
+		}{
+			{res.A, &res.UncommittedA},
+			{res.B, &res.UncommittedB},
+		} {
+			s, err := m.diffSection(ctx, side.target.Path, flags, "HEAD")
+			if err != nil {
+				return DiffResult{}, err
+			}
+			*side.out = &s
+		}
+	}
+
+	return res, nil
+}
+
+func (m *Manager) headOf(ctx context.Context, target Target) (string, error) {
+	out, err := m.git.Run(ctx, target.Path, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("resolve HEAD of %s: %w", target.Branch, err)
+	}
+	return strings.TrimSpace(out.Stdout), nil
+}
+
+func (m *Manager) diffSection(ctx context.Context, dir string, flags []string, revs ...string) (DiffSection, error) {
+	files, err := gitx.DiffFilesGit(ctx, m.git, dir, revs...)
+	if err != nil {
+		return DiffSection{}, err
+	}
+
+	args := append([]string{"diff", "--no-ext-diff"}, flags...)
+	out, err := m.git.Run(ctx, dir, append(args, revs...)...)
+	if err != nil {
+		return DiffSection{}, fmt.Errorf("git diff: %w", err)
+	}
+
+	s := DiffSection{Text: out.Stdout}
+	for _, f := range files {
+		s.Files = append(s.Files, DiffFile(f))
+	}
+	return s, nil
+}
+
+type diffJSONTarget struct {
+	Path   string `json:"path"`
+	Branch string `json:"branch"`
+	IsMain bool   `json:"isMain"`
+	Head   string `json:"head"`
+}
+
+type diffJSONSection struct {
+	Files     []DiffFile `json:"files"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+}
+
+func newDiffJSONSection(s DiffSection) diffJSONSection {
+	out := diffJSONSection{Files: s.Files}
+	if out.Files == nil {
+		out.Files = []DiffFile{}
+	}
+	out.Additions, out.Deletions = s.Totals()
+	return out
+}
+
+// WriteDiffJSON renders a summary of res (files and line counts, without the diff text) to w as a single
+// JSON document using the same schema version as WriteListJSON.
+func WriteDiffJSON(w io.Writer, res DiffResult) error {
+	type uncommitted struct {
+		A diffJSONSection `json:"a"`
+		B diffJSONSection `json:"b"`
+	}
+	doc := struct {
+		SchemaVersion int             `json:"schemaVersion"`
+		A             diffJSONTarget  `json:"a"`
+		B             diffJSONTarget  `json:"b"`
+		Committed     diffJSONSection `json:"committed"`
+		Uncommitted   *uncommitted    `json:"uncommitted,omitempty"`
+	}{
+		SchemaVersion: ListSchemaVersion,
+		A:             diffJSONTarget{Path: res.A.Path, Branch: res.A.Branch, IsMain: res.A.IsMain, Head: res.AHead},
+		B:             diffJSONTarget{Path: res.B.Path, Branch: res.B.Branch, IsMain: res.B.IsMain, Head: res.BHead},
+		Committed:     newDiffJSONSection(res.Committed),
+	}
+	if res.UncommittedA != nil && res.UncommittedB != nil {
+		doc.Uncommitted = &uncommitted{
+			A: newDiffJSONSection(*res.UncommittedA),
+			B: newDiffJSONSection(*res.UncommittedB),
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+)
+
+func TestManagerDiff(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	target, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(target.Path, "feature.txt"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := g.Run(t.Context(), target.Path, "add", "feature.txt"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if _, err := g.Run(t.Context(), target.Path, "commit", "-m", "feature"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(target.Path, "README.md"), []byte("edited\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tests := map[string]struct {
+		a, b            string
+		opts            DiffOptions
+		wantCommitted   []DiffFile
+		wantUncommitted []DiffFile
+		wantText        string
+	}{
+		"success: defaults to the main repo as base": {
+			a:             "feature-a",
+			wantCommitted: []DiffFile{{Path: "feature.txt", Status: "A", Additions: 2}},
+			wantText:      "+one",
+		},
+		"success: explicit order": {
+			a:             "feature-a",
+			b:             "1",
+			opts:          DiffOptions{Format: DiffFormatNameOnly},
+			wantCommitted: []DiffFile{{Path: "feature.txt", Status: "D", Deletions: 2}},
+			wantText:      "feature.txt",
+		},
+		"success: uncommitted": {
+			a:               "feature-a",
+			opts:            DiffOptions{Format: DiffFormatStat, Uncommitted: true},
+			wantCommitted:   []DiffFile{{Path: "feature.txt", Status: "A", Additions: 2}},
+			wantUncommitted: []DiffFile{{Path: "README.md", Status: "M", Additions: 1, Deletions: 1}},
+			wantText:        "1 file changed",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := m.Diff(t.Context(), tc.a, tc.b, tc.opts)
+			if err != nil {
+				t.Fatalf("Diff() error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCommitted, got.Committed.Files); diff != "" {
+				t.Fatalf("committed files mismatch (-want +got):\n%s", diff)
+			}
+			if !strings.Contains(got.Committed.Text, tc.wantText) {
+				t.Fatalf("committed text %q does not contain %q", got.Committed.Text, tc.wantText)
+			}
+			if !tc.opts.Uncommitted {
+				if got.UncommittedA != nil || got.UncommittedB != nil {
+					t.Fatalf("unexpected uncommitted sections: %+v %+v", got.UncommittedA, got.UncommittedB)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.wantUncommitted, got.UncommittedB.Files); diff != "" {
+				t.Fatalf("uncommitted files mismatch (-want +got):\n%s", diff)
+			}
+			if len(got.UncommittedA.Files) != 0 {
+				t.Fatalf("expected clean main repo, got %+v", got.UncommittedA.Files)
+			}
+		})
+	}
+
+	res, err := m.Diff(t.Context(), "feature-a", "", DiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff() error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteDiffJSON(&buf, res); err != nil {
+		t.Fatalf("WriteDiffJSON() error: %v", err)
+	}
+	var doc struct {
+		SchemaVersion int `json:"schemaVersion"`
+		B             struct {
+			Branch string `json:"branch"`
+		} `json:"b"`
+		Committed struct {
+			Additions int `json:"additions"`
+		} `json:"committed"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v\n%s", err, buf.String())
+	}
+	if doc.SchemaVersion != ListSchemaVersion || doc.B.Branch != "feature-a" || doc.Committed.Additions != 2 {
+		t.Fatalf("unexpected JSON document:\n%s", buf.String())
+	}
+}
@@ -31,9 +31,10 @@ type DoctorReport struct {
 
 	MainRoot string
 
-	WorktreesBaseDir string
-	WorktreesPrefix  string
-	WorktreeCount    int
+	WorktreesBaseDir      string
+	WorktreesPrefix       string
+	WorktreesPathTemplate string
+	WorktreeCount         int
 
 	Editor      string
 	EditorReady bool
@@ -58,6 +59,7 @@ func (m *Manager) Doctor(ctx context.Context) (DoctorReport, error) {
 	}
 	report.WorktreesBaseDir = paths.BaseDir
 	report.WorktreesPrefix = paths.Prefix
+	report.WorktreesPathTemplate = paths.Template
 
 	entries, err := m.List(ctx)
 	if err != nil {
@@ -123,6 +125,9 @@ func WriteDoctorReport(w io.Writer, report DoctorReport) {
 	}
 	writeLine("[OK] Repository: " + report.MainRoot)
 	writeLine("[OK] Worktrees directory: " + report.WorktreesBaseDir)
+	if report.WorktreesPathTemplate != "" && report.WorktreesPathTemplate != worktrees.DefaultPathTemplate {
+		writeLine("[OK] Worktree layout: " + report.WorktreesPathTemplate)
+	}
 	writeLine("")
 
 	if report.Editor == "none" || report.Editor == "" {
@@ -0,0 +1,196 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/internal/copy"
+	"github.com/zchee/git-worktree-runner/internal/gitcmd"
+	"github.com/zchee/git-worktree-runner/internal/hooks"
+	"github.com/zchee/git-worktree-runner/internal/lock"
+	"github.com/zchee/git-worktree-runner/internal/naming"
+	"github.com/zchee/git-worktree-runner/internal/ports"
+	"github.com/zchee/git-worktree-runner/internal/repoctx"
+	"github.com/zchee/git-worktree-runner/internal/worktrees"
+)
+
+// EventSchemaVersion is the version of the JSON schema written by WriteEventJSON.
+//
+// It is bumped only for incompatible changes; new event types, error codes and fields may be added without a bump.
+const EventSchemaVersion = 1
+
+// EventType identifies what an Event reports.
+type EventType string
+
+const (
+	// EventCreated reports a new worktree (Branch, Path).
+	EventCreated EventType = "created"
+	// EventCopied reports the files copied into a worktree (Branch, Path, Files).
+	EventCopied EventType = "copied"
+	// EventHookStarted reports a hook command about to run (Phase, Index, Command).
+	EventHookStarted EventType = "hook_started"
+	// EventHookFailed reports a failing hook command (Phase, Index, Command, ExitCode, Code, Message).
+	EventHookFailed EventType = "hook_failed"
+	// EventRemoved reports a removed worktree (Branch, Path, BranchDeleted).
+	EventRemoved EventType = "removed"
+	// EventError reports the error a command failed with (Code, Message).
+	EventError EventType = "error"
+)
+
+// ErrorCode is a stable, machine-readable classification of an error.
+type ErrorCode string
+
+const (
+	ErrorCodeUnknown               ErrorCode = "unknown"
+	ErrorCodeUsage                 ErrorCode = "usage"
+	ErrorCodeNotInRepo             ErrorCode = "not_in_repo"
+	ErrorCodeTargetNotFound        ErrorCode = "target_not_found"
+	ErrorCodeAmbiguousTarget       ErrorCode = "ambiguous_target"
+	ErrorCodeForceRequiresName     ErrorCode = "force_requires_name"
+	ErrorCodePathCollision         ErrorCode = "path_collision"
+	ErrorCodeInvalidTrackMode      ErrorCode = "invalid_track_mode"
+	ErrorCodeInvalidPathTemplate   ErrorCode = "invalid_path_template"
+	ErrorCodeInvalidNamingScheme   ErrorCode = "invalid_naming_scheme"
+	ErrorCodeNoPatterns            ErrorCode = "no_patterns"
+	ErrorCodeUnsafePattern         ErrorCode = "unsafe_pattern"
+	ErrorCodeSymlinkEscape         ErrorCode = "symlink_escape"
+	ErrorCodeInvalidCopyMode       ErrorCode = "invalid_copy_mode"
+	ErrorCodeInvalidPreserve       ErrorCode = "invalid_preserve"
+	ErrorCodeInvalidConflictPolicy ErrorCode = "invalid_conflict_policy"
+	ErrorCodeNoAIToolConfigured    ErrorCode = "no_ai_tool_configured"
+	ErrorCodePortsExhausted        ErrorCode = "ports_exhausted"
+	ErrorCodeLockTimeout           ErrorCode = "lock_timeout"
+	ErrorCodeHookFailed            ErrorCode = "hook_failed"
+	ErrorCodeGitFailed             ErrorCode = "git_failed"
+	ErrorCodeWorktreeDirty         ErrorCode = "worktree_dirty"
+	ErrorCodeWorktreeLocked        ErrorCode = "worktree_locked"
+	ErrorCodeDeclined              ErrorCode = "declined"
+)
+
+// errorCodes maps sentinel errors to their codes. It is checked in order, so more specific errors come first.
+var errorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{repoctx.ErrNotInRepo, ErrorCodeNotInRepo},
+	{ErrTargetNotFound, ErrorCodeTargetNotFound},
+	{ErrAmbiguousTarget, ErrorCodeAmbiguousTarget},
+	{ErrWorktreeDirty, ErrorCodeWorktreeDirty},
+	{ErrWorktreeLocked, ErrorCodeWorktreeLocked},
+	{ErrDeclined, ErrorCodeDeclined},
+	{ErrForceRequiresName, ErrorCodeForceRequiresName},
+	{ErrPathCollision, ErrorCodePathCollision},
+	{ErrInvalidTrackMode, ErrorCodeInvalidTrackMode},
+	{worktrees.ErrInvalidPathTemplate, ErrorCodeInvalidPathTemplate},
+	{naming.ErrInvalidScheme, ErrorCodeInvalidNamingScheme},
+	{copy.ErrNoPatterns, ErrorCodeNoPatterns},
+	{copy.ErrUnsafePattern, ErrorCodeUnsafePattern},
+	{copy.ErrSymlinkEscape, ErrorCodeSymlinkEscape},
+	{copy.ErrInvalidMode, ErrorCodeInvalidCopyMode},
+	{copy.ErrInvalidPreserve, ErrorCodeInvalidPreserve},
+	{copy.ErrInvalidConflictPolicy, ErrorCodeInvalidConflictPolicy},
+	{ErrNoAIToolConfigured, ErrorCodeNoAIToolConfigured},
+	{ports.ErrExhausted, ErrorCodePortsExhausted},
+	{lock.ErrAcquireTimeout, ErrorCodeLockTimeout},
+	{hooks.ErrHookFailed, ErrorCodeHookFailed},
+}
+
+// ErrorCodeOf classifies err. Errors that wrap none of the known sentinels are ErrorCodeGitFailed when a git
+// command failed, and ErrorCodeUnknown otherwise.
+//
+// For errors joined from several failures (e.g. Remove with several identifiers) the first match wins.
+func ErrorCodeOf(err error) ErrorCode {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	var exitErr *gitcmd.ExitError
+	if errors.As(err, &exitErr) {
+		return ErrorCodeGitFailed
+	}
+	return ErrorCodeUnknown
+}
+
+// Event is a progress or failure notification from a Manager operation.
+//
+// Subscribe with ManagerOptions.OnEvent. Fields that do not apply to Type are left empty.
+type Event struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+
+	// Branch and Path identify the worktree the event is about.
+	Branch string `json:"branch,omitempty"`
+	Path   string `json:"path,omitempty"`
+
+	// Files lists the copied files and directories, relative to the worktree (copied).
+	Files []string `json:"files,omitempty"`
+	// BranchDeleted reports whether the branch was deleted with the worktree (removed).
+	BranchDeleted bool `json:"branchDeleted,omitempty"`
+
+	// Phase, Index and Command identify a hook (hook_started, hook_failed). Index starts at 1.
+	Phase   string `json:"phase,omitempty"`
+	Index   int    `json:"index,omitempty"`
+	Command string `json:"command,omitempty"`
+	// ExitCode is the exit status of a failed hook, or -1 when it timed out (hook_failed).
+	ExitCode int `json:"exitCode,omitempty"`
+	// Stderr is what a failed hook wrote to stderr (hook_failed).
+	Stderr string `json:"stderr,omitempty"`
+
+	// Code and Message describe the failure (hook_failed, error).
+	Code    ErrorCode `json:"code,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+// ErrorEvent returns the error event for err.
+func ErrorEvent(err error) Event {
+	return Event{
+		Type:    EventError,
+		Time:    time.Now().UTC(),
+		Code:    ErrorCodeOf(err),
+		Message: err.Error(),
+	}
+}
+
+// WriteEventJSON writes e to w as a single line of JSON carrying "schemaVersion", for newline-delimited streams.
+func WriteEventJSON(w io.Writer, e Event) error {
+	line := struct {
+		SchemaVersion int `json:"schemaVersion"`
+		Event
+	}{
+		SchemaVersion: EventSchemaVersion,
+		Event:         e,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(line)
+}
+
+// emit delivers e to ManagerOptions.OnEvent, if set.
+func (m *Manager) emit(e Event) {
+	if m.onEvent == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	m.onEvent(e)
+}
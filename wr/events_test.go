@@ -0,0 +1,186 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/copy"
+	"github.com/zchee/git-worktree-runner/internal/gitcmd"
+	"github.com/zchee/git-worktree-runner/internal/hooks"
+	"github.com/zchee/git-worktree-runner/internal/lock"
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+)
+
+func TestManagerEvents(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	if err := os.WriteFile(filepath.Join(repoDir, ".env.local"), []byte("KEY=VALUE\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile(.env.local): %v", err)
+	}
+	for _, kv := range [][2]string{
+		{"wr.copy.include", ".env.local"},
+		{"wr.hook.postCreate", "echo created"},
+		{"wr.hook.preRemove", "exit 3"},
+	} {
+		if _, err := g.Run(t.Context(), repoDir, "config", "--local", "--add", kv[0], kv[1]); err != nil {
+			t.Fatalf("git config --add %s: %v", kv[0], err)
+		}
+	}
+
+	var events []Event
+	m, err := NewManager(t.Context(), ManagerOptions{
+		StartDir: repoDir,
+		OnEvent:  func(e Event) { events = append(events, e) },
+	})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	target, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{FromCurrent: true})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+	if err := m.Remove(t.Context(), []string{"feature-a"}, RemoveWorktreeOptions{Force: true}); !errors.Is(err, hooks.ErrHookFailed) {
+		t.Fatalf("Remove() error = %v, want %v", err, hooks.ErrHookFailed)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "--unset-all", "wr.hook.preRemove"); err != nil {
+		t.Fatalf("git config --unset-all: %v", err)
+	}
+	if err := m.Remove(t.Context(), []string{"feature-a"}, RemoveWorktreeOptions{Force: true}); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+
+	for i, e := range events {
+		if e.Time.IsZero() {
+			t.Fatalf("event %d (%s) has no time", i, e.Type)
+		}
+		events[i].Time = events[0].Time
+	}
+	at := events[0].Time
+	want := []Event{
+		{Type: EventCreated, Time: at, Branch: "feature-a", Path: target.Path},
+		{Type: EventCopied, Time: at, Branch: "feature-a", Path: target.Path, Files: []string{".env.local"}},
+		{Type: EventHookStarted, Time: at, Branch: "feature-a", Path: target.Path, Phase: hooks.PhasePostCreate, Index: 1, Command: "echo created"},
+		{Type: EventHookStarted, Time: at, Branch: "feature-a", Path: target.Path, Phase: hooks.PhasePreRemove, Index: 1, Command: "exit 3"},
+		{
+			Type:     EventHookFailed,
+			Time:     at,
+			Branch:   "feature-a",
+			Path:     target.Path,
+			Phase:    hooks.PhasePreRemove,
+			Index:    1,
+			Command:  "exit 3",
+			ExitCode: 3,
+			Code:     ErrorCodeHookFailed,
+			Message:  "preRemove hook 1 failed (exit 3): exit 3",
+		},
+		{Type: EventRemoved, Time: at, Branch: "feature-a", Path: target.Path},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Fatalf("events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestErrorCodeOf(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err  error
+		want ErrorCode
+	}{
+		"target not found": {
+			err:  fmt.Errorf("%w: nope", ErrTargetNotFound),
+			want: ErrorCodeTargetNotFound,
+		},
+		"force requires name": {
+			err:  ErrForceRequiresName,
+			want: ErrorCodeForceRequiresName,
+		},
+		"unsafe pattern": {
+			err:  fmt.Errorf("%w: %q", copy.ErrUnsafePattern, "../x"),
+			want: ErrorCodeUnsafePattern,
+		},
+		"lock timeout": {
+			err:  fmt.Errorf("wrap: %w", lock.ErrAcquireTimeout),
+			want: ErrorCodeLockTimeout,
+		},
+		"hook failed": {
+			err:  &hooks.HookError{Phase: hooks.PhasePreCreate, Index: 1, ExitCode: 1},
+			want: ErrorCodeHookFailed,
+		},
+		"worktree dirty": {
+			err:  fmt.Errorf("%w: /tmp/x", ErrWorktreeDirty),
+			want: ErrorCodeWorktreeDirty,
+		},
+		"worktree locked": {
+			err:  fmt.Errorf("%w: /tmp/x", ErrWorktreeLocked),
+			want: ErrorCodeWorktreeLocked,
+		},
+		"declined": {
+			err:  fmt.Errorf("clean %w", ErrDeclined),
+			want: ErrorCodeDeclined,
+		},
+		"git failed": {
+			err:  fmt.Errorf("add: %w", &gitcmd.ExitError{Path: "git", ExitCode: 128}),
+			want: ErrorCodeGitFailed,
+		},
+		"joined errors use the first match": {
+			err:  errors.Join(errors.New("other"), ErrAmbiguousTarget, ErrTargetNotFound),
+			want: ErrorCodeTargetNotFound,
+		},
+		"unknown": {
+			err:  errors.New("boom"),
+			want: ErrorCodeUnknown,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tc.want, ErrorCodeOf(tc.err)); diff != "" {
+				t.Fatalf("ErrorCodeOf(%v) mismatch (-want +got):\n%s", tc.err, diff)
+			}
+		})
+	}
+}
+
+func TestWriteEventJSON(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	e := Event{Type: EventError, Code: ErrorCodeUsage, Message: "Usage: git wr go <id>"}
+	if err := WriteEventJSON(&b, e); err != nil {
+		t.Fatalf("WriteEventJSON() error: %v", err)
+	}
+	want := `{"schemaVersion":1,"type":"error","time":"0001-01-01T00:00:00Z","code":"usage","message":"Usage: git wr go <id>"}` + "\n"
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Fatalf("WriteEventJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -67,6 +67,9 @@ func (m *Manager) OpenEditor(ctx context.Context, identifier, editorOverride str
 	if err != nil {
 		return 1, err
 	}
+	if spec.Env, err = m.portEnv(ctx, target); err != nil {
+		return 1, err
+	}
 
 	return adapters.Exec(ctx, spec, io.Stdin, io.Stdout, io.Stderr)
 }
@@ -98,6 +101,9 @@ func (m *Manager) RunAI(ctx context.Context, identifier, toolOverride string, ar
 	if err != nil {
 		return 1, err
 	}
+	if spec.Env, err = m.portEnv(ctx, target); err != nil {
+		return 1, err
+	}
 
 	return adapters.Exec(ctx, spec, io.Stdin, io.Stdout, io.Stderr)
 }
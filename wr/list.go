@@ -0,0 +1,207 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/internal/gitx"
+)
+
+// ListSchemaVersion is the version of the JSON schema written by WriteListJSON and WriteListNDJSON.
+//
+// It is bumped whenever a field is removed or changes meaning; adding fields does not bump it.
+const ListSchemaVersion = 1
+
+// listDetailsConcurrency bounds the number of worktrees inspected at once.
+const listDetailsConcurrency = 8
+
+// ListOptions configures Manager.ListWithOptions.
+type ListOptions struct {
+	// Details collects HEAD, upstream, ahead/behind, dirty counts and last-commit time for every worktree.
+	//
+	// This runs `git status` and `git log` in each worktree, so it is noticeably slower than a plain list.
+	Details bool
+	// Tag, if non-empty, keeps only the worktrees tagged Tag (see Manager.Tag).
+	Tag string
+}
+
+func (m *Manager) collectListDetails(ctx context.Context, entries []ListEntry) error {
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, listDetailsConcurrency)
+		errs = make([]error, len(entries))
+	)
+
+	for i := range entries {
+		if !hasWorktreeDir(entries[i]) {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			errs[i] = m.fillListDetails(ctx, &entries[i])
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (m *Manager) fillListDetails(ctx context.Context, e *ListEntry) error {
+	st, err := gitx.StatusGit(ctx, m.git, e.Target.Path)
+	if err != nil {
+		return fmt.Errorf("inspect worktree %s: %w", e.Target.Path, err)
+	}
+	lastCommit, err := gitx.LastCommitTimeGit(ctx, m.git, e.Target.Path)
+	if err != nil {
+		return fmt.Errorf("inspect worktree %s: %w", e.Target.Path, err)
+	}
+
+	e.Head = st.Head
+	e.Upstream = st.Upstream
+	e.Ahead = st.Ahead
+	e.Behind = st.Behind
+	e.Dirty = DirtyCounts{
+		Staged:     st.Staged,
+		Unstaged:   st.Unstaged,
+		Untracked:  st.Untracked,
+		Conflicted: st.Conflicted,
+	}
+	e.LastCommit = lastCommit
+	return nil
+}
+
+// hasWorktreeDir reports whether e points to a checked-out worktree that git commands can run in.
+func hasWorktreeDir(e ListEntry) bool {
+	if e.Status == WorktreeStatusMissing || e.Status == WorktreeStatusPrunable {
+		return false
+	}
+	// Locked worktrees may live on removable media that is not mounted.
+	fi, err := os.Stat(e.Target.Path)
+	return err == nil && fi.IsDir()
+}
+
+type listJSONEntry struct {
+	ID         int            `json:"id,omitempty"`
+	Path       string         `json:"path"`
+	Branch     string         `json:"branch"`
+	IsMain     bool           `json:"isMain"`
+	Status     WorktreeStatus `json:"status"`
+	LockReason string         `json:"lockReason,omitempty"`
+	Ports      []int          `json:"ports,omitempty"`
+
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	FromRef     string   `json:"fromRef,omitempty"`
+	CreatedBy   string   `json:"createdBy,omitempty"`
+	CreatedAt   string   `json:"createdAt,omitempty"`
+
+	Head           string      `json:"head,omitempty"`
+	Upstream       string      `json:"upstream,omitempty"`
+	Ahead          int         `json:"ahead"`
+	Behind         int         `json:"behind"`
+	Dirty          DirtyCounts `json:"dirty"`
+	LastCommitTime string      `json:"lastCommitTime,omitempty"`
+}
+
+func newListJSONEntry(e ListEntry) listJSONEntry {
+	out := listJSONEntry{
+		ID:         e.ID,
+		Path:       e.Target.Path,
+		Branch:     e.Target.Branch,
+		IsMain:     e.Target.IsMain,
+		Status:     e.Status,
+		LockReason: e.LockReason,
+		Ports:      e.Ports.Ports(),
+
+		Description: e.Meta.Description,
+		Tags:        e.Meta.Tags,
+		FromRef:     e.Meta.FromRef,
+		CreatedBy:   e.Meta.CreatedBy,
+
+		Head:     e.Head,
+		Upstream: e.Upstream,
+		Ahead:    e.Ahead,
+		Behind:   e.Behind,
+		Dirty:    e.Dirty,
+	}
+	if !e.Meta.CreatedAt.IsZero() {
+		out.CreatedAt = e.Meta.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if !e.LastCommit.IsZero() {
+		out.LastCommitTime = e.LastCommit.UTC().Format(time.RFC3339)
+	}
+	return out
+}
+
+// WriteListJSON renders entries to w as a single JSON document:
+//
+//	{"schemaVersion": 1, "worktrees": [{"path": ..., "branch": ..., ...}]}
+func WriteListJSON(w io.Writer, entries []ListEntry) error {
+	doc := struct {
+		SchemaVersion int             `json:"schemaVersion"`
+		Worktrees     []listJSONEntry `json:"worktrees"`
+	}{
+		SchemaVersion: ListSchemaVersion,
+		Worktrees:     make([]listJSONEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		doc.Worktrees = append(doc.Worktrees, newListJSONEntry(e))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// WriteListNDJSON renders entries to w as newline-delimited JSON, one worktree per line.
+//
+// Every line carries "schemaVersion" so that consumers can process lines independently.
+func WriteListNDJSON(w io.Writer, entries []ListEntry) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		line := struct {
+			SchemaVersion int `json:"schemaVersion"`
+			listJSONEntry
+		}{
+			SchemaVersion: ListSchemaVersion,
+			listJSONEntry: newListJSONEntry(e),
+		}
+		if err := enc.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
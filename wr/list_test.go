@@ -0,0 +1,135 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+)
+
+func TestManagerListWithDetails(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	target, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(target.Path, "untracked.txt"), []byte("x\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(untracked.txt): %v", err)
+	}
+
+	plain, err := m.List(t.Context())
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	for _, e := range plain {
+		if e.Head != "" {
+			t.Fatalf("expected List() to skip details, got %+v", e)
+		}
+	}
+
+	entries, err := m.ListWithOptions(t.Context(), ListOptions{Details: true})
+	if err != nil {
+		t.Fatalf("ListWithOptions() error: %v", err)
+	}
+
+	var found bool
+	for _, e := range entries {
+		if e.Head == "" {
+			t.Fatalf("expected HEAD SHA for %q, got %+v", e.Target.Path, e)
+		}
+		if e.LastCommit.IsZero() {
+			t.Fatalf("expected last commit time for %q, got %+v", e.Target.Path, e)
+		}
+		if e.Target.Path != target.Path {
+			continue
+		}
+		found = true
+		if diff := cmp.Diff(DirtyCounts{Untracked: 1}, e.Dirty); diff != "" {
+			t.Fatalf("dirty counts mismatch (-want +got):\n%s", diff)
+		}
+	}
+	if !found {
+		t.Fatalf("expected entry for %q, got %+v", target.Path, entries)
+	}
+
+	var doc struct {
+		SchemaVersion int `json:"schemaVersion"`
+		Worktrees     []struct {
+			Path   string      `json:"path"`
+			Head   string      `json:"head"`
+			Dirty  DirtyCounts `json:"dirty"`
+			IsMain bool        `json:"isMain"`
+		} `json:"worktrees"`
+	}
+	var buf bytes.Buffer
+	if err := WriteListJSON(&buf, entries); err != nil {
+		t.Fatalf("WriteListJSON() error: %v", err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v\n%s", err, buf.String())
+	}
+	if diff := cmp.Diff(ListSchemaVersion, doc.SchemaVersion); diff != "" {
+		t.Fatalf("schema version mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(len(entries), len(doc.Worktrees)); diff != "" {
+		t.Fatalf("worktree count mismatch (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := WriteListNDJSON(&buf, entries); err != nil {
+		t.Fatalf("WriteListNDJSON() error: %v", err)
+	}
+	var lines int
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var line struct {
+			SchemaVersion int    `json:"schemaVersion"`
+			Path          string `json:"path"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			t.Fatalf("json.Unmarshal(line) error: %v\n%s", err, sc.Text())
+		}
+		if line.SchemaVersion != ListSchemaVersion || line.Path == "" {
+			t.Fatalf("unexpected NDJSON line: %s", sc.Text())
+		}
+		lines++
+	}
+	if diff := cmp.Diff(len(entries), lines); diff != "" {
+		t.Fatalf("NDJSON line count mismatch (-want +got):\n%s", diff)
+	}
+}
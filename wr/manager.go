@@ -20,17 +20,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	git "github.com/go-git/go-git/v6"
 
 	"github.com/zchee/git-worktree-runner/internal/config"
 	"github.com/zchee/git-worktree-runner/internal/gitcmd"
 	"github.com/zchee/git-worktree-runner/internal/gitx"
-	"github.com/zchee/git-worktree-runner/internal/naming"
+	"github.com/zchee/git-worktree-runner/internal/hooks"
+	"github.com/zchee/git-worktree-runner/internal/pathutil"
+	"github.com/zchee/git-worktree-runner/internal/ports"
+	"github.com/zchee/git-worktree-runner/internal/registry"
 	"github.com/zchee/git-worktree-runner/internal/repoctx"
 	"github.com/zchee/git-worktree-runner/internal/worktrees"
 )
@@ -49,6 +57,13 @@ const (
 // ErrTargetNotFound is returned when a worktree cannot be resolved from an identifier.
 var ErrTargetNotFound = errors.New("worktree target not found")
 
+// ErrAmbiguousTarget is returned when an identifier matches more than one worktree.
+var ErrAmbiguousTarget = errors.New("ambiguous worktree target")
+
+// ErrDeclined reports that the user declined a confirmation prompt or canceled a selection.
+// Manager methods do not return it themselves; front ends wrap it so that callers can tell it apart.
+var ErrDeclined = errors.New("canceled")
+
 // ManagerOptions configures Manager construction.
 type ManagerOptions struct {
 	// StartDir is where repository discovery begins. If empty, os.Getwd is used.
@@ -57,6 +72,17 @@ type ManagerOptions struct {
 	Yes bool
 	// Env overrides environment variables for config resolution (tests).
 	Env map[string]string
+
+	// HookStdout and HookStderr receive the output of hooks as it is produced, each line prefixed with
+	// "[hook <phase> #<n>] ". Nil discards the output; failing hooks still report stderr via hooks.HookError.
+	HookStdout io.Writer
+	HookStderr io.Writer
+	// HookVerbose writes each hook command to HookStderr before it runs.
+	HookVerbose bool
+
+	// OnEvent, if non-nil, receives an Event as operations progress: worktrees created and removed,
+	// files copied, and hooks started and failed. It is called synchronously from the operation.
+	OnEvent func(Event)
 }
 
 // Manager manages git worktree operations for a single repository.
@@ -69,6 +95,11 @@ type Manager struct {
 	cfg config.Resolver
 
 	yes bool
+
+	hookOpts  hooks.Options
+	onEvent   func(Event)
+	portStore ports.Store
+	registry  registry.Store
 }
 
 // Target identifies a worktree or the main repository.
@@ -78,10 +109,47 @@ type Target struct {
 	Branch string
 }
 
-// ListEntry is one row in `git wr list --porcelain`.
+// ListEntry is one row in `git wr list`.
 type ListEntry struct {
+	// ID is the stable numeric identifier accepted by ResolveTarget: 1 for the main repository, and 0 for
+	// worktrees not created by CreateWorktree.
+	ID     int
 	Target Target
 	Status WorktreeStatus
+
+	// LockReason is the reason recorded by `git worktree lock --reason`, if any.
+	LockReason string
+	// Ports is the port block assigned to the worktree (see Manager.Ports), or the zero block.
+	Ports PortBlock
+	// Meta is the metadata recorded in the worktree registry, or the zero WorktreeMeta.
+	Meta WorktreeMeta
+
+	// The fields below are populated only by ListWithOptions with ListOptions.Details set.
+
+	// Head is the commit SHA of HEAD, or empty for an unborn branch.
+	Head string
+	// Upstream is the upstream branch of Target.Branch (for example "origin/main"), or empty.
+	Upstream string
+	// Ahead and Behind count commits relative to Upstream.
+	Ahead  int
+	Behind int
+	// Dirty counts uncommitted changes in the worktree.
+	Dirty DirtyCounts
+	// LastCommit is the committer time of HEAD, or the zero time for an unborn branch.
+	LastCommit time.Time
+}
+
+// DirtyCounts counts uncommitted changes in a worktree.
+type DirtyCounts struct {
+	Staged     int `json:"staged"`
+	Unstaged   int `json:"unstaged"`
+	Untracked  int `json:"untracked"`
+	Conflicted int `json:"conflicted"`
+}
+
+// Total returns the number of changed files.
+func (d DirtyCounts) Total() int {
+	return d.Staged + d.Unstaged + d.Untracked + d.Conflicted
 }
 
 // NewManager discovers the repository from opts.StartDir and returns a Manager bound to that repository.
@@ -107,6 +175,14 @@ func NewManager(ctx context.Context, opts ManagerOptions) (*Manager, error) {
 		repo:    repo,
 		cfg:     config.New(g, rc.MainRoot, opts.Env),
 		yes:     opts.Yes,
+		hookOpts: hooks.Options{
+			Stdout:  opts.HookStdout,
+			Stderr:  opts.HookStderr,
+			Verbose: opts.HookVerbose,
+		},
+		onEvent:   opts.OnEvent,
+		portStore: ports.NewStore(rc.CommonDir),
+		registry:  registry.NewStore(rc.CommonDir),
 	}, nil
 }
 
@@ -121,11 +197,25 @@ func (m *Manager) currentBranch(ctx context.Context, dir string) (string, error)
 
 // ResolveTarget resolves identifier into a concrete Target.
 //
-// identifier can be:
-// - "1" for the main repository
-// - a branch name
+// identifier can be, in order of precedence:
+// - "#<id>" for the worktree with that numeric ID as shown by `git wr list` ("#1" is the main repository)
+// - "." or a path (absolute, or starting with "./" or "../") inside a worktree
+// - "tag:<name>" for the single worktree tagged <name> (see Manager.Tag)
+// - a branch name, including the original name recorded when the worktree was created
 // - a worktree directory name (after sanitization and optional prefix)
+// - a worktree path relative to the worktrees dir, or its last element when unique (see wr.worktrees.pathTemplate)
+// - a bare numeric ID, so that a branch or directory named like an ID still resolves to itself
+// - an unambiguous prefix of a branch name or worktree directory name
+//
+// Identifiers that match several worktrees return ErrAmbiguousTarget listing the candidates.
 func (m *Manager) ResolveTarget(ctx context.Context, identifier string) (Target, error) {
+	return m.resolveTarget(ctx, identifier, true)
+}
+
+// resolveTarget implements ResolveTarget. Unless allowPrefix is set, an identifier that only matches a
+// prefix returns ErrTargetNotFound naming the worktree it would have matched, so that destructive commands
+// never act on a worktree the user did not spell out.
+func (m *Manager) resolveTarget(ctx context.Context, identifier string, allowPrefix bool) (Target, error) {
 	if identifier == "" {
 		return Target{}, fmt.Errorf("%w: empty identifier", ErrTargetNotFound)
 	}
@@ -152,32 +242,98 @@ func (m *Manager) ResolveTarget(ctx context.Context, identifier string) (Target,
 			Detached: branch == gitx.DetachedBranch,
 		}
 	}
+	mainTarget := Target{IsMain: true, Path: m.repoCtx.MainRoot, Branch: mainEntry.Branch}
 
-	if identifier == "1" {
-		return Target{IsMain: true, Path: m.repoCtx.MainRoot, Branch: mainEntry.Branch}, nil
+	targetAt := func(dir string) (Target, error) {
+		if dir == m.repoCtx.MainRoot {
+			return mainTarget, nil
+		}
+		if e, ok := byPath[dir]; ok {
+			return Target{IsMain: false, Path: e.Path, Branch: e.Branch}, nil
+		}
+		branch, err := m.currentBranch(ctx, dir)
+		if err != nil {
+			return Target{}, err
+		}
+		return Target{IsMain: false, Path: dir, Branch: branch}, nil
+	}
+	ambiguous := func(dirs []string) error {
+		candidates := make([]string, 0, len(dirs))
+		for _, dir := range dirs {
+			if e, ok := byPath[dir]; ok && e.Branch != "" {
+				candidates = append(candidates, fmt.Sprintf("%s (%s)", e.Branch, dir))
+			} else {
+				candidates = append(candidates, dir)
+			}
+		}
+		return fmt.Errorf("%w: %q matches %s", ErrAmbiguousTarget, identifier, strings.Join(candidates, ", "))
 	}
 
-	if mainEntry.Branch != gitx.DetachedBranch && identifier == mainEntry.Branch {
-		return Target{IsMain: true, Path: m.repoCtx.MainRoot, Branch: mainEntry.Branch}, nil
+	// byID resolves a numeric ID. ok is false when s is not a number or no existing worktree has that ID.
+	byID := func(s string) (target Target, ok bool, err error) {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			return Target{}, false, nil
+		}
+		if id == 1 {
+			return mainTarget, true, nil
+		}
+		if id < registry.FirstID {
+			return Target{}, false, nil
+		}
+		dir, err := m.worktreeByID(id)
+		if err != nil || dir == "" {
+			return Target{}, false, err
+		}
+		target, err = targetAt(dir)
+		return target, err == nil, err
 	}
 
-	paths, err := worktrees.ResolvePaths(ctx, m.cfg)
-	if err != nil {
-		return Target{}, err
+	if s, ok := strings.CutPrefix(identifier, "#"); ok && s != "" && strings.Trim(s, "0123456789") == "" {
+		target, ok, err := byID(s)
+		if err != nil {
+			return Target{}, err
+		}
+		if !ok {
+			return Target{}, fmt.Errorf("%w: no worktree has ID %s", ErrTargetNotFound, s)
+		}
+		return target, nil
 	}
 
-	candidate := filepath.Join(paths.BaseDir, paths.Prefix+naming.SanitizeBranchName(identifier))
-	if e, ok := byPath[candidate]; ok {
-		return Target{IsMain: false, Path: e.Path, Branch: e.Branch}, nil
+	if isPathIdentifier(identifier) {
+		dir, err := m.worktreeContaining(entries, identifier)
+		if err != nil {
+			return Target{}, err
+		}
+		return targetAt(dir)
 	}
-	if _, err := os.Stat(candidate); err == nil {
-		branch, err := m.currentBranch(ctx, candidate)
+
+	if tag, ok := strings.CutPrefix(identifier, TagPrefix); ok {
+		tagged, err := m.taggedWorktrees(tag)
 		if err != nil {
 			return Target{}, err
 		}
-		return Target{IsMain: false, Path: candidate, Branch: branch}, nil
+		switch len(tagged) {
+		case 0:
+			return Target{}, fmt.Errorf("%w: no worktree is tagged %q", ErrTargetNotFound, tag)
+		case 1:
+			return targetAt(tagged[0])
+		default:
+			return Target{}, ambiguous(tagged)
+		}
+	}
+
+	if mainEntry.Branch != gitx.DetachedBranch && identifier == mainEntry.Branch {
+		return mainTarget, nil
+	}
+
+	paths, err := worktrees.ResolvePaths(ctx, m.cfg)
+	if err != nil {
+		return Target{}, err
 	}
 
+	// Branch names win over directory names: with wr.worktrees.naming=hash, the directory named after one
+	// branch can belong to another branch that sanitizes to the same name.
 	for _, e := range entries {
 		if e.Path == m.repoCtx.MainRoot {
 			continue
@@ -186,12 +342,104 @@ func (m *Manager) ResolveTarget(ctx context.Context, identifier string) (Target,
 			return Target{IsMain: false, Path: e.Path, Branch: e.Branch}, nil
 		}
 	}
+	recorded, err := m.recordedWorktrees(identifier)
+	if err != nil {
+		return Target{}, err
+	}
+	switch len(recorded) {
+	case 0:
+	case 1:
+		return targetAt(recorded[0])
+	default:
+		return Target{}, ambiguous(recorded)
+	}
+
+	candidate, err := paths.WorktreePath(identifier, "", time.Now())
+	if err != nil {
+		return Target{}, err
+	}
+	if _, ok := byPath[candidate]; ok {
+		return targetAt(candidate)
+	}
+	if _, err := os.Stat(candidate); err == nil {
+		return targetAt(candidate)
+	}
+
+	// Templates with per-creation fields (such as {{.Date}}) cannot be rendered back from the identifier,
+	// so also accept the path relative to the worktrees dir, or its last element when that is unique.
+	dirs, err := layoutDirs(paths)
+	if err != nil {
+		return Target{}, err
+	}
+	for _, e := range entries {
+		if e.Path != m.repoCtx.MainRoot && !slices.Contains(dirs, e.Path) {
+			dirs = append(dirs, e.Path)
+		}
+	}
+	var matches []string
+	for _, dir := range dirs {
+		rel, ok := paths.RelPath(dir)
+		if !ok {
+			continue
+		}
+		if rel == filepath.ToSlash(identifier) {
+			return targetAt(dir)
+		}
+		if path.Base(rel) == identifier {
+			matches = append(matches, dir)
+		}
+	}
+	switch len(matches) {
+	case 0:
+	case 1:
+		return targetAt(matches[0])
+	default:
+		return Target{}, ambiguous(matches)
+	}
+
+	if target, ok, err := byID(identifier); err != nil || ok {
+		return target, err
+	}
+
+	// Finally, accept a prefix of a branch name or directory name that matches exactly one worktree.
+	matches = nil
+	if mainEntry.Branch != gitx.DetachedBranch && strings.HasPrefix(mainEntry.Branch, identifier) {
+		matches = append(matches, m.repoCtx.MainRoot)
+	}
+	for _, dir := range dirs {
+		e, registered := byPath[dir]
+		if registered && !e.Detached && strings.HasPrefix(e.Branch, identifier) || strings.HasPrefix(filepath.Base(dir), identifier) {
+			matches = append(matches, dir)
+		}
+	}
+	switch len(matches) {
+	case 0:
+	case 1:
+		target, err := targetAt(matches[0])
+		if err != nil || allowPrefix {
+			return target, err
+		}
+		return Target{}, fmt.Errorf("%w: %s (prefix of %s; use the full name)", ErrTargetNotFound, identifier, target.Branch)
+	default:
+		slices.Sort(matches)
+		return Target{}, ambiguous(matches)
+	}
 
 	return Target{}, fmt.Errorf("%w: %s", ErrTargetNotFound, identifier)
 }
 
+// Branches returns the local branch names followed by the remote-tracking branch names.
+func (m *Manager) Branches(ctx context.Context) ([]string, error) {
+	return gitx.BranchesGit(ctx, m.git, m.repoCtx.MainRoot)
+}
+
 // List returns all known worktrees, including the main repository worktree.
 func (m *Manager) List(ctx context.Context) ([]ListEntry, error) {
+	return m.ListWithOptions(ctx, ListOptions{})
+}
+
+// ListWithOptions is like List but allows collecting per-worktree details.
+func (m *Manager) ListWithOptions(ctx context.Context, opts ListOptions) ([]ListEntry, error) {
 	porcelainEntries, err := worktrees.ListPorcelain(ctx, m.repoCtx.CommonDir, m.repoCtx.MainRoot, m.currentBranch)
 	if err != nil {
 		return nil, err
@@ -210,19 +458,13 @@ func (m *Manager) List(ctx context.Context) ([]ListEntry, error) {
 	}
 
 	// Match upstream behavior: also list directories under the configured worktrees dir.
-	if dirs, err := os.ReadDir(paths.BaseDir); err == nil {
-		for _, d := range dirs {
-			if !d.IsDir() {
-				continue
-			}
-			if !strings.HasPrefix(d.Name(), paths.Prefix) {
-				continue
-			}
-			seenPaths[filepath.Join(paths.BaseDir, d.Name())] = struct{}{}
-		}
-	} else if !errors.Is(err, os.ErrNotExist) {
+	dirs, err := layoutDirs(paths)
+	if err != nil {
 		return nil, err
 	}
+	for _, dir := range dirs {
+		seenPaths[dir] = struct{}{}
+	}
 
 	var out []ListEntry
 	for path := range seenPaths {
@@ -254,7 +496,8 @@ func (m *Manager) List(ctx context.Context) ([]ListEntry, error) {
 				Path:   path,
 				Branch: branch,
 			},
-			Status: status,
+			Status:     status,
+			LockReason: e.LockReason,
 		})
 	}
 
@@ -268,5 +511,80 @@ func (m *Manager) List(ctx context.Context) ([]ListEntry, error) {
 		return out[i].Target.Path < out[j].Target.Path
 	})
 
+	if err := m.fillPorts(out); err != nil {
+		return nil, err
+	}
+	if err := m.fillMeta(out); err != nil {
+		return nil, err
+	}
+	if opts.Tag != "" {
+		out = slices.DeleteFunc(out, func(e ListEntry) bool { return !e.Meta.HasTag(opts.Tag) })
+	}
+
+	if opts.Details {
+		if err := m.collectListDetails(ctx, out); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// layoutDirs returns the directories under the worktrees dir that match wr.worktrees.pathTemplate.
+func layoutDirs(paths worktrees.Paths) ([]string, error) {
+	pattern, err := paths.Glob()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("list worktree directories: %w", err)
+	}
+	out := matches[:0]
+	for _, match := range matches {
+		if fi, err := os.Stat(match); err == nil && fi.IsDir() {
+			out = append(out, match)
+		}
+	}
 	return out, nil
 }
+
+// isPathIdentifier reports whether identifier should be resolved as a file system path rather than a name.
+// Branch names can contain '/', so relative paths must start with "./" or "../".
+func isPathIdentifier(identifier string) bool {
+	if identifier == "." || identifier == ".." || filepath.IsAbs(identifier) {
+		return true
+	}
+	for _, sep := range []string{"/", string(filepath.Separator)} {
+		if strings.HasPrefix(identifier, "."+sep) || strings.HasPrefix(identifier, ".."+sep) {
+			return true
+		}
+	}
+	return false
+}
+
+// worktreeContaining returns the root of the worktree that contains p. "." is the worktree the Manager
+// was created in; other relative paths are resolved against ManagerOptions.StartDir.
+func (m *Manager) worktreeContaining(entries []worktrees.PorcelainEntry, p string) (string, error) {
+	if p == "." {
+		return m.repoCtx.WorktreeRoot, nil
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(m.repoCtx.StartDir, p)
+	}
+	abs, err := pathutil.Canonicalize(p)
+	if err != nil {
+		return "", err
+	}
+
+	best := ""
+	for _, e := range entries {
+		if pathutil.IsWithin(e.Path, abs) && len(e.Path) > len(best) {
+			best = e.Path
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("%w: %s is not inside a worktree", ErrTargetNotFound, p)
+	}
+	return best, nil
+}
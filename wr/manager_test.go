@@ -17,8 +17,10 @@
 package wr
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -151,3 +153,177 @@ func TestManagerListIncludesMissingBaseDirEntries(t *testing.T) {
 		t.Fatalf("expected missing entry for %q, got %+v", missingDir, entries)
 	}
 }
+
+func TestManagerResolveTargetIdentifiers(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+	repoDir, err := pathutil.Canonicalize(repoDir)
+	if err != nil {
+		t.Fatalf("Canonicalize(repoDir): %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	mainBranch, err := gitx.CurrentBranchGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("CurrentBranchGit() error: %v", err)
+	}
+
+	targets := map[string]Target{"main": {IsMain: true, Path: repoDir, Branch: mainBranch}}
+	for _, branch := range []string{"feature-auth", "feature-billing", "bugfix"} {
+		target, err := m.CreateWorktree(t.Context(), branch, CreateWorktreeOptions{FromCurrent: true, NoCopy: true})
+		if err != nil {
+			t.Fatalf("CreateWorktree(%s) error: %v", branch, err)
+		}
+		targets[branch] = target
+	}
+	if err := os.MkdirAll(filepath.Join(targets["bugfix"].Path, "sub", "dir"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	// IDs are stable: removing a worktree does not renumber the others, and its ID is not reused.
+	if err := m.Remove(t.Context(), []string{"feature-billing"}, RemoveWorktreeOptions{Force: true}); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	billing, err := m.CreateWorktree(t.Context(), "feature-billing", CreateWorktreeOptions{NoCopy: true})
+	if err != nil {
+		t.Fatalf("CreateWorktree(feature-billing) error: %v", err)
+	}
+	targets["feature-billing"] = billing
+
+	entries, err := m.List(t.Context())
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	gotIDs := map[string]int{}
+	for _, e := range entries {
+		gotIDs[e.Target.Branch] = e.ID
+	}
+	wantIDs := map[string]int{mainBranch: 1, "feature-auth": 2, "bugfix": 4, "feature-billing": 5}
+	if diff := cmp.Diff(wantIDs, gotIDs); diff != "" {
+		t.Fatalf("IDs mismatch (-want +got):\n%s", diff)
+	}
+
+	// A branch named like an ID (here ID 2, feature-auth) wins over the bare ID.
+	numeric, err := m.CreateWorktree(t.Context(), "2", CreateWorktreeOptions{FromCurrent: true, NoCopy: true})
+	if err != nil {
+		t.Fatalf("CreateWorktree(2) error: %v", err)
+	}
+	targets["2"] = numeric
+
+	tests := map[string]struct {
+		identifier string
+		startDir   string
+		want       string
+		wantErr    error
+	}{
+		"success: main id": {
+			identifier: "1",
+			want:       "main",
+		},
+		"success: numeric id": {
+			identifier: "4",
+			want:       "bugfix",
+		},
+		"success: hash main id": {
+			identifier: "#1",
+			want:       "main",
+		},
+		"success: hash id": {
+			identifier: "#2",
+			want:       "feature-auth",
+		},
+		"success: branch named like an id": {
+			identifier: "2",
+			want:       "2",
+		},
+		"success: branch prefix": {
+			identifier: "feature-a",
+			want:       "feature-auth",
+		},
+		"success: directory prefix": {
+			identifier: "bug",
+			want:       "bugfix",
+		},
+		"success: current worktree": {
+			identifier: ".",
+			startDir:   filepath.Join(targets["bugfix"].Path, "sub", "dir"),
+			want:       "bugfix",
+		},
+		"success: relative path": {
+			identifier: "../../../feature-auth",
+			startDir:   filepath.Join(targets["bugfix"].Path, "sub", "dir"),
+			want:       "feature-auth",
+		},
+		"success: absolute path inside a worktree": {
+			identifier: filepath.Join(targets["bugfix"].Path, "sub"),
+			want:       "bugfix",
+		},
+		"success: main repo path": {
+			identifier: ".",
+			want:       "main",
+		},
+		"error: ambiguous prefix": {
+			identifier: "feature",
+			wantErr:    ErrAmbiguousTarget,
+		},
+		"error: unknown id": {
+			identifier: "3",
+			wantErr:    ErrTargetNotFound,
+		},
+		"error: unknown hash id": {
+			identifier: "#3",
+			wantErr:    ErrTargetNotFound,
+		},
+		"error: path outside worktrees": {
+			identifier: filepath.Dir(repoDir),
+			wantErr:    ErrTargetNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := m
+			if tc.startDir != "" {
+				var err error
+				m, err = NewManager(t.Context(), ManagerOptions{StartDir: tc.startDir})
+				if err != nil {
+					t.Fatalf("NewManager() error: %v", err)
+				}
+			}
+
+			got, err := m.ResolveTarget(t.Context(), tc.identifier)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v (target=%+v)", tc.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveTarget(%q) error: %v", tc.identifier, err)
+			}
+			if diff := cmp.Diff(targets[tc.want], got); diff != "" {
+				t.Fatalf("target mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	_, err = m.ResolveTarget(t.Context(), "feature")
+	if err == nil || !strings.Contains(err.Error(), "feature-auth (") || !strings.Contains(err.Error(), "feature-billing (") {
+		t.Fatalf("expected ambiguous error to list both candidates, got %v", err)
+	}
+
+	// Remove does not act on a worktree the identifier only prefixes.
+	err = m.Remove(t.Context(), []string{"bug"}, RemoveWorktreeOptions{Force: true})
+	if !errors.Is(err, ErrTargetNotFound) || !strings.Contains(err.Error(), "prefix of bugfix") {
+		t.Fatalf("expected Remove(bug) to reject the prefix, got %v", err)
+	}
+	if _, err := os.Stat(targets["bugfix"].Path); err != nil {
+		t.Fatalf("expected bugfix worktree to remain: %v", err)
+	}
+}
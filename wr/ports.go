@@ -0,0 +1,176 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zchee/git-worktree-runner/internal/ports"
+	"github.com/zchee/git-worktree-runner/internal/worktrees"
+)
+
+// EnvFileName is the file written into a worktree when wr.ports.envFile is enabled.
+const EnvFileName = ".env.wr"
+
+// PortBlock is a contiguous range of TCP ports assigned to one worktree.
+type PortBlock = ports.Block
+
+const defaultPortBase = 4000
+
+// portSettings holds the wr.ports.* configuration.
+type portSettings struct {
+	base    int
+	count   int
+	envFile bool
+}
+
+func (s portSettings) enabled() bool {
+	return s.count > 0
+}
+
+func (m *Manager) portSettings(ctx context.Context) (portSettings, error) {
+	var s portSettings
+
+	countRaw, err := m.cfg.Default(ctx, "wr.ports.count", "", "0", "ports.count")
+	if err != nil {
+		return portSettings{}, err
+	}
+	if s.count, err = strconv.Atoi(countRaw); err != nil || s.count < 0 {
+		return portSettings{}, fmt.Errorf("invalid wr.ports.count %q: expected a non-negative integer", countRaw)
+	}
+
+	baseRaw, err := m.cfg.Default(ctx, "wr.ports.base", "", strconv.Itoa(defaultPortBase), "ports.base")
+	if err != nil {
+		return portSettings{}, err
+	}
+	if s.base, err = strconv.Atoi(baseRaw); err != nil || s.base < 1 || s.base > 65535 {
+		return portSettings{}, fmt.Errorf("invalid wr.ports.base %q: expected a port number", baseRaw)
+	}
+
+	envFileRaw, err := m.cfg.Default(ctx, "wr.ports.envFile", "", "false", "ports.envFile")
+	if err != nil {
+		return portSettings{}, err
+	}
+	if s.envFile, err = strconv.ParseBool(envFileRaw); err != nil {
+		return portSettings{}, fmt.Errorf("invalid wr.ports.envFile %q: expected true or false", envFileRaw)
+	}
+
+	return s, nil
+}
+
+// Ports returns the port block assigned to target.
+//
+// Blocks are assigned by CreateWorktree and persisted in the git common dir, so a worktree keeps the same
+// ports until it is removed. It returns the zero PortBlock when port allocation is disabled
+// (wr.ports.count is 0) or target has no block, like the main repository and worktrees created before
+// allocation was enabled.
+func (m *Manager) Ports(ctx context.Context, target Target) (PortBlock, error) {
+	s, err := m.portSettings(ctx)
+	if err != nil {
+		return PortBlock{}, err
+	}
+	if !s.enabled() {
+		return PortBlock{}, nil
+	}
+	block, _, err := m.portStore.Get(target.Path)
+	return block, err
+}
+
+// portEnv returns the port environment variables for target, or nil when it has no port block.
+func (m *Manager) portEnv(ctx context.Context, target Target) ([]string, error) {
+	block, err := m.Ports(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return block.Env(), nil
+}
+
+// fillPorts sets ListEntry.Ports from the persisted allocations without assigning new blocks.
+func (m *Manager) fillPorts(entries []ListEntry) error {
+	all, err := m.portStore.All()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		entries[i].Ports = all[filepath.Clean(entries[i].Target.Path)]
+	}
+	return nil
+}
+
+// prunePorts releases the port blocks of worktrees that git no longer knows about.
+func (m *Manager) prunePorts(ctx context.Context) error {
+	entries, err := worktrees.ListPorcelain(ctx, m.repoCtx.CommonDir, m.repoCtx.MainRoot, m.currentBranch)
+	if err != nil {
+		return err
+	}
+	live := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		live[filepath.Clean(e.Path)] = struct{}{}
+	}
+	return m.portStore.Prune(ctx, func(worktreePath string) bool {
+		_, ok := live[worktreePath]
+		return ok
+	})
+}
+
+// writeEnvFile writes the port block to EnvFileName in worktreePath and makes sure the file is
+// ignored by git in every worktree of the repository.
+func (m *Manager) writeEnvFile(worktreePath string, block PortBlock) error {
+	var b strings.Builder
+	b.WriteString("# Generated by git wr. Ports assigned to this worktree.\n")
+	for _, kv := range block.Env() {
+		b.WriteString(kv)
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, EnvFileName), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", EnvFileName, err)
+	}
+
+	return ensureExcluded(filepath.Join(m.repoCtx.CommonDir, "info", "exclude"), "/"+EnvFileName)
+}
+
+// ensureExcluded appends pattern to the git exclude file at path unless it is already listed.
+func ensureExcluded(path, pattern string) error {
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	for line := range strings.Lines(string(b)) {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		b = append(b, '\n')
+	}
+	b = append(b, pattern+"\n"...)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
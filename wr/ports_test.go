@@ -0,0 +1,155 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/gitx"
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+)
+
+func TestManagerPorts(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	for key, value := range map[string]string{
+		"wr.ports.count":   "3",
+		"wr.ports.base":    "5100",
+		"wr.ports.envFile": "true",
+	} {
+		if _, err := g.Run(t.Context(), repoDir, "config", "--local", key, value); err != nil {
+			t.Fatalf("git config %s: %v", key, err)
+		}
+	}
+
+	// A worktree added outside git wr has no block, and reading ports must not assign one.
+	external := filepath.Join(t.TempDir(), "external")
+	if _, err := g.Run(t.Context(), repoDir, "worktree", "add", "-b", "external", external); err != nil {
+		t.Fatalf("git worktree add: %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	externalTarget, err := m.ResolveTarget(t.Context(), "external")
+	if err != nil {
+		t.Fatalf("ResolveTarget(external) error: %v", err)
+	}
+	if block, err := m.Ports(t.Context(), externalTarget); err != nil || !block.IsZero() {
+		t.Fatalf("Ports(external) = %+v, %v; want no block", block, err)
+	}
+	if _, err := m.RunAll(t.Context(), []string{"true"}, RunAllOptions{Stdout: io.Discard, Stderr: io.Discard}); err != nil {
+		t.Fatalf("RunAll() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".git", "wr-ports.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected reading ports to leave wr-ports.json alone, got %v", err)
+	}
+
+	targets := map[string]Target{}
+	for _, branch := range []string{"feature-a", "feature-b"} {
+		target, err := m.CreateWorktree(t.Context(), branch, CreateWorktreeOptions{
+			FromCurrent: true,
+			NoCopy:      true,
+		})
+		if err != nil {
+			t.Fatalf("CreateWorktree(%s) error: %v", branch, err)
+		}
+		targets[branch] = target
+	}
+
+	b, err := os.ReadFile(filepath.Join(targets["feature-b"].Path, EnvFileName))
+	if err != nil {
+		t.Fatalf("ReadFile(%s): %v", EnvFileName, err)
+	}
+	if !strings.Contains(string(b), "WR_PORT=5103\n") {
+		t.Fatalf("expected WR_PORT=5103 in %s, got:\n%s", EnvFileName, b)
+	}
+	st, err := gitx.StatusGit(t.Context(), g, targets["feature-b"].Path)
+	if err != nil {
+		t.Fatalf("StatusGit() error: %v", err)
+	}
+	if st.Dirty() {
+		t.Fatalf("expected %s to be ignored, got status %+v", EnvFileName, st)
+	}
+
+	entries, err := m.List(t.Context())
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	got := map[string][]int{}
+	for _, e := range entries {
+		got[e.Target.Branch] = e.Ports.Ports()
+	}
+	mainBranch, err := gitx.CurrentBranchGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("CurrentBranchGit() error: %v", err)
+	}
+	want := map[string][]int{
+		mainBranch:  {},
+		"external":  {},
+		"feature-a": {5100, 5101, 5102},
+		"feature-b": {5103, 5104, 5105},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ports mismatch (-want +got):\n%s", diff)
+	}
+
+	argv := []string{"sh", "-c", "echo $WR_PORT"}
+	if runtime.GOOS == "windows" {
+		argv = []string{"cmd.exe", "/C", "echo %WR_PORT%"}
+	}
+	var stdout bytes.Buffer
+	if _, err := m.Run(t.Context(), "feature-a", argv, RunOptions{IO: ExecIO{Stdout: &stdout}}); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if diff := cmp.Diff("5100", strings.TrimSpace(stdout.String())); diff != "" {
+		t.Fatalf("WR_PORT mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := m.Remove(t.Context(), []string{"feature-a"}, RemoveWorktreeOptions{Force: true}); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	target, err := m.CreateWorktree(t.Context(), "feature-c", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree(feature-c) error: %v", err)
+	}
+	block, err := m.Ports(t.Context(), target)
+	if err != nil {
+		t.Fatalf("Ports() error: %v", err)
+	}
+	if diff := cmp.Diff(PortBlock{Base: 5100, Count: 3}, block); diff != "" {
+		t.Fatalf("expected the released block to be reused (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/internal/naming"
+	"github.com/zchee/git-worktree-runner/internal/registry"
+	"github.com/zchee/git-worktree-runner/internal/worktrees"
+)
+
+// TagPrefix marks an identifier that selects a worktree by tag, for example "tag:review".
+// Git branch names cannot contain ':', so it never clashes with a branch.
+const TagPrefix = "tag:"
+
+// WorktreeMeta is the metadata recorded for a worktree in <git-common-dir>/wr-registry.json.
+type WorktreeMeta = registry.Entry
+
+// Meta returns the metadata recorded for target, or the zero WorktreeMeta.
+func (m *Manager) Meta(target Target) (WorktreeMeta, error) {
+	e, _, err := m.registry.Get(target.Path)
+	return e, err
+}
+
+// Describe sets the description of the worktree identified by identifier. An empty text clears it.
+func (m *Manager) Describe(ctx context.Context, identifier, text string) (Target, error) {
+	return m.updateMeta(ctx, identifier, func(e *WorktreeMeta) error {
+		e.Description = strings.TrimSpace(text)
+		return nil
+	})
+}
+
+// Tag adds tags to the worktree identified by identifier, or removes them when remove is true.
+func (m *Manager) Tag(ctx context.Context, identifier string, tags []string, remove bool) (Target, error) {
+	for _, tag := range tags {
+		if err := validateTag(tag); err != nil {
+			return Target{}, err
+		}
+	}
+	return m.updateMeta(ctx, identifier, func(e *WorktreeMeta) error {
+		if remove {
+			e.RemoveTags(tags...)
+		} else {
+			e.AddTags(tags...)
+		}
+		return nil
+	})
+}
+
+func (m *Manager) updateMeta(ctx context.Context, identifier string, fn func(e *WorktreeMeta) error) (Target, error) {
+	target, err := m.ResolveTarget(ctx, identifier)
+	if err != nil {
+		return Target{}, err
+	}
+	err = m.registry.Update(ctx, target.Path, func(e *WorktreeMeta) error {
+		if e.Branch == "" && !target.IsMain {
+			e.Branch = target.Branch
+		}
+		return fn(e)
+	})
+	return target, err
+}
+
+// validateTag rejects tags that could not be passed back as a "tag:<name>" identifier or a --tag value.
+func validateTag(tag string) error {
+	if tag == "" || strings.HasPrefix(tag, "-") || strings.ContainsFunc(tag, func(r rune) bool {
+		return r <= ' ' || r == ',' || r == ':' || r == 0x7f
+	}) {
+		return fmt.Errorf("invalid tag %q: tags must be non-empty and cannot start with '-' or contain spaces, ',' or ':'", tag)
+	}
+	return nil
+}
+
+// taggedWorktrees returns the paths of the worktrees tagged with tag that still exist, sorted.
+func (m *Manager) taggedWorktrees(tag string) ([]string, error) {
+	all, err := m.registry.All()
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for worktreePath, e := range all {
+		if !e.HasTag(tag) {
+			continue
+		}
+		if _, err := os.Stat(worktreePath); err == nil {
+			out = append(out, worktreePath)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// worktreeByID returns the path of the worktree with the given ID, or "" when no existing worktree has it.
+func (m *Manager) worktreeByID(id int) (string, error) {
+	all, err := m.registry.All()
+	if err != nil {
+		return "", err
+	}
+	for worktreePath, e := range all {
+		if e.ID != id {
+			continue
+		}
+		if _, err := os.Stat(worktreePath); err == nil {
+			return worktreePath, nil
+		}
+	}
+	return "", nil
+}
+
+// fillMeta sets ListEntry.Meta and ListEntry.ID from the registry.
+//
+// It only reads the registry: IDs are assigned by CreateWorktree, so worktrees added with plain
+// `git worktree add` have no ID.
+func (m *Manager) fillMeta(entries []ListEntry) error {
+	all, err := m.registry.All()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		entries[i].Meta = all[filepath.Clean(entries[i].Target.Path)]
+		entries[i].ID = entries[i].Meta.ID
+		if entries[i].Target.IsMain {
+			entries[i].ID = 1
+		}
+	}
+	return nil
+}
+
+// newWorktreePath returns the directory for a new worktree of branch.
+//
+// With the hash and escape naming schemes, a directory that already exists and is not recorded as
+// belonging to branch is treated as a collision, and the name gets a short hash of the branch instead.
+func (m *Manager) newWorktreePath(paths worktrees.Paths, branch, suffix string) (string, error) {
+	now := time.Now()
+	worktreePath, err := paths.WorktreePath(branch, suffix, now)
+	if err != nil || paths.Naming == naming.SchemeSanitize {
+		return worktreePath, err
+	}
+
+	if _, err := os.Stat(worktreePath); err != nil {
+		return worktreePath, nil
+	}
+	owner, ok, err := m.registry.Get(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	if ok && owner.Branch == branch {
+		return worktreePath, nil
+	}
+	return paths.DisambiguatedPath(branch, suffix, now)
+}
+
+// recordedWorktrees returns the paths recorded for branch by CreateWorktree that still exist, sorted.
+func (m *Manager) recordedWorktrees(branch string) ([]string, error) {
+	all, err := m.registry.All()
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for worktreePath, e := range all {
+		if e.Branch != branch || worktreePath == m.repoCtx.MainRoot {
+			continue
+		}
+		if _, err := os.Stat(worktreePath); err == nil {
+			out = append(out, worktreePath)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// pruneRegistry forgets the metadata of worktrees that git no longer knows about.
+func (m *Manager) pruneRegistry(ctx context.Context) error {
+	entries, err := worktrees.ListPorcelain(ctx, m.repoCtx.CommonDir, m.repoCtx.MainRoot, m.currentBranch)
+	if err != nil {
+		return err
+	}
+	live := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		live[filepath.Clean(e.Path)] = struct{}{}
+	}
+	return m.registry.Prune(ctx, func(worktreePath string) bool {
+		_, ok := live[worktreePath]
+		return ok
+	})
+}
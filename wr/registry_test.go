@@ -0,0 +1,200 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+)
+
+func TestManagerRegistry(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir, Env: map[string]string{"USER": "alice"}})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	a, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+		Description: "  fix the login flow ",
+		Tags:        []string{"review", "ai"},
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree(feature-a) error: %v", err)
+	}
+	b, err := m.CreateWorktree(t.Context(), "feature-b", CreateWorktreeOptions{FromCurrent: true, NoCopy: true})
+	if err != nil {
+		t.Fatalf("CreateWorktree(feature-b) error: %v", err)
+	}
+
+	meta, err := m.Meta(a)
+	if err != nil {
+		t.Fatalf("Meta() error: %v", err)
+	}
+	if meta.CreatedAt.IsZero() || meta.FromRef == "" {
+		t.Fatalf("expected creation time and ref, got %+v", meta)
+	}
+	head, err := g.Run(t.Context(), repoDir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("git rev-parse HEAD: %v", err)
+	}
+	want := WorktreeMeta{
+		ID:          2,
+		Branch:      "feature-a",
+		FromRef:     meta.FromRef,
+		FromCommit:  strings.TrimSpace(head.Stdout),
+		CreatedBy:   "alice",
+		CreatedAt:   meta.CreatedAt,
+		Description: "fix the login flow",
+		Tags:        []string{"ai", "review"},
+	}
+	if diff := cmp.Diff(want, meta); diff != "" {
+		t.Fatalf("meta mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err := m.ResolveTarget(t.Context(), "tag:review")
+	if err != nil {
+		t.Fatalf("ResolveTarget(tag:review) error: %v", err)
+	}
+	if diff := cmp.Diff(a, got); diff != "" {
+		t.Fatalf("target mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := m.Tag(t.Context(), "feature-b", []string{"review"}, false); err != nil {
+		t.Fatalf("Tag() error: %v", err)
+	}
+	if _, err := m.ResolveTarget(t.Context(), "tag:review"); !errors.Is(err, ErrAmbiguousTarget) {
+		t.Fatalf("expected %v, got %v", ErrAmbiguousTarget, err)
+	}
+	if _, err := m.ResolveTarget(t.Context(), "tag:nope"); !errors.Is(err, ErrTargetNotFound) {
+		t.Fatalf("expected %v, got %v", ErrTargetNotFound, err)
+	}
+	if _, err := m.Tag(t.Context(), "feature-b", []string{"bad tag"}, false); err == nil {
+		t.Fatalf("expected error for invalid tag")
+	}
+
+	if _, err := m.Tag(t.Context(), "feature-a", []string{"review"}, true); err != nil {
+		t.Fatalf("Tag(remove) error: %v", err)
+	}
+	if _, err := m.Describe(t.Context(), "feature-b", "second try"); err != nil {
+		t.Fatalf("Describe() error: %v", err)
+	}
+
+	entries, err := m.ListWithOptions(t.Context(), ListOptions{Tag: "review"})
+	if err != nil {
+		t.Fatalf("ListWithOptions() error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Target != b || entries[0].Meta.Description != "second try" {
+		t.Fatalf("unexpected tagged entries: %+v", entries)
+	}
+
+	if err := m.Remove(t.Context(), []string{"feature-b"}, RemoveWorktreeOptions{Force: true}); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if meta, err := m.Meta(b); err != nil || meta.Branch != "" {
+		t.Fatalf("expected registry entry to be removed, got %+v (err=%v)", meta, err)
+	}
+}
+
+func TestManagerListDoesNotWriteRegistry(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	external := filepath.Join(t.TempDir(), "external")
+	if _, err := g.Run(t.Context(), repoDir, "worktree", "add", "-b", "external", external); err != nil {
+		t.Fatalf("git worktree add: %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	entries, err := m.List(t.Context())
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	gotIDs := map[string]int{}
+	for _, e := range entries {
+		gotIDs[e.Target.Branch] = e.ID
+	}
+	if gotIDs["external"] != 0 {
+		t.Fatalf("expected no ID for a worktree added outside git-wr, got %v", gotIDs)
+	}
+
+	for _, name := range []string{"wr-registry.json", "wr-registry.lock"} {
+		if _, err := os.Stat(filepath.Join(repoDir, ".git", name)); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected List to leave %s untouched, got %v", name, err)
+		}
+	}
+}
+
+func TestManagerResolveTargetAmbiguousRecordedBranch(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	// Both worktrees were created for "feature" and their branches renamed afterwards.
+	var dirs []string
+	for i, renamed := range []string{"feature-a", "feature-b"} {
+		target, err := m.CreateWorktree(t.Context(), "feature", CreateWorktreeOptions{
+			FromCurrent: true,
+			NoCopy:      true,
+			NameSuffix:  strconv.Itoa(i),
+		})
+		if err != nil {
+			t.Fatalf("CreateWorktree(feature) error: %v", err)
+		}
+		if _, err := g.Run(t.Context(), target.Path, "branch", "-m", "feature", renamed); err != nil {
+			t.Fatalf("git branch -m: %v", err)
+		}
+		dirs = append(dirs, target.Path)
+	}
+
+	_, err = m.ResolveTarget(t.Context(), "feature")
+	if !errors.Is(err, ErrAmbiguousTarget) {
+		t.Fatalf("expected %v, got %v", ErrAmbiguousTarget, err)
+	}
+	for _, dir := range dirs {
+		if !strings.Contains(err.Error(), dir) {
+			t.Fatalf("expected candidate %s in %q", dir, err)
+		}
+	}
+}
@@ -17,16 +17,26 @@
 package wr
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/zchee/git-worktree-runner/internal/lineprefix"
 )
 
 // RunOptions configures Manager.Run.
 type RunOptions struct {
 	// Env is a list of KEY=VALUE pairs appended to the current process environment.
+	// The port variables of the target (see Manager.Ports) are added first, so Env can override them.
 	Env []string
 
 	IO ExecIO
@@ -45,27 +55,195 @@ func (m *Manager) Run(ctx context.Context, identifier string, argv []string, opt
 		return 1, err
 	}
 
+	env, err := m.portEnv(ctx, target)
+	if err != nil {
+		return 1, err
+	}
+
+	return runInDir(ctx, target.Path, argv, append(env, opts.Env...), opts.IO)
+}
+
+// RunAllOptions configures Manager.RunAll.
+type RunAllOptions struct {
+	// Identifiers selects the targets to run in. When empty, every worktree returned by List
+	// (including the main repository) is targeted, skipping missing and prunable entries.
+	Identifiers []string
+	// Filter is a glob (path.Match syntax) matched against the branch name and the worktree
+	// directory name. It only applies when Identifiers is empty.
+	Filter string
+	// Concurrency bounds the number of commands running at once. Defaults to runtime.NumCPU().
+	Concurrency int
+
+	// Env is a list of KEY=VALUE pairs appended to the current process environment,
+	// after the port variables of each target.
+	Env []string
+
+	// Stdout and Stderr receive the output of every command, line by line, prefixed with
+	// "[<branch>] ". They default to os.Stdout and os.Stderr. Commands never read stdin.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// RunResult is the outcome of a command in one target.
+type RunResult struct {
+	Target   Target
+	ExitCode int
+	Duration time.Duration
+	// Err is set when the command could not be started (for example, the program was not found).
+	Err error
+}
+
+// RunAll executes argv in several targets concurrently and returns one result per target, in target order.
+//
+// A non-zero exit status in a target is reported in its RunResult and is not an error.
+func (m *Manager) RunAll(ctx context.Context, argv []string, opts RunAllOptions) ([]RunResult, error) {
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("no command specified")
+	}
+
+	targets, err := m.runAllTargets(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	// Read the port blocks up front so that the allocation file is not read by every goroutine.
+	envs := make([][]string, len(targets))
+	for i, target := range targets {
+		portEnv, err := m.portEnv(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		envs[i] = append(portEnv, opts.Env...)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		sem     = make(chan struct{}, concurrency)
+		results = make([]RunResult, len(targets))
+	)
+	for i, target := range targets {
+		results[i] = RunResult{Target: target, ExitCode: 1}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			prefix := "[" + target.Branch + "] "
+			out := lineprefix.NewWriter(&mu, stdout, prefix)
+			errOut := lineprefix.NewWriter(&mu, stderr, prefix)
+
+			start := time.Now()
+			exitCode, err := runInDir(ctx, target.Path, argv, envs[i], ExecIO{
+				Stdin:  bytes.NewReader(nil),
+				Stdout: out,
+				Stderr: errOut,
+			})
+			out.Flush()
+			errOut.Flush()
+
+			results[i].ExitCode = exitCode
+			results[i].Duration = time.Since(start)
+			results[i].Err = err
+		}()
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func (m *Manager) runAllTargets(ctx context.Context, opts RunAllOptions) ([]Target, error) {
+	if len(opts.Identifiers) > 0 {
+		seen := map[string]struct{}{}
+		var targets []Target
+		for _, id := range opts.Identifiers {
+			tgt, err := m.ResolveTarget(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := seen[tgt.Path]; ok {
+				continue
+			}
+			seen[tgt.Path] = struct{}{}
+			targets = append(targets, tgt)
+		}
+		return targets, nil
+	}
+
+	if opts.Filter != "" {
+		if _, err := path.Match(opts.Filter, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", opts.Filter, err)
+		}
+	}
+
+	entries, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []Target
+	for _, e := range entries {
+		if e.Status == WorktreeStatusMissing || e.Status == WorktreeStatusPrunable {
+			continue
+		}
+		if opts.Filter != "" {
+			branchOK, _ := path.Match(opts.Filter, e.Target.Branch)
+			dirOK, _ := path.Match(opts.Filter, filepath.Base(e.Target.Path))
+			if !branchOK && !dirOK {
+				continue
+			}
+		}
+		targets = append(targets, e.Target)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: no worktrees match", ErrTargetNotFound)
+	}
+	return targets, nil
+}
+
+func runInDir(ctx context.Context, dir string, argv, env []string, stdio ExecIO) (exitCode int, err error) {
 	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...) //nolint:gosec // This command intentionally executes user-provided programs.
-	cmd.Dir = target.Path
+	cmd.Dir = dir
 
-	if opts.IO.Stdin != nil {
-		cmd.Stdin = opts.IO.Stdin
+	if stdio.Stdin != nil {
+		cmd.Stdin = stdio.Stdin
 	} else {
 		cmd.Stdin = os.Stdin
 	}
-	if opts.IO.Stdout != nil {
-		cmd.Stdout = opts.IO.Stdout
+	if stdio.Stdout != nil {
+		cmd.Stdout = stdio.Stdout
 	} else {
 		cmd.Stdout = os.Stdout
 	}
-	if opts.IO.Stderr != nil {
-		cmd.Stderr = opts.IO.Stderr
+	if stdio.Stderr != nil {
+		cmd.Stderr = stdio.Stderr
 	} else {
 		cmd.Stderr = os.Stderr
 	}
 
-	if len(opts.Env) != 0 {
-		cmd.Env = append(os.Environ(), opts.Env...)
+	if len(env) != 0 {
+		cmd.Env = append(os.Environ(), env...)
 	}
 
 	if err := cmd.Run(); err == nil {
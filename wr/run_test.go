@@ -128,3 +128,118 @@ func TestManagerRun(t *testing.T) {
 		})
 	}
 }
+
+func TestManagerRunAll(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	for _, branch := range []string{"feature-a", "feature-b", "other"} {
+		if _, err := m.CreateWorktree(t.Context(), branch, CreateWorktreeOptions{
+			FromCurrent: true,
+			NoCopy:      true,
+		}); err != nil {
+			t.Fatalf("CreateWorktree(%s) error: %v", branch, err)
+		}
+	}
+
+	tests := map[string]struct {
+		opts RunAllOptions
+		argv []string
+
+		wantMain     bool
+		wantBranches []string
+		wantExit     int
+		wantStdout   []string
+		wantErr      bool
+	}{
+		"success: all worktrees": {
+			opts:         RunAllOptions{Concurrency: 2},
+			argv:         []string{"git", "branch", "--show-current"},
+			wantMain:     true,
+			wantBranches: []string{"feature-a", "feature-b", "other"},
+			wantStdout:   []string{"[feature-a] feature-a\n", "[feature-b] feature-b\n", "[other] other\n"},
+		},
+		"success: filter by branch glob": {
+			opts:         RunAllOptions{Filter: "feature-*"},
+			argv:         []string{"git", "branch", "--show-current"},
+			wantBranches: []string{"feature-a", "feature-b"},
+			wantStdout:   []string{"[feature-a] feature-a\n", "[feature-b] feature-b\n"},
+		},
+		"success: explicit identifiers are deduplicated": {
+			opts:         RunAllOptions{Identifiers: []string{"other", "other", "feature-a"}},
+			argv:         []string{"git", "branch", "--show-current"},
+			wantBranches: []string{"other", "feature-a"},
+		},
+		"success: non-zero exit is reported per target": {
+			opts:         RunAllOptions{Identifiers: []string{"feature-a"}},
+			argv:         []string{"git", "rev-parse", "--verify", "refs/heads/does-not-exist"},
+			wantBranches: []string{"feature-a"},
+			wantExit:     128,
+		},
+		"error: filter without matches": {
+			opts:    RunAllOptions{Filter: "nope-*"},
+			argv:    []string{"git", "status"},
+			wantErr: true,
+		},
+		"error: no argv": {
+			opts:    RunAllOptions{},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			tc.opts.Stdout = &stdout
+			tc.opts.Stderr = &stderr
+
+			got, err := m.RunAll(t.Context(), tc.argv, tc.opts)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil (results=%+v)", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunAll() error: %v", err)
+			}
+
+			var gotMain bool
+			var gotBranches []string
+			for _, res := range got {
+				if res.Target.IsMain {
+					gotMain = true
+				} else {
+					gotBranches = append(gotBranches, res.Target.Branch)
+				}
+				if res.Err != nil {
+					t.Fatalf("unexpected start error for %s: %v", res.Target.Branch, res.Err)
+				}
+				if diff := cmp.Diff(tc.wantExit, res.ExitCode); diff != "" {
+					t.Fatalf("exit code mismatch for %s (-want +got):\n%s", res.Target.Branch, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.wantMain, gotMain); diff != "" {
+				t.Fatalf("main target mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantBranches, gotBranches); diff != "" {
+				t.Fatalf("targets mismatch (-want +got):\n%s", diff)
+			}
+
+			for _, want := range tc.wantStdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Fatalf("stdout mismatch: expected substring %q, got %q", want, stdout.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/zchee/git-worktree-runner/internal/gitx"
+)
+
+// StatusEntry describes the state of one worktree relative to its upstream and the default branch.
+type StatusEntry struct {
+	ListEntry
+
+	// DefaultBranch is the resolved default branch: the wr.defaultBranch git config key, or the branch
+	// detected from origin when it is "auto" (see resolveDefaultBranch).
+	DefaultBranch string
+	// DefaultRef is the ref used for the default-branch comparison: the local branch when it exists,
+	// otherwise origin/<DefaultBranch>. It is empty when neither exists, in which case the fields below are unset.
+	DefaultRef string
+	// AheadOfDefault and BehindDefault count commits relative to DefaultRef.
+	AheadOfDefault int
+	BehindDefault  int
+	// BaseCommit is the commit the worktree's branch started from: the first entry of the branch reflog,
+	// or the commit recorded in the registry when the worktree was created. It is empty when unknown.
+	BaseCommit string
+	// Merged reports whether the branch has commits of its own and all of them are on the default branch:
+	// HEAD is reachable from DefaultRef, and the branch reflog records a commit made on the branch between
+	// BaseCommit and HEAD. A worktree whose branch never moved, or was only fast-forwarded or reset to
+	// commits made elsewhere, is not merged, since there is nothing to show that its work landed.
+	Merged bool
+}
+
+// SafeToRemove reports whether removing the worktree cannot lose work: it is not the main repository,
+// not locked, has no uncommitted changes, and every commit is already on the default branch.
+func (e StatusEntry) SafeToRemove() bool {
+	if e.Target.IsMain || e.Status == WorktreeStatusLocked {
+		return false
+	}
+	if !hasWorktreeDir(e.ListEntry) {
+		return false
+	}
+	return e.Dirty.Total() == 0 && e.DefaultRef != "" && e.Merged
+}
+
+// Status reports dirty state, ahead/behind counts and merge state for every worktree returned by List.
+//
+// Missing and prunable worktrees are included with only their list fields populated.
+func (m *Manager) Status(ctx context.Context) ([]StatusEntry, error) {
+	entries, err := m.ListWithOptions(ctx, ListOptions{Details: true})
+	if err != nil {
+		return nil, err
+	}
+
+	defaultBranch, defaultRef, err := m.defaultBranchRef(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]StatusEntry, 0, len(entries))
+	for _, e := range entries {
+		se := StatusEntry{
+			ListEntry:     e,
+			DefaultBranch: defaultBranch,
+		}
+		if defaultRef != "" && e.Head != "" && !(e.Target.IsMain && e.Target.Branch == defaultBranch) {
+			ahead, behind, err := gitx.AheadBehindGit(ctx, m.git, m.repoCtx.MainRoot, defaultRef, e.Head)
+			if err != nil {
+				return nil, err
+			}
+			se.DefaultRef = defaultRef
+			se.AheadOfDefault = ahead
+			se.BehindDefault = behind
+			if se.BaseCommit, err = m.baseCommit(ctx, e); err != nil {
+				return nil, err
+			}
+			if ahead == 0 && se.BaseCommit != "" && se.BaseCommit != e.Head {
+				if se.Merged, err = m.hasOwnCommits(ctx, e, se.BaseCommit); err != nil {
+					return nil, err
+				}
+			}
+		}
+		out = append(out, se)
+	}
+
+	return out, nil
+}
+
+// baseCommit returns StatusEntry.BaseCommit for e.
+func (m *Manager) baseCommit(ctx context.Context, e ListEntry) (string, error) {
+	if e.Target.Branch != "" && e.Target.Branch != gitx.DetachedBranch {
+		start, err := gitx.BranchStartGit(ctx, m.git, m.repoCtx.MainRoot, e.Target.Branch)
+		if err != nil || start != "" {
+			return start, err
+		}
+	}
+	return e.Meta.FromCommit, nil
+}
+
+// hasOwnCommits reports whether a commit between base and the HEAD of e was made on its branch, rather than
+// reached by fast-forwarding or resetting the branch to commits made elsewhere.
+func (m *Manager) hasOwnCommits(ctx context.Context, e ListEntry, base string) (bool, error) {
+	if e.Target.Branch == "" || e.Target.Branch == gitx.DetachedBranch {
+		return false, nil
+	}
+	own, err := gitx.BranchOwnCommitsGit(ctx, m.git, m.repoCtx.MainRoot, e.Target.Branch)
+	if err != nil || len(own) == 0 {
+		return false, err
+	}
+	res, err := m.git.Run(ctx, m.repoCtx.MainRoot, "rev-list", base+".."+e.Head)
+	if err != nil {
+		return false, fmt.Errorf("git rev-list %s..%s: %w", base, e.Head, err)
+	}
+	commits := strings.Fields(res.Stdout)
+	return slices.ContainsFunc(own, func(c string) bool { return slices.Contains(commits, c) }), nil
+}
+
+// defaultBranchRef resolves the default branch name and the ref to compare against.
+//
+// ref is "refs/heads/<name>" when the local branch exists, "refs/remotes/origin/<name>" when only the
+// remote-tracking branch exists, and empty otherwise.
+func (m *Manager) defaultBranchRef(ctx context.Context) (name, ref string, err error) {
+	name, err = m.resolveDefaultBranch(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, candidate := range []string{plumbingLocalBranchRef(name), plumbingRemoteBranchRef("origin", name)} {
+		ok, err := m.refExists(ctx, candidate)
+		if err != nil {
+			return "", "", err
+		}
+		if ok {
+			return name, candidate, nil
+		}
+	}
+	return name, "", nil
+}
+
+type statusJSONEntry struct {
+	listJSONEntry
+
+	DefaultBranch  string `json:"defaultBranch"`
+	DefaultRef     string `json:"defaultRef,omitempty"`
+	BaseCommit     string `json:"baseCommit,omitempty"`
+	AheadOfDefault int    `json:"aheadOfDefault"`
+	BehindDefault  int    `json:"behindDefault"`
+	Merged         bool   `json:"merged"`
+	SafeToRemove   bool   `json:"safeToRemove"`
+}
+
+// WriteStatusJSON renders entries to w as a single JSON document using the same schema version as WriteListJSON.
+func WriteStatusJSON(w io.Writer, entries []StatusEntry) error {
+	doc := struct {
+		SchemaVersion int               `json:"schemaVersion"`
+		Worktrees     []statusJSONEntry `json:"worktrees"`
+	}{
+		SchemaVersion: ListSchemaVersion,
+		Worktrees:     make([]statusJSONEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		doc.Worktrees = append(doc.Worktrees, statusJSONEntry{
+			listJSONEntry:  newListJSONEntry(e.ListEntry),
+			DefaultBranch:  e.DefaultBranch,
+			DefaultRef:     e.DefaultRef,
+			BaseCommit:     e.BaseCommit,
+			AheadOfDefault: e.AheadOfDefault,
+			BehindDefault:  e.BehindDefault,
+			Merged:         e.Merged,
+			SafeToRemove:   e.SafeToRemove(),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The git-worktree-runner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package wr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zchee/git-worktree-runner/internal/gitx"
+	"github.com/zchee/git-worktree-runner/internal/testutil"
+)
+
+func TestManagerStatus(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	mainBranch, err := gitx.CurrentBranchGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("CurrentBranchGit() error: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "wr.defaultBranch", mainBranch); err != nil {
+		t.Fatalf("git config wr.defaultBranch: %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	targets := map[string]Target{}
+	for _, branch := range []string{"merged", "fresh", "forwarded", "ahead", "dirty"} {
+		target, err := m.CreateWorktree(t.Context(), branch, CreateWorktreeOptions{
+			FromCurrent: true,
+			NoCopy:      true,
+		})
+		if err != nil {
+			t.Fatalf("CreateWorktree(%s) error: %v", branch, err)
+		}
+		targets[branch] = target
+	}
+	for _, branch := range []string{"merged", "ahead"} {
+		if _, err := g.Run(t.Context(), targets[branch].Path, "commit", "--allow-empty", "-m", branch); err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+	}
+	if _, err := g.Run(t.Context(), repoDir, "merge", "--ff-only", "merged"); err != nil {
+		t.Fatalf("git merge merged: %v", err)
+	}
+	// "forwarded" catches up with the default branch without any work of its own.
+	if _, err := g.Run(t.Context(), targets["forwarded"].Path, "merge", "--ff-only", mainBranch); err != nil {
+		t.Fatalf("git merge %s: %v", mainBranch, err)
+	}
+	if err := os.WriteFile(filepath.Join(targets["dirty"].Path, "README.md"), []byte("changed\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(README.md): %v", err)
+	}
+
+	entries, err := m.Status(t.Context())
+	if err != nil {
+		t.Fatalf("Status() error: %v", err)
+	}
+
+	type summary struct {
+		AheadOfDefault int
+		Merged         bool
+		Dirty          DirtyCounts
+		SafeToRemove   bool
+	}
+	got := map[string]summary{}
+	for _, e := range entries {
+		if diff := cmp.Diff(mainBranch, e.DefaultBranch); diff != "" {
+			t.Fatalf("default branch mismatch (-want +got):\n%s", diff)
+		}
+		got[e.Target.Branch] = summary{
+			AheadOfDefault: e.AheadOfDefault,
+			Merged:         e.Merged,
+			Dirty:          e.Dirty,
+			SafeToRemove:   e.SafeToRemove(),
+		}
+	}
+
+	want := map[string]summary{
+		mainBranch:  {},
+		"merged":    {Merged: true, SafeToRemove: true},
+		"fresh":     {},
+		"forwarded": {},
+		"ahead":     {AheadOfDefault: 1},
+		"dirty":     {Dirty: DirtyCounts{Unstaged: 1}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("status mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/zchee/git-worktree-runner/internal/copy"
@@ -29,13 +30,16 @@ import (
 	"github.com/zchee/git-worktree-runner/internal/gitx"
 	"github.com/zchee/git-worktree-runner/internal/hooks"
 	"github.com/zchee/git-worktree-runner/internal/lock"
-	"github.com/zchee/git-worktree-runner/internal/naming"
+	"github.com/zchee/git-worktree-runner/internal/pathutil"
 	"github.com/zchee/git-worktree-runner/internal/worktrees"
 )
 
 // ErrForceRequiresName is returned when CreateWorktreeOptions.Force is true and NameSuffix is empty.
 var ErrForceRequiresName = errors.New("--force requires --name to distinguish worktrees")
 
+// ErrPathCollision is returned when the path of a new worktree overlaps an existing worktree.
+var ErrPathCollision = errors.New("worktree path collides with an existing worktree")
+
 // ErrInvalidTrackMode is returned when CreateWorktreeOptions.TrackMode is unknown.
 var ErrInvalidTrackMode = errors.New("invalid track mode")
 
@@ -58,45 +62,104 @@ type CreateWorktreeOptions struct {
 	NoFetch     bool
 	Force       bool
 	NameSuffix  string
+
+	// Description and Tags are recorded in the worktree registry (see Manager.Describe and Manager.Tag).
+	Description string
+	Tags        []string
+
+	// CopyProgress, if non-nil, receives progress updates while files are copied into the new worktree.
+	// File and directory copies report separately, each starting from zero.
+	CopyProgress func(CopyProgress)
 }
 
-// CreateWorktree creates a new linked worktree.
-func (m *Manager) CreateWorktree(ctx context.Context, branch string, opts CreateWorktreeOptions) (Target, error) {
-	if branch == "" {
-		return Target{}, fmt.Errorf("branch name required")
-	}
+// BranchAction is how CreateWorktree obtains the branch of a new worktree.
+type BranchAction string
 
-	if opts.Force && opts.NameSuffix == "" {
-		return Target{}, ErrForceRequiresName
+const (
+	// BranchActionTrackRemote creates a local branch tracking origin/<branch>.
+	BranchActionTrackRemote BranchAction = "track-remote"
+	// BranchActionExisting checks out the existing local branch.
+	BranchActionExisting BranchAction = "existing"
+	// BranchActionNew creates the branch from CreatePlan.FromRef.
+	BranchActionNew BranchAction = "new"
+)
+
+// PlannedHook is a hook command that would run, and the directory it would run in.
+type PlannedHook struct {
+	Phase string
+	// Index is the 1-based position of the hook within its phase, as in Event.Index.
+	Index   int
+	Command string
+	Dir     string
+}
+
+// CreatePlan describes what CreateWorktree would do, as computed by PlanCreateWorktree.
+type CreatePlan struct {
+	Branch string
+	Path   string
+
+	// FromRef is the ref a new branch starts from: CreateWorktreeOptions.FromRef, the current branch with
+	// FromCurrent, or the default branch. It is only used when BranchAction is BranchActionNew.
+	FromRef      string
+	BranchAction BranchAction
+	// Fetch reports whether origin is fetched first. The plan reflects the refs before that fetch.
+	Fetch bool
+
+	// Copied lists the files and directories that would be copied from the main repository.
+	Copied []string
+	// Hooks lists the preCreate and postCreate hooks in the order they would run.
+	Hooks []PlannedHook
+}
+
+// PlanCreateWorktree computes what CreateWorktree would do with the same arguments, without creating,
+// fetching, copying or running anything. Errors that CreateWorktree would report before touching the
+// repository, such as path collisions or a missing remote branch, are reported here as well.
+func (m *Manager) PlanCreateWorktree(ctx context.Context, branch string, opts CreateWorktreeOptions) (CreatePlan, error) {
+	trackMode, _, worktreePath, err := m.prepareCreate(ctx, branch, opts)
+	if err != nil {
+		return CreatePlan{}, err
 	}
 
-	trackMode := opts.TrackMode
-	if trackMode == "" {
-		trackMode = TrackModeAuto
+	plan := CreatePlan{
+		Branch: branch,
+		Path:   worktreePath,
+		Fetch:  !opts.NoFetch,
 	}
-	switch trackMode {
-	case TrackModeAuto, TrackModeRemote, TrackModeLocal, TrackModeNone:
-	default:
-		return Target{}, fmt.Errorf("%w: %q", ErrInvalidTrackMode, trackMode)
+	if plan.FromRef, err = m.resolveFromRef(ctx, opts); err != nil {
+		return CreatePlan{}, err
+	}
+	if plan.BranchAction, _, err = m.branchAction(ctx, branch, trackMode, plan.FromRef); err != nil {
+		return CreatePlan{}, err
 	}
 
-	paths, err := worktrees.ResolvePaths(ctx, m.cfg)
-	if err != nil {
-		return Target{}, err
+	if !opts.NoCopy {
+		if plan.Copied, err = m.copyIntoWorktree(ctx, worktreePath, nil, true); err != nil {
+			return CreatePlan{}, err
+		}
 	}
 
-	worktreeName := naming.SanitizeBranchName(branch)
-	if opts.NameSuffix != "" {
-		worktreeName = worktreeName + "-" + opts.NameSuffix
+	pre, err := m.plannedHooks(ctx, hooks.PhasePreCreate, m.repoCtx.MainRoot)
+	if err != nil {
+		return CreatePlan{}, err
 	}
-	worktreePath := filepath.Join(paths.BaseDir, paths.Prefix+worktreeName)
+	post, err := m.plannedHooks(ctx, hooks.PhasePostCreate, worktreePath)
+	if err != nil {
+		return CreatePlan{}, err
+	}
+	plan.Hooks = append(pre, post...)
 
-	if _, err := os.Stat(worktreePath); err == nil {
-		return Target{}, fmt.Errorf("worktree already exists at %s", worktreePath)
+	return plan, nil
+}
+
+// CreateWorktree creates a new linked worktree.
+func (m *Manager) CreateWorktree(ctx context.Context, branch string, opts CreateWorktreeOptions) (Target, error) {
+	trackMode, paths, worktreePath, err := m.prepareCreate(ctx, branch, opts)
+	if err != nil {
+		return Target{}, err
 	}
 
-	if err := os.MkdirAll(paths.BaseDir, 0o755); err != nil {
-		return Target{}, fmt.Errorf("create worktrees base dir %q: %w", paths.BaseDir, err)
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0o755); err != nil {
+		return Target{}, fmt.Errorf("create worktrees dir %q: %w", filepath.Dir(worktreePath), err)
 	}
 
 	lockPath := filepath.Join(m.repoCtx.CommonDir, "wr.lock")
@@ -106,35 +169,43 @@ func (m *Manager) CreateWorktree(ctx context.Context, branch string, opts Create
 	}
 	defer func() { _ = l.Release() }()
 
-	if !opts.NoFetch {
-		// Match upstream behavior: fetch is best-effort.
-		_, _ = m.git.Run(ctx, m.repoCtx.MainRoot, "fetch", "origin")
+	// Ports are assigned before preCreate so that every hook phase sees the same environment.
+	// The block is released again if the worktree is not created.
+	portCfg, err := m.portSettings(ctx)
+	if err != nil {
+		return Target{}, err
 	}
-
-	fromRef := opts.FromRef
-	if fromRef == "" {
-		if opts.FromCurrent {
-			current, err := gitx.CurrentBranchGit(ctx, m.git, m.repoCtx.MainRoot)
-			if err != nil {
-				return Target{}, err
-			}
-			if current != gitx.DetachedBranch {
-				fromRef = current
-			}
+	var block PortBlock
+	created := false
+	if portCfg.enabled() {
+		block, err = m.portStore.Allocate(ctx, worktreePath, portCfg.base, portCfg.count)
+		if err != nil {
+			return Target{}, err
 		}
-		if fromRef == "" {
-			fromRef, err = m.resolveDefaultBranch(ctx)
-			if err != nil {
-				return Target{}, err
+		defer func() {
+			if !created {
+				_ = m.portStore.Release(context.WithoutCancel(ctx), worktreePath)
 			}
-		}
+		}()
 	}
+	env := m.hookEnv(worktreePath, branch, block)
 
-	remoteExists, err := m.refExists(ctx, plumbingRemoteBranchRef("origin", branch))
+	// The worktree does not exist yet, so preCreate hooks run in the main repository.
+	if err := m.runHooks(ctx, hooks.PhasePreCreate, m.repoCtx.MainRoot, env); err != nil {
+		return Target{}, err
+	}
+
+	if !opts.NoFetch {
+		// Match upstream behavior: fetch is best-effort.
+		_, _ = m.git.Run(ctx, m.repoCtx.MainRoot, "fetch", "origin")
+	}
+
+	fromRef, err := m.resolveFromRef(ctx, opts)
 	if err != nil {
 		return Target{}, err
 	}
-	localExists, err := m.refExists(ctx, plumbingLocalBranchRef(branch))
+	// base is the ref the worktree was created from, as recorded in the registry.
+	action, base, err := m.branchAction(ctx, branch, trackMode, fromRef)
 	if err != nil {
 		return Target{}, err
 	}
@@ -144,68 +215,76 @@ func (m *Manager) CreateWorktree(ctx context.Context, branch string, opts Create
 		forceFlag = append(forceFlag, "--force")
 	}
 
-	switch trackMode {
-	case TrackModeRemote:
-		if !remoteExists {
-			return Target{}, fmt.Errorf("remote branch origin/%s does not exist", branch)
-		}
-		if localExists {
-			if err := m.gitWorktreeAdd(ctx, forceFlag, worktreePath, branch); err != nil {
-				return Target{}, err
+	switch action {
+	case BranchActionTrackRemote:
+		if trackMode == TrackModeRemote {
+			if err := m.gitWorktreeAddNewBranch(ctx, forceFlag, worktreePath, branch, "origin/"+branch); err != nil {
+				// Fallback to match upstream behavior when the branch already exists.
+				if err2 := m.gitWorktreeAdd(ctx, forceFlag, worktreePath, branch); err2 != nil {
+					return Target{}, err
+				}
 			}
 			break
 		}
-		if err := m.gitWorktreeAddNewBranch(ctx, forceFlag, worktreePath, branch, "origin/"+branch); err != nil {
-			// Fallback to match upstream behavior when the branch already exists.
-			if err2 := m.gitWorktreeAdd(ctx, forceFlag, worktreePath, branch); err2 != nil {
-				return Target{}, err
-			}
+		// Create local tracking branch first (ignore error).
+		_, _ = m.git.Run(ctx, m.repoCtx.MainRoot, "branch", "--track", branch, "origin/"+branch)
+		if err := m.gitWorktreeAdd(ctx, forceFlag, worktreePath, branch); err != nil {
+			return Target{}, err
 		}
 
-	case TrackModeLocal:
-		if !localExists {
-			return Target{}, fmt.Errorf("local branch %s does not exist", branch)
-		}
+	case BranchActionExisting:
 		if err := m.gitWorktreeAdd(ctx, forceFlag, worktreePath, branch); err != nil {
 			return Target{}, err
 		}
 
-	case TrackModeNone:
+	case BranchActionNew:
 		if err := m.gitWorktreeAddNewBranch(ctx, forceFlag, worktreePath, branch, fromRef); err != nil {
 			return Target{}, err
 		}
+	}
+	created = true
 
-	case TrackModeAuto:
-		if remoteExists && !localExists {
-			// Create local tracking branch first (ignore error).
-			_, _ = m.git.Run(ctx, m.repoCtx.MainRoot, "branch", "--track", branch, "origin/"+branch)
-			if err := m.gitWorktreeAdd(ctx, forceFlag, worktreePath, branch); err != nil {
-				return Target{}, err
-			}
-			break
-		}
-		if localExists {
-			if err := m.gitWorktreeAdd(ctx, forceFlag, worktreePath, branch); err != nil {
-				return Target{}, err
-			}
-			break
+	head, err := m.git.Run(ctx, worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		return Target{}, err
+	}
+	err = m.registry.Update(ctx, worktreePath, func(e *WorktreeMeta) error {
+		*e = WorktreeMeta{
+			Branch:      branch,
+			FromRef:     base,
+			FromCommit:  strings.TrimSpace(head.Stdout),
+			CreatedBy:   paths.User,
+			CreatedAt:   time.Now().UTC().Truncate(time.Second),
+			Description: strings.TrimSpace(opts.Description),
 		}
-		if err := m.gitWorktreeAddNewBranch(ctx, forceFlag, worktreePath, branch, fromRef); err != nil {
+		e.AddTags(opts.Tags...)
+		return nil
+	})
+	if err != nil {
+		return Target{}, err
+	}
+	if _, err := m.registry.AssignIDs(ctx, []string{worktreePath}); err != nil {
+		return Target{}, err
+	}
+	m.emit(Event{Type: EventCreated, Branch: branch, Path: worktreePath})
+
+	if portCfg.envFile && !block.IsZero() {
+		if err := m.writeEnvFile(worktreePath, block); err != nil {
 			return Target{}, err
 		}
 	}
 
 	if !opts.NoCopy {
-		if err := m.copyIntoWorktree(ctx, worktreePath); err != nil {
+		files, err := m.copyIntoWorktree(ctx, worktreePath, opts.CopyProgress, false)
+		if err != nil {
 			return Target{}, err
 		}
+		if len(files) > 0 {
+			m.emit(Event{Type: EventCopied, Branch: branch, Path: worktreePath, Files: files})
+		}
 	}
 
-	if err := m.runHooks(ctx, "postCreate", worktreePath, map[string]string{
-		"REPO_ROOT":     m.repoCtx.MainRoot,
-		"WORKTREE_PATH": worktreePath,
-		"BRANCH":        branch,
-	}); err != nil {
+	if err := m.runHooks(ctx, hooks.PhasePostCreate, worktreePath, env); err != nil {
 		return Target{}, err
 	}
 
@@ -216,44 +295,215 @@ func (m *Manager) CreateWorktree(ctx context.Context, branch string, opts Create
 	}, nil
 }
 
-func (m *Manager) copyIntoWorktree(ctx context.Context, worktreePath string) error {
-	includes, err := m.cfg.All(ctx, "wr.copy.include", "copy.include")
+// prepareCreate validates the arguments of CreateWorktree and returns the effective track mode, the resolved
+// paths and the path of the new worktree.
+func (m *Manager) prepareCreate(ctx context.Context, branch string, opts CreateWorktreeOptions) (TrackMode, worktrees.Paths, string, error) {
+	if branch == "" {
+		return "", worktrees.Paths{}, "", fmt.Errorf("branch name required")
+	}
+
+	if opts.Force && opts.NameSuffix == "" {
+		return "", worktrees.Paths{}, "", ErrForceRequiresName
+	}
+	for _, tag := range opts.Tags {
+		if err := validateTag(tag); err != nil {
+			return "", worktrees.Paths{}, "", err
+		}
+	}
+
+	trackMode := opts.TrackMode
+	if trackMode == "" {
+		trackMode = TrackModeAuto
+	}
+	switch trackMode {
+	case TrackModeAuto, TrackModeRemote, TrackModeLocal, TrackModeNone:
+	default:
+		return "", worktrees.Paths{}, "", fmt.Errorf("%w: %q", ErrInvalidTrackMode, trackMode)
+	}
+
+	paths, err := worktrees.ResolvePaths(ctx, m.cfg)
+	if err != nil {
+		return "", worktrees.Paths{}, "", err
+	}
+
+	worktreePath, err := m.newWorktreePath(paths, branch, opts.NameSuffix)
+	if err != nil {
+		return "", worktrees.Paths{}, "", err
+	}
+
+	if _, err := os.Stat(worktreePath); err == nil {
+		return "", worktrees.Paths{}, "", fmt.Errorf("worktree already exists at %s", worktreePath)
+	}
+	if err := m.checkPathCollision(ctx, worktreePath); err != nil {
+		return "", worktrees.Paths{}, "", err
+	}
+
+	return trackMode, paths, worktreePath, nil
+}
+
+// resolveFromRef returns the ref a new branch starts from: opts.FromRef, the current branch with
+// opts.FromCurrent (unless HEAD is detached), or the default branch.
+func (m *Manager) resolveFromRef(ctx context.Context, opts CreateWorktreeOptions) (string, error) {
+	if opts.FromRef != "" {
+		return opts.FromRef, nil
+	}
+	if opts.FromCurrent {
+		current, err := gitx.CurrentBranchGit(ctx, m.git, m.repoCtx.MainRoot)
+		if err != nil {
+			return "", err
+		}
+		if current != gitx.DetachedBranch {
+			return current, nil
+		}
+	}
+	return m.resolveDefaultBranch(ctx)
+}
+
+// branchAction decides how the worktree for branch obtains its branch under trackMode, and returns the ref
+// it is based on as recorded in the registry.
+func (m *Manager) branchAction(ctx context.Context, branch string, trackMode TrackMode, fromRef string) (action BranchAction, base string, err error) {
+	remoteExists, err := m.refExists(ctx, plumbingRemoteBranchRef("origin", branch))
 	if err != nil {
-		return err
+		return "", "", err
 	}
-	fileIncludes, err := m.cfg.WorktreeIncludePatterns()
+	localExists, err := m.refExists(ctx, plumbingLocalBranchRef(branch))
+	if err != nil {
+		return "", "", err
+	}
+
+	switch trackMode {
+	case TrackModeRemote:
+		if !remoteExists {
+			return "", "", fmt.Errorf("remote branch origin/%s does not exist", branch)
+		}
+		if localExists {
+			return BranchActionExisting, "origin/" + branch, nil
+		}
+		return BranchActionTrackRemote, "origin/" + branch, nil
+
+	case TrackModeLocal:
+		if !localExists {
+			return "", "", fmt.Errorf("local branch %s does not exist", branch)
+		}
+		return BranchActionExisting, branch, nil
+
+	case TrackModeNone:
+		return BranchActionNew, fromRef, nil
+
+	default:
+		switch {
+		case remoteExists && !localExists:
+			return BranchActionTrackRemote, "origin/" + branch, nil
+		case localExists:
+			return BranchActionExisting, branch, nil
+		default:
+			return BranchActionNew, fromRef, nil
+		}
+	}
+}
+
+// checkPathCollision reports ErrPathCollision when worktreePath would be nested in, or contain, a registered
+// worktree. Nesting inside the main repository is allowed (for example wr.worktrees.dir=.worktrees).
+func (m *Manager) checkPathCollision(ctx context.Context, worktreePath string) error {
+	entries, err := worktrees.ListPorcelain(ctx, m.repoCtx.CommonDir, m.repoCtx.MainRoot, m.currentBranch)
 	if err != nil {
 		return err
 	}
-	includes = append(includes, fileIncludes...)
+	for _, e := range entries {
+		nested := e.Path != m.repoCtx.MainRoot && pathutil.IsWithin(e.Path, worktreePath)
+		if nested || pathutil.IsWithin(worktreePath, e.Path) {
+			return fmt.Errorf("%w: %s overlaps %s (branch %s); adjust wr.worktrees.pathTemplate", ErrPathCollision, worktreePath, e.Path, e.Branch)
+		}
+	}
+	return nil
+}
+
+// copyIntoWorktree copies the configured files and directories from the main repository and returns the
+// copied files and directories. With dryRun, it only returns what would be copied.
+func (m *Manager) copyIntoWorktree(ctx context.Context, worktreePath string, progress func(CopyProgress), dryRun bool) ([]string, error) {
+	includes, rules, err := m.copyIncludes(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	excludes, err := m.cfg.All(ctx, "wr.copy.exclude", "copy.exclude")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if len(includes) > 0 {
-		if _, err := copy.CopyFiles(ctx, m.repoCtx.MainRoot, worktreePath, includes, excludes, copy.Options{PreservePaths: true}); err != nil {
-			return err
+	copyOpts, err := m.copyOptions(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+	copyOpts.PreservePaths = true
+	copyOpts.DryRun = dryRun
+	copyOpts.Progress = progress
+	copyOpts.Include = rules
+	ignoredOnly, err := m.copyIgnoredOnly(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	if ignoredOnly {
+		copyOpts.Filter = m.ignoredFilter(m.repoCtx.MainRoot)
+	}
+
+	var copied []string
+	if len(includes) > 0 || !rules.Empty() {
+		res, err := copy.CopyFiles(ctx, m.repoCtx.MainRoot, worktreePath, includes, excludes, copyOpts)
+		if err != nil {
+			return nil, err
 		}
+		copied = append(copied, res.CopiedFiles...)
 	}
 
 	includeDirs, err := m.cfg.All(ctx, "wr.copy.includeDirs", "copy.includeDirs")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	excludeDirs, err := m.cfg.All(ctx, "wr.copy.excludeDirs", "copy.excludeDirs")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(includeDirs) > 0 {
-		if _, err := copy.CopyDirectories(ctx, m.repoCtx.MainRoot, worktreePath, includeDirs, excludeDirs); err != nil {
-			return err
+		res, err := copy.CopyDirectories(ctx, m.repoCtx.MainRoot, worktreePath, includeDirs, excludeDirs, copyOpts)
+		if err != nil {
+			return nil, err
 		}
+		copied = append(copied, res.CopiedDirs...)
 	}
 
-	return nil
+	return copied, nil
+}
+
+// hookEnv returns the environment passed to hooks of every phase, including the port variables of block.
+func (m *Manager) hookEnv(worktreePath, branch string, block PortBlock) map[string]string {
+	env := map[string]string{
+		"REPO_ROOT":     m.repoCtx.MainRoot,
+		"WORKTREE_PATH": worktreePath,
+		"BRANCH":        branch,
+	}
+	for _, kv := range block.Env() {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	return env
+}
+
+// plannedHooks returns the hooks configured for phase, as they would run in dir.
+func (m *Manager) plannedHooks(ctx context.Context, phase, dir string) ([]PlannedHook, error) {
+	values, err := m.cfg.All(ctx, "wr.hook."+phase, "hooks."+phase)
+	if err != nil {
+		return nil, err
+	}
+	var out []PlannedHook
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		out = append(out, PlannedHook{Phase: phase, Index: i + 1, Command: v, Dir: dir})
+	}
+	return out, nil
 }
 
 func (m *Manager) runHooks(ctx context.Context, phase, dir string, env map[string]string) error {
@@ -270,7 +520,48 @@ func (m *Manager) runHooks(ctx context.Context, phase, dir string, env map[strin
 		envPairs = append(envPairs, k+"="+v)
 	}
 
-	return hooks.Run(ctx, phase, dir, values, envPairs, hooks.Options{})
+	opts := m.hookOpts
+	opts.Timeout, err = m.hookTimeout(ctx)
+	if err != nil {
+		return err
+	}
+	opts.OnStart = func(phase string, index int, command string) {
+		m.emit(Event{Type: EventHookStarted, Branch: env["BRANCH"], Path: env["WORKTREE_PATH"], Phase: phase, Index: index, Command: command})
+	}
+
+	err = hooks.Run(ctx, phase, dir, values, envPairs, opts)
+	var he *hooks.HookError
+	if errors.As(err, &he) {
+		m.emit(Event{
+			Type:     EventHookFailed,
+			Branch:   env["BRANCH"],
+			Path:     env["WORKTREE_PATH"],
+			Phase:    he.Phase,
+			Index:    he.Index,
+			Command:  he.Command,
+			ExitCode: he.ExitCode,
+			Stderr:   he.Stderr,
+			Code:     ErrorCodeHookFailed,
+			Message:  he.Error(),
+		})
+	}
+	return err
+}
+
+// hookTimeout resolves wr.hook.timeout (a Go duration such as "10m"). Zero disables the timeout.
+func (m *Manager) hookTimeout(ctx context.Context) (time.Duration, error) {
+	raw, err := m.cfg.Default(ctx, "wr.hook.timeout", "", "", "hooks.timeout")
+	if err != nil {
+		return 0, err
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid wr.hook.timeout %q: expected a non-negative duration such as 10m", raw)
+	}
+	return d, nil
 }
 
 func (m *Manager) resolveDefaultBranch(ctx context.Context) (string, error) {
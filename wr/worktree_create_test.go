@@ -21,10 +21,15 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/zchee/git-worktree-runner/internal/gitx"
+	"github.com/zchee/git-worktree-runner/internal/hooks"
+	"github.com/zchee/git-worktree-runner/internal/naming"
 	"github.com/zchee/git-worktree-runner/internal/testutil"
 )
 
@@ -109,6 +114,168 @@ func TestCreateAndRemoveWorktree(t *testing.T) {
 	}
 }
 
+func TestCreateWorktreePathTemplate(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "wr.worktrees.pathTemplate", "{{.User}}/{{.Date}}-{{.Branch}}"); err != nil {
+		t.Fatalf("git config wr.worktrees.pathTemplate: %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir, Env: map[string]string{"USER": "alice"}})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	target, err := m.CreateWorktree(t.Context(), "feature/auth", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+	rel := "alice/" + time.Now().Format(time.DateOnly) + "-feature-auth"
+	if got := filepath.ToSlash(target.Path); !strings.HasSuffix(got, "-worktrees/"+rel) {
+		t.Fatalf("unexpected worktree path %q, want suffix %q", got, rel)
+	}
+
+	for _, identifier := range []string{"feature/auth", rel, filepath.Base(rel)} {
+		got, err := m.ResolveTarget(t.Context(), identifier)
+		if err != nil {
+			t.Fatalf("ResolveTarget(%q) error: %v", identifier, err)
+		}
+		if diff := cmp.Diff(target, got); diff != "" {
+			t.Fatalf("ResolveTarget(%q) mismatch (-want +got):\n%s", identifier, diff)
+		}
+	}
+
+	// A directory that git no longer knows about is still discovered through the layout.
+	if _, err := g.Run(t.Context(), repoDir, "worktree", "remove", "--force", target.Path); err != nil {
+		t.Fatalf("git worktree remove: %v", err)
+	}
+	stray := filepath.Join(filepath.Dir(target.Path), "2020-01-01-stray")
+	if err := os.MkdirAll(stray, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	entries, err := m.List(t.Context())
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		if !e.Target.IsMain {
+			got = append(got, e.Target.Path)
+		}
+	}
+	if diff := cmp.Diff([]string{stray}, got); diff != "" {
+		t.Fatalf("listed worktrees mismatch (-want +got):\n%s", diff)
+	}
+
+	removed, err := m.removeEmptyDirs(t.Context(), false)
+	if err != nil {
+		t.Fatalf("removeEmptyDirs() error: %v", err)
+	}
+	if diff := cmp.Diff([]string{stray, filepath.Dir(stray)}, removed); diff != "" {
+		t.Fatalf("removed dirs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCreateWorktreePathCollision(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	outer, err := m.CreateWorktree(t.Context(), "outer", CreateWorktreeOptions{FromCurrent: true, NoCopy: true})
+	if err != nil {
+		t.Fatalf("CreateWorktree(outer) error: %v", err)
+	}
+
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "wr.worktrees.dir", filepath.Join(outer.Path, "nested")); err != nil {
+		t.Fatalf("git config wr.worktrees.dir: %v", err)
+	}
+	_, err = m.CreateWorktree(t.Context(), "inner", CreateWorktreeOptions{FromCurrent: true, NoCopy: true})
+	if !errors.Is(err, ErrPathCollision) {
+		t.Fatalf("expected %v, got %v", ErrPathCollision, err)
+	}
+}
+
+func TestCreateWorktreeNamingCollisions(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	tests := map[string]struct {
+		scheme   string
+		wantErr  bool
+		wantBase []string
+	}{
+		"error: sanitize keeps failing on collisions": {
+			scheme:  "sanitize",
+			wantErr: true,
+		},
+		"success: hash appends a suffix on collision": {
+			scheme:   "hash",
+			wantBase: []string{"feat-a", "feat-a-" + naming.Hash("feat-a"), "feat-a-" + naming.Hash("feat-a-")},
+		},
+		"success: escape is reversible": {
+			scheme:   "escape",
+			wantBase: []string{"feat%2Fa", "feat-a", "feat-a-"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			repoDir := filepath.Join(t.TempDir(), "repo")
+			g := testutil.Git(t)
+			testutil.InitRepo(t, g, repoDir)
+			if _, err := g.Run(t.Context(), repoDir, "config", "--local", "wr.worktrees.naming", tc.scheme); err != nil {
+				t.Fatalf("git config wr.worktrees.naming: %v", err)
+			}
+
+			m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+			if err != nil {
+				t.Fatalf("NewManager() error: %v", err)
+			}
+
+			branches := []string{"feat/a", "feat-a", "feat-a-"}
+			var gotBase []string
+			for _, branch := range branches {
+				target, err := m.CreateWorktree(t.Context(), branch, CreateWorktreeOptions{FromCurrent: true, NoCopy: true})
+				if tc.wantErr && branch != branches[0] {
+					if err == nil {
+						t.Fatalf("expected collision error for %s", branch)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("CreateWorktree(%s) error: %v", branch, err)
+				}
+				gotBase = append(gotBase, filepath.Base(target.Path))
+			}
+			if diff := cmp.Diff(tc.wantBase, gotBase); diff != "" {
+				t.Fatalf("worktree names mismatch (-want +got):\n%s", diff)
+			}
+
+			for _, branch := range branches {
+				got, err := m.ResolveTarget(t.Context(), branch)
+				if err != nil {
+					t.Fatalf("ResolveTarget(%s) error: %v", branch, err)
+				}
+				if diff := cmp.Diff(branch, got.Branch); diff != "" {
+					t.Fatalf("ResolveTarget(%s) branch mismatch (-want +got):\n%s", branch, diff)
+				}
+			}
+		})
+	}
+}
+
 func TestCreateWorktreeCopiesIncludedFiles(t *testing.T) {
 	testutil.SetGitProcessEnv(t)
 
@@ -196,3 +363,174 @@ func TestHooksPostCreateAndPostRemove(t *testing.T) {
 		t.Fatalf("expected removed.txt to contain WORKTREE_PATH, got empty")
 	}
 }
+
+func TestHooksPreCreateAndPreRemoveAbort(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	preCreate := `test "$BRANCH" != bad`
+	preRemove := "test ! -f keep"
+	if runtime.GOOS == "windows" {
+		preCreate = `if "%BRANCH%"=="bad" exit /B 1`
+		preRemove = "if exist keep exit /B 1"
+	}
+
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "--add", "wr.hook.preCreate", preCreate); err != nil {
+		t.Fatalf("git config --add wr.hook.preCreate: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "--add", "wr.hook.preRemove", preRemove); err != nil {
+		t.Fatalf("git config --add wr.hook.preRemove: %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+
+	_, err = m.CreateWorktree(t.Context(), "bad", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	var he *hooks.HookError
+	if !errors.As(err, &he) {
+		t.Fatalf("expected *hooks.HookError, got %v", err)
+	}
+	if diff := cmp.Diff(hooks.PhasePreCreate, he.Phase); diff != "" {
+		t.Fatalf("phase mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "show-ref", "--verify", "--quiet", "refs/heads/bad"); err == nil {
+		t.Fatalf("expected preCreate failure to abort before creating branch bad")
+	}
+
+	target, err := m.CreateWorktree(t.Context(), "good", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(target.Path, "keep"), nil, 0o600); err != nil {
+		t.Fatalf("WriteFile(keep): %v", err)
+	}
+	err = m.Remove(t.Context(), []string{"good"}, RemoveWorktreeOptions{Force: true})
+	if !errors.As(err, &he) {
+		t.Fatalf("expected *hooks.HookError, got %v", err)
+	}
+	if diff := cmp.Diff(hooks.PhasePreRemove, he.Phase); diff != "" {
+		t.Fatalf("phase mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(target.Path); err != nil {
+		t.Fatalf("expected worktree to survive aborted removal: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(target.Path, "keep")); err != nil {
+		t.Fatalf("Remove(keep): %v", err)
+	}
+	if err := m.Remove(t.Context(), []string{"good"}, RemoveWorktreeOptions{Force: true}); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if _, err := os.Stat(target.Path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected worktree to be removed, stat err=%v", err)
+	}
+}
+
+func TestManagerPlanCreateWorktree(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	if err := os.WriteFile(filepath.Join(repoDir, ".env.local"), []byte("KEY=VALUE\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile(.env.local): %v", err)
+	}
+	for _, kv := range [][2]string{
+		{"wr.copy.include", ".env.local"},
+		{"wr.hook.preCreate", "echo pre"},
+		{"wr.hook.postCreate", "echo post"},
+	} {
+		if _, err := g.Run(t.Context(), repoDir, "config", "--local", "--add", kv[0], kv[1]); err != nil {
+			t.Fatalf("git config --add %s: %v", kv[0], err)
+		}
+	}
+	if _, err := g.Run(t.Context(), repoDir, "branch", "existing"); err != nil {
+		t.Fatalf("git branch existing: %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	mainBranch, err := gitx.CurrentBranchGit(t.Context(), g, repoDir)
+	if err != nil {
+		t.Fatalf("CurrentBranchGit() error: %v", err)
+	}
+
+	tests := map[string]struct {
+		branch  string
+		opts    CreateWorktreeOptions
+		want    CreatePlan
+		wantErr bool
+	}{
+		"success: new branch from current": {
+			branch: "feature-a",
+			opts:   CreateWorktreeOptions{FromCurrent: true, NoFetch: true},
+			want: CreatePlan{
+				Branch:       "feature-a",
+				FromRef:      mainBranch,
+				BranchAction: BranchActionNew,
+				Copied:       []string{".env.local"},
+			},
+		},
+		"success: existing local branch without copy": {
+			branch: "existing",
+			opts:   CreateWorktreeOptions{FromRef: "HEAD", NoCopy: true},
+			want: CreatePlan{
+				Branch:       "existing",
+				FromRef:      "HEAD",
+				BranchAction: BranchActionExisting,
+				Fetch:        true,
+			},
+		},
+		"error: missing remote branch": {
+			branch:  "feature-b",
+			opts:    CreateWorktreeOptions{TrackMode: TrackModeRemote},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := m.PlanCreateWorktree(t.Context(), tc.branch, tc.opts)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil (plan=%+v)", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanCreateWorktree() error: %v", err)
+			}
+
+			tc.want.Path = filepath.Join(repoDir+"-worktrees", tc.branch)
+			tc.want.Hooks = []PlannedHook{
+				{Phase: hooks.PhasePreCreate, Index: 1, Command: "echo pre", Dir: repoDir},
+				{Phase: hooks.PhasePostCreate, Index: 1, Command: "echo post", Dir: tc.want.Path},
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("plan mismatch (-want +got):\n%s", diff)
+			}
+			if _, err := os.Stat(got.Path); !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("expected %s not to be created: stat err=%v", got.Path, err)
+			}
+		})
+	}
+
+	if _, err := g.Run(t.Context(), repoDir, "show-ref", "--verify", "--quiet", "refs/heads/feature-a"); err == nil {
+		t.Fatalf("expected branch feature-a not to be created")
+	}
+}
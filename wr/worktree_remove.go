@@ -20,13 +20,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/zchee/git-worktree-runner/internal/gitx"
+	"github.com/zchee/git-worktree-runner/internal/hooks"
 	"github.com/zchee/git-worktree-runner/internal/lock"
+	"github.com/zchee/git-worktree-runner/internal/worktrees"
 )
 
+// ErrWorktreeDirty is returned when removing a worktree with uncommitted or untracked changes without Force.
+var ErrWorktreeDirty = errors.New("worktree has uncommitted changes")
+
+// ErrWorktreeLocked is returned when removing a worktree locked with `git worktree lock` without Force.
+var ErrWorktreeLocked = errors.New("worktree is locked")
+
 // RemoveWorktreeOptions configures worktree removal.
 type RemoveWorktreeOptions struct {
 	DeleteBranch bool
@@ -42,7 +51,8 @@ type RemoveWorktreeOptions struct {
 	ConfirmDeleteBranch func(ctx context.Context, branch string) (bool, error)
 }
 
-// Remove removes one or more worktrees identified by identifiers.
+// Remove removes one or more worktrees identified by identifiers (see ResolveTarget). Unlike ResolveTarget,
+// it does not accept a prefix of a branch or directory name.
 func (m *Manager) Remove(ctx context.Context, identifiers []string, opts RemoveWorktreeOptions) error {
 	if len(identifiers) == 0 {
 		return fmt.Errorf("at least one identifier is required")
@@ -58,7 +68,7 @@ func (m *Manager) Remove(ctx context.Context, identifiers []string, opts RemoveW
 	var errs []error
 
 	for _, id := range identifiers {
-		target, err := m.ResolveTarget(ctx, id)
+		target, err := m.resolveTarget(ctx, id, false)
 		if err != nil {
 			errs = append(errs, err)
 			continue
@@ -68,46 +78,205 @@ func (m *Manager) Remove(ctx context.Context, identifiers []string, opts RemoveW
 			continue
 		}
 
-		args := []string{"worktree", "remove"}
-		if opts.Force {
-			args = append(args, "--force")
+		if _, err := m.removeTarget(ctx, target, opts); err != nil {
+			errs = append(errs, err)
+			continue
 		}
-		args = append(args, target.Path)
+	}
+
+	return errors.Join(errs...)
+}
+
+// RemovePlan describes what Remove would do, as computed by PlanRemove.
+type RemovePlan struct {
+	Worktrees []PlannedRemoval
+}
 
-		if _, err := m.git.Run(ctx, m.repoCtx.MainRoot, args...); err != nil {
+// PlannedRemoval is one worktree of a RemovePlan.
+type PlannedRemoval struct {
+	Target Target
+	// Dirty counts the changes in the worktree. It is zero when the worktree directory is missing.
+	Dirty DirtyCounts
+	// Skipped explains why the worktree would not be removed ("locked" or "dirty" without Force). Empty when it
+	// would be removed.
+	Skipped string
+	// LockReason is the reason recorded by `git worktree lock --reason` when Skipped is "locked".
+	LockReason string
+	// DeleteBranch reports whether the branch would be deleted. Unless Yes is set, Remove asks
+	// ConfirmDeleteBranch first.
+	DeleteBranch bool
+	// Hooks lists the preRemove and postRemove hooks in the order they would run.
+	Hooks []PlannedHook
+}
+
+// PlanRemove computes what Remove would do with the same arguments, without removing, deleting or running
+// anything. Identifiers that Remove would reject are reported in the returned error, joined like Remove does;
+// the plan still covers the other worktrees.
+func (m *Manager) PlanRemove(ctx context.Context, identifiers []string, opts RemoveWorktreeOptions) (RemovePlan, error) {
+	if len(identifiers) == 0 {
+		return RemovePlan{}, fmt.Errorf("at least one identifier is required")
+	}
+
+	var (
+		plan RemovePlan
+		errs []error
+	)
+	for _, id := range identifiers {
+		target, err := m.resolveTarget(ctx, id, false)
+		if err != nil {
 			errs = append(errs, err)
 			continue
 		}
+		if target.IsMain {
+			errs = append(errs, fmt.Errorf("cannot remove main repository"))
+			continue
+		}
 
-		if opts.DeleteBranch && target.Branch != "" && target.Branch != gitx.DetachedBranch {
-			yes := opts.Yes || m.yes
+		p := PlannedRemoval{Target: target}
+		locked, reason, err := m.worktreeLocked(ctx, target.Path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if locked && !opts.Force {
+			p.Skipped = "locked"
+			p.LockReason = reason
+		}
+		preDir := m.repoCtx.MainRoot
+		if fi, err := os.Stat(target.Path); err == nil && fi.IsDir() {
+			preDir = target.Path
+			st, err := gitx.StatusGit(ctx, m.git, target.Path)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			p.Dirty = DirtyCounts{
+				Staged:     st.Staged,
+				Unstaged:   st.Unstaged,
+				Untracked:  st.Untracked,
+				Conflicted: st.Conflicted,
+			}
+			if st.Dirty() && !opts.Force && p.Skipped == "" {
+				p.Skipped = "dirty"
+			}
+		}
+		if p.Skipped == "" {
+			p.DeleteBranch = opts.DeleteBranch && target.Branch != "" && target.Branch != gitx.DetachedBranch
 
-			deleteBranch := true
-			if !yes && opts.ConfirmDeleteBranch != nil {
-				ok, err := opts.ConfirmDeleteBranch(ctx, target.Branch)
-				if err != nil {
-					errs = append(errs, err)
-					continue
-				}
-				deleteBranch = ok
+			pre, err := m.plannedHooks(ctx, hooks.PhasePreRemove, preDir)
+			if err != nil {
+				return RemovePlan{}, err
 			}
-			if deleteBranch {
-				if _, err := m.git.Run(ctx, m.repoCtx.MainRoot, "branch", "-D", target.Branch); err != nil {
-					errs = append(errs, err)
-					continue
-				}
+			post, err := m.plannedHooks(ctx, hooks.PhasePostRemove, m.repoCtx.MainRoot)
+			if err != nil {
+				return RemovePlan{}, err
 			}
+			p.Hooks = append(pre, post...)
 		}
+		plan.Worktrees = append(plan.Worktrees, p)
+	}
 
-		if err := m.runHooks(ctx, "postRemove", m.repoCtx.MainRoot, map[string]string{
-			"REPO_ROOT":     m.repoCtx.MainRoot,
-			"WORKTREE_PATH": target.Path,
-			"BRANCH":        target.Branch,
-		}); err != nil {
-			errs = append(errs, err)
-			continue
+	return plan, errors.Join(errs...)
+}
+
+// removeTarget removes a single linked worktree, optionally deletes its branch, and runs the preRemove and
+// postRemove hooks around it. A failing preRemove hook leaves the worktree untouched.
+//
+// The caller must hold the wr.lock file lock.
+func (m *Manager) removeTarget(ctx context.Context, target Target, opts RemoveWorktreeOptions) (branchDeleted bool, err error) {
+	block, _, err := m.portStore.Get(target.Path)
+	if err != nil {
+		return false, err
+	}
+	env := m.hookEnv(target.Path, target.Branch, block)
+
+	// Run preRemove inside the worktree when it still exists so hooks can inspect it.
+	preDir := m.repoCtx.MainRoot
+	if fi, err := os.Stat(target.Path); err == nil && fi.IsDir() {
+		preDir = target.Path
+	}
+	if !opts.Force {
+		locked, reason, err := m.worktreeLocked(ctx, target.Path)
+		if err != nil {
+			return false, err
+		}
+		if locked {
+			if reason != "" {
+				return false, fmt.Errorf("%w: %s (%s; use --force to remove it anyway)", ErrWorktreeLocked, target.Path, reason)
+			}
+			return false, fmt.Errorf("%w: %s (use --force to remove it anyway)", ErrWorktreeLocked, target.Path)
+		}
+	}
+	if preDir == target.Path && !opts.Force {
+		st, err := gitx.StatusGit(ctx, m.git, target.Path)
+		if err != nil {
+			return false, err
+		}
+		if st.Dirty() {
+			return false, fmt.Errorf("%w: %s (use --force to remove it anyway)", ErrWorktreeDirty, target.Path)
 		}
 	}
+	if err := m.runHooks(ctx, hooks.PhasePreRemove, preDir, env); err != nil {
+		return false, err
+	}
 
-	return errors.Join(errs...)
+	args := []string{"worktree", "remove"}
+	if opts.Force {
+		// A single --force only overrides local changes; git needs it twice to remove a locked worktree.
+		args = append(args, "--force", "--force")
+	}
+	args = append(args, target.Path)
+
+	if _, err := m.git.Run(ctx, m.repoCtx.MainRoot, args...); err != nil {
+		return false, err
+	}
+	if !block.IsZero() {
+		if err := m.portStore.Release(ctx, target.Path); err != nil {
+			return false, err
+		}
+	}
+	if err := m.registry.Release(ctx, target.Path); err != nil {
+		return false, err
+	}
+
+	if opts.DeleteBranch && target.Branch != "" && target.Branch != gitx.DetachedBranch {
+		yes := opts.Yes || m.yes
+
+		deleteBranch := true
+		if !yes && opts.ConfirmDeleteBranch != nil {
+			ok, err := opts.ConfirmDeleteBranch(ctx, target.Branch)
+			if err != nil {
+				return false, err
+			}
+			deleteBranch = ok
+		}
+		if deleteBranch {
+			if _, err := m.git.Run(ctx, m.repoCtx.MainRoot, "branch", "-D", target.Branch); err != nil {
+				return false, err
+			}
+			branchDeleted = true
+		}
+	}
+
+	m.emit(Event{Type: EventRemoved, Branch: target.Branch, Path: target.Path, BranchDeleted: branchDeleted})
+
+	if err := m.runHooks(ctx, hooks.PhasePostRemove, m.repoCtx.MainRoot, env); err != nil {
+		return branchDeleted, err
+	}
+
+	return branchDeleted, nil
+}
+
+// worktreeLocked reports whether the worktree at worktreePath is locked, and the recorded reason.
+func (m *Manager) worktreeLocked(ctx context.Context, worktreePath string) (locked bool, reason string, err error) {
+	entries, err := worktrees.ListPorcelain(ctx, m.repoCtx.CommonDir, m.repoCtx.MainRoot, m.currentBranch)
+	if err != nil {
+		return false, "", err
+	}
+	for _, e := range entries {
+		if e.Path == worktreePath {
+			return e.Locked, e.LockReason, nil
+		}
+	}
+	return false, "", nil
 }
@@ -19,12 +19,14 @@ package wr
 import (
 	"context"
 	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/zchee/git-worktree-runner/internal/gitcmd"
+	"github.com/zchee/git-worktree-runner/internal/hooks"
 	"github.com/zchee/git-worktree-runner/internal/testutil"
 )
 
@@ -121,3 +123,148 @@ func assertBranchDeleted(t *testing.T, g gitcmd.Git, repoDir, branch string, wan
 		t.Fatalf("expected branch %q to exist, got err=%v", branch, err)
 	}
 }
+
+func TestManagerRemoveRefusesDirtyWorktree(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	target, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(target.Path, "untracked.txt"), []byte("x\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(untracked.txt): %v", err)
+	}
+
+	if err := m.Remove(t.Context(), []string{"feature-a"}, RemoveWorktreeOptions{}); !errors.Is(err, ErrWorktreeDirty) {
+		t.Fatalf("Remove() error = %v, want %v", err, ErrWorktreeDirty)
+	}
+	if _, err := os.Stat(target.Path); err != nil {
+		t.Fatalf("expected dirty worktree to remain: %v", err)
+	}
+
+	if err := m.Remove(t.Context(), []string{"feature-a"}, RemoveWorktreeOptions{Force: true}); err != nil {
+		t.Fatalf("Remove(force) error: %v", err)
+	}
+}
+
+func TestManagerRemoveRefusesLockedWorktree(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	target, err := m.CreateWorktree(t.Context(), "feature-a", CreateWorktreeOptions{
+		FromCurrent: true,
+		NoCopy:      true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWorktree() error: %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "worktree", "lock", target.Path); err != nil {
+		t.Fatalf("git worktree lock: %v", err)
+	}
+
+	if err := m.Remove(t.Context(), []string{"feature-a"}, RemoveWorktreeOptions{}); !errors.Is(err, ErrWorktreeLocked) {
+		t.Fatalf("Remove() error = %v, want %v", err, ErrWorktreeLocked)
+	}
+	if _, err := os.Stat(target.Path); err != nil {
+		t.Fatalf("expected locked worktree to remain: %v", err)
+	}
+
+	if err := m.Remove(t.Context(), []string{"feature-a"}, RemoveWorktreeOptions{Force: true}); err != nil {
+		t.Fatalf("Remove(force) error: %v", err)
+	}
+	if _, err := os.Stat(target.Path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected locked worktree to be removed with force, got %v", err)
+	}
+}
+
+func TestManagerPlanRemove(t *testing.T) {
+	testutil.SetGitProcessEnv(t)
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	g := testutil.Git(t)
+	testutil.InitRepo(t, g, repoDir)
+	if _, err := g.Run(t.Context(), repoDir, "config", "--local", "--add", "wr.hook.postRemove", "echo post"); err != nil {
+		t.Fatalf("git config --add wr.hook.postRemove: %v", err)
+	}
+
+	m, err := NewManager(t.Context(), ManagerOptions{StartDir: repoDir})
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	targets := map[string]Target{}
+	for _, branch := range []string{"clean", "dirty", "locked"} {
+		target, err := m.CreateWorktree(t.Context(), branch, CreateWorktreeOptions{
+			FromCurrent: true,
+			NoCopy:      true,
+		})
+		if err != nil {
+			t.Fatalf("CreateWorktree(%s) error: %v", branch, err)
+		}
+		targets[branch] = target
+	}
+	if err := os.WriteFile(filepath.Join(targets["dirty"].Path, "untracked.txt"), []byte("x\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile(untracked.txt): %v", err)
+	}
+	if _, err := g.Run(t.Context(), repoDir, "worktree", "lock", "--reason", "on usb", targets["locked"].Path); err != nil {
+		t.Fatalf("git worktree lock: %v", err)
+	}
+	postRemove := []PlannedHook{{Phase: hooks.PhasePostRemove, Index: 1, Command: "echo post", Dir: repoDir}}
+
+	tests := map[string]struct {
+		opts RemoveWorktreeOptions
+		want RemovePlan
+	}{
+		"success: dirty worktree is skipped": {
+			opts: RemoveWorktreeOptions{DeleteBranch: true},
+			want: RemovePlan{Worktrees: []PlannedRemoval{
+				{Target: targets["clean"], DeleteBranch: true, Hooks: postRemove},
+				{Target: targets["dirty"], Dirty: DirtyCounts{Untracked: 1}, Skipped: "dirty"},
+				{Target: targets["locked"], Skipped: "locked", LockReason: "on usb"},
+			}},
+		},
+		"success: force removes dirty and locked worktrees": {
+			opts: RemoveWorktreeOptions{Force: true},
+			want: RemovePlan{Worktrees: []PlannedRemoval{
+				{Target: targets["clean"], Hooks: postRemove},
+				{Target: targets["dirty"], Dirty: DirtyCounts{Untracked: 1}, Hooks: postRemove},
+				{Target: targets["locked"], Hooks: postRemove},
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := m.PlanRemove(t.Context(), []string{"clean", "dirty", "locked", "nope"}, tc.opts)
+			if !errors.Is(err, ErrTargetNotFound) {
+				t.Fatalf("PlanRemove() error = %v, want %v", err, ErrTargetNotFound)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("plan mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, target := range targets {
+		if _, err := os.Stat(target.Path); err != nil {
+			t.Fatalf("expected %s to remain: %v", target.Path, err)
+		}
+	}
+}